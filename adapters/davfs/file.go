@@ -0,0 +1,293 @@
+package davfs
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"golang.org/x/net/webdav"
+)
+
+// fileInfo describes a file of the tree, it provides the content type and
+// the ETag of the file without reading its content.
+type fileInfo struct {
+	f    *file.File
+	root bool
+}
+
+func newFileInfo(f *file.File, root bool) *fileInfo {
+	return &fileInfo{f: f, root: root}
+}
+
+func (fi *fileInfo) Name() string {
+	if fi.root {
+		return "/"
+	}
+
+	return fi.f.Name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return int64(fi.f.Size)
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.f.IsDir {
+		return os.ModeDir | 0o755
+	}
+
+	return 0o644
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.f.UpdatedAt
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.f.IsDir
+}
+
+func (fi *fileInfo) Sys() interface{} {
+	return fi.f
+}
+
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.f.IsDir || fi.f.MimeType == "" {
+		return "", webdav.ErrNotImplemented
+	}
+
+	return fi.f.MimeType, nil
+}
+
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	if fi.f.IsDir || len(fi.f.MD5) == 0 {
+		return "", webdav.ErrNotImplemented
+	}
+
+	return fmt.Sprintf("%q", hex.EncodeToString(fi.f.MD5)), nil
+}
+
+// davFile reads a file from the storage or lists a directory.
+type davFile struct {
+	ctx context.Context
+	fs  *FileSystem
+	f   *file.File
+
+	r *services.FileReader
+
+	children []file.File
+	listed   bool
+}
+
+func (df *davFile) Read(p []byte) (int, error) {
+	if df.f.IsDir {
+		return 0, pathError("read", df.f.Name, os.ErrInvalid)
+	}
+
+	return df.reader().Read(p)
+}
+
+func (df *davFile) Seek(offset int64, whence int) (int64, error) {
+	if df.f.IsDir {
+		return 0, pathError("seek", df.f.Name, os.ErrInvalid)
+	}
+
+	return df.reader().Seek(offset, whence)
+}
+
+func (df *davFile) reader() *services.FileReader {
+	if df.r == nil {
+		df.r = services.NewFileReader(df.ctx, df.fs.FileService, df.f)
+	}
+
+	return df.r
+}
+
+// Readdir returns the next count children, or all of them when count is not
+// positive.
+func (df *davFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !df.f.IsDir {
+		return nil, pathError("readdir", df.f.Name, os.ErrInvalid)
+	}
+
+	if !df.listed {
+		children, err := df.fs.children(df.ctx, df.f)
+		if err != nil {
+			return nil, err
+		}
+
+		df.children, df.listed = children, true
+	}
+
+	n := len(df.children)
+	if count > 0 {
+		if n == 0 {
+			return nil, io.EOF
+		}
+
+		n = min(n, count)
+	}
+
+	infos := make([]os.FileInfo, 0, n)
+	for i := range df.children[:n] {
+		infos = append(infos, newFileInfo(&df.children[i], false))
+	}
+
+	df.children = df.children[n:]
+
+	return infos, nil
+}
+
+func (df *davFile) Stat() (os.FileInfo, error) {
+	return newFileInfo(df.f, df.f.ID == df.fs.Root.ID), nil
+}
+
+func (df *davFile) Write(p []byte) (int, error) {
+	return 0, pathError("write", df.f.Name, os.ErrPermission)
+}
+
+func (df *davFile) Close() error {
+	if df.r == nil {
+		return nil
+	}
+
+	return df.r.Close()
+}
+
+// davWriter streams the content written to it to the storage, the file is
+// created once it is closed. A file of the same file system read into it is
+// copied like CopyFiles instead, which is how COPY requests are served.
+type davWriter struct {
+	ctx      context.Context
+	fs       *FileSystem
+	parent   *file.File
+	existing *file.File
+	f        *file.File
+
+	pw        *io.PipeWriter
+	written   int64
+	length    int64
+	available int64
+	done      chan error
+}
+
+// put starts the upload of the content written to the pipe.
+func (w *davWriter) put() {
+	pr, pw := io.Pipe()
+
+	w.pw, w.done = pw, make(chan error, 1)
+
+	go func() {
+		err := services.PutFile(w.ctx, w.fs.FileStore, w.fs.UserStore, w.fs.FileService, w.fs.PermissionService, w.fs.PubSubService,
+			w.parent, w.existing, w.f, pr)
+		err = w.fs.applied(err)
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+}
+
+func (w *davWriter) Write(p []byte) (int, error) {
+	if w.done == nil {
+		w.put()
+	}
+
+	if w.pw == nil {
+		return 0, pathError("write", w.f.Name, os.ErrClosed)
+	}
+
+	if w.parent.Owner != nil && w.written+int64(len(p)) > w.available {
+		w.pw.CloseWithError(file.ErrStorageCapacityExceeded)
+		return 0, file.ErrStorageCapacityExceeded
+	}
+
+	n, err := w.pw.Write(p)
+	w.written += int64(n)
+
+	return n, err
+}
+
+// ReadFrom copies the file in the storage when r is a file of the same file
+// system and nothing was written yet, the content is streamed otherwise.
+func (w *davWriter) ReadFrom(r io.Reader) (int64, error) {
+	src, ok := r.(*davFile)
+	if !ok || src.fs != w.fs || src.f.IsDir || src.r != nil || w.done != nil {
+		return io.Copy(struct{ io.Writer }{w}, r)
+	}
+
+	w.done = make(chan error, 1)
+
+	if w.parent.Owner != nil && int64(src.f.Size) > w.available {
+		w.done <- file.ErrStorageCapacityExceeded
+		return 0, file.ErrStorageCapacityExceeded
+	}
+
+	err := services.PutCopy(w.ctx, w.fs.FileStore, w.fs.UserStore, w.fs.FileService, w.fs.PermissionService, w.fs.PubSubService,
+		w.parent, w.existing, src.f, w.f)
+	err = w.fs.applied(err)
+	w.done <- err
+
+	if err != nil {
+		return 0, err
+	}
+
+	w.written = int64(w.f.Size)
+
+	return w.written, nil
+}
+
+func (w *davWriter) Close() error {
+	if w.done == nil {
+		w.put()
+	}
+
+	if w.pw != nil {
+		if w.length >= 0 && w.written != w.length {
+			w.pw.CloseWithError(ErrIncompleteUpload)
+		} else {
+			w.pw.Close()
+		}
+	}
+
+	return <-w.done
+}
+
+// Abort discards the content written so far, the file is left as it was
+// when the writer is closed.
+func (w *davWriter) Abort(err error) {
+	if w.done == nil {
+		w.done = make(chan error, 1)
+		w.done <- err
+
+		return
+	}
+
+	if w.pw != nil {
+		w.pw.CloseWithError(err)
+	}
+}
+
+// Stat describes the content written so far.
+func (w *davWriter) Stat() (os.FileInfo, error) {
+	f := *w.f
+	f.Size = uint64(w.written)
+	f.UpdatedAt = time.Now()
+
+	return newFileInfo(&f, false), nil
+}
+
+func (w *davWriter) Read(p []byte) (int, error) {
+	return 0, pathError("read", w.f.Name, os.ErrInvalid)
+}
+
+func (w *davWriter) Seek(offset int64, whence int) (int64, error) {
+	return 0, pathError("seek", w.f.Name, os.ErrInvalid)
+}
+
+func (w *davWriter) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, pathError("readdir", w.f.Name, os.ErrInvalid)
+}
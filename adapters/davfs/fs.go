@@ -0,0 +1,431 @@
+// Package davfs exposes the tree of a user as a WebDAV file system. The
+// operations go through the same stores, permission checks and storage usage
+// accounting as the file API.
+package davfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/net/webdav"
+)
+
+// pageSize is the number of children listed at once.
+const pageSize = 1000
+
+var ErrIncompleteUpload = errors.New("incomplete upload")
+
+type contextKey struct{}
+
+// WithContentLength records the length of the body of a PUT request, a
+// shorter upload is discarded instead of replacing the file.
+func WithContentLength(ctx context.Context, length int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, length)
+}
+
+// FileSystem is the tree under Root as seen by User.
+type FileSystem struct {
+	FileStore         file.Store
+	UserStore         identity.Store
+	FileService       file.Service
+	PermissionService permission.Service
+	PubSubService     pubsub.Service
+	Logger            *zap.SugaredLogger
+
+	User *identity.User
+	Root *file.File
+}
+
+var _ webdav.FileSystem = (*FileSystem)(nil)
+
+func (fs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	name = clean(name)
+	if name == "/" {
+		return pathError("mkdir", name, os.ErrExist)
+	}
+
+	parent, err := fs.get(ctx, "mkdir", path.Dir(name))
+	if err != nil {
+		return err
+	}
+
+	if !parent.IsDir {
+		return pathError("mkdir", name, os.ErrNotExist)
+	}
+
+	if err := fs.canEdit(ctx, "mkdir", name, parent); err != nil {
+		return err
+	}
+
+	if _, err := fs.get(ctx, "mkdir", name); err == nil {
+		return pathError("mkdir", name, os.ErrExist)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	f := file.NewDirectory(path.Base(name)).WithID(uuid.New()).WithOwnerID(fs.User.ID)
+	if err := services.CreateDirectory(ctx, fs.FileStore, fs.PermissionService, parent, f); err != nil {
+		if errors.Is(err, file.ErrDirAlreadyExists) {
+			return pathError("mkdir", name, os.ErrExist)
+		}
+
+		return err
+	}
+
+	fs.writeLogs(ctx, file.NewLog(f.ID, fs.User.ID, file.LogActionCreate))
+	fs.writeChanges(ctx, file.NewChange(f, file.ChangeCreate))
+
+	return nil
+}
+
+func (fs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	name = clean(name)
+
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return fs.create(ctx, name, flag)
+	}
+
+	f, err := fs.get(ctx, "open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.canView(ctx, "open", name, f); err != nil {
+		return nil, err
+	}
+
+	if f.Quarantined() {
+		return nil, pathError("open", name, os.ErrPermission)
+	}
+
+	return &davFile{ctx: ctx, fs: fs, f: f}, nil
+}
+
+// RemoveAll moves name to the trash of the user, like MoveToTrash.
+func (fs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	name = clean(name)
+	if name == "/" {
+		return pathError("remove", name, os.ErrPermission)
+	}
+
+	f, err := fs.get(ctx, "remove", name)
+	if err != nil {
+		return err
+	}
+
+	parent, err := fs.get(ctx, "remove", path.Dir(name))
+	if err != nil {
+		return err
+	}
+
+	if err := fs.canEdit(ctx, "remove", name, parent); err != nil {
+		return err
+	}
+
+	trash, err := fs.FileStore.GetTrashByUserID(ctx, fs.User.ID)
+	if err != nil {
+		return err
+	}
+
+	// only the owner can trash a file
+	files, err := fs.FileStore.ListSelectedOwnedChildren(ctx, fs.User.ID, parent, []string{f.ID.String()})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return pathError("remove", name, os.ErrPermission)
+	}
+
+	return fs.applied(services.MoveFiles(ctx, fs.FileStore, fs.UserStore, fs.PermissionService, fs.PubSubService, fs.User.ID, parent, trash, files, true))
+}
+
+// Rename moves oldName to newName, like Move followed by Rename when the
+// name changes too.
+func (fs *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	oldName, newName = clean(oldName), clean(newName)
+	if oldName == "/" || newName == "/" {
+		return pathError("rename", oldName, os.ErrPermission)
+	}
+
+	if strings.HasPrefix(newName+"/", oldName+"/") {
+		return pathError("rename", newName, os.ErrInvalid)
+	}
+
+	f, err := fs.get(ctx, "rename", oldName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fs.get(ctx, "rename", newName); err == nil {
+		return pathError("rename", newName, os.ErrExist)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	src, err := fs.get(ctx, "rename", path.Dir(oldName))
+	if err != nil {
+		return err
+	}
+
+	dest, err := fs.get(ctx, "rename", path.Dir(newName))
+	if err != nil {
+		return err
+	}
+
+	if !dest.IsDir {
+		return pathError("rename", newName, os.ErrNotExist)
+	}
+
+	if src.ID != dest.ID {
+		if err := fs.canEdit(ctx, "rename", oldName, src); err != nil {
+			return err
+		}
+
+		if err := fs.canEdit(ctx, "rename", newName, dest); err != nil {
+			return err
+		}
+
+		files, err := fs.FileStore.ListSelectedChildren(ctx, src.FullPath(), []string{f.ID.String()})
+		if err != nil {
+			return err
+		}
+
+		err = services.MoveFiles(ctx, fs.FileStore, fs.UserStore, fs.PermissionService, fs.PubSubService, fs.User.ID, src, dest, files, false)
+		if err := fs.applied(err); err != nil {
+			return err
+		}
+	}
+
+	if path.Base(oldName) == path.Base(newName) {
+		return nil
+	}
+
+	if err := fs.canEdit(ctx, "rename", oldName, f); err != nil {
+		return err
+	}
+
+	if err := fs.FileStore.UpdateName(ctx, f.ID, path.Base(newName)); err != nil {
+		return err
+	}
+
+	fs.writeLogs(ctx, file.NewLog(f.ID, fs.User.ID, file.LogActionUpdate))
+
+	renamed := *f
+	renamed.Path, renamed.Name = dest.FullPath(), path.Base(newName)
+	fs.writeChanges(ctx, file.NewChange(&renamed, file.ChangeRename).WithFromPath(path.Join(dest.FullPath(), f.Name)))
+
+	return nil
+}
+
+func (fs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	name = clean(name)
+
+	f, err := fs.get(ctx, "stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.canView(ctx, "stat", name, f); err != nil {
+		return nil, err
+	}
+
+	return newFileInfo(f, name == "/"), nil
+}
+
+// create opens a writer to name, the content replaces the file once it is
+// closed. The replaced file is moved to the trash like with PutFile.
+func (fs *FileSystem) create(ctx context.Context, name string, flag int) (webdav.File, error) {
+	if name == "/" || flag&os.O_APPEND != 0 {
+		return nil, pathError("open", name, os.ErrPermission)
+	}
+
+	parent, err := fs.get(ctx, "open", path.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !parent.IsDir {
+		return nil, pathError("open", name, os.ErrNotExist)
+	}
+
+	if err := fs.canEdit(ctx, "open", name, parent); err != nil {
+		return nil, err
+	}
+
+	existing, err := fs.get(ctx, "open", name)
+	switch {
+	case os.IsNotExist(err):
+		existing = nil
+		if flag&os.O_CREATE == 0 {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case existing.IsDir:
+		return nil, pathError("open", name, os.ErrExist)
+	case flag&os.O_EXCL != 0:
+		return nil, pathError("open", name, os.ErrExist)
+	case existing.OwnerID != fs.User.ID:
+		// the replaced file goes to the trash, which only its owner can do
+		return nil, pathError("open", name, os.ErrPermission)
+	}
+
+	length, ok := ctx.Value(contextKey{}).(int64)
+	if !ok {
+		length = -1
+	}
+
+	w := &davWriter{
+		ctx:      ctx,
+		fs:       fs,
+		parent:   parent,
+		existing: existing,
+		f:        file.NewFile(path.Base(name)).WithID(uuid.New()).WithOwnerID(fs.User.ID),
+		length:   length,
+	}
+
+	if parent.Owner != nil {
+		w.available = int64(parent.Owner.StorageCapacity) - int64(parent.Owner.StorageUsage)
+	}
+
+	return w, nil
+}
+
+// get returns the file at name, which is relative to the root. The trash is
+// not part of the tree.
+func (fs *FileSystem) get(ctx context.Context, op string, name string) (*file.File, error) {
+	if name == "/" {
+		return fs.Root, nil
+	}
+
+	if name == "/.trash" || strings.HasPrefix(name, "/.trash/") {
+		return nil, pathError(op, name, os.ErrNotExist)
+	}
+
+	f, err := fs.FileStore.GetByFullPath(ctx, path.Join(fs.Root.FullPath(), name))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, pathError(op, name, os.ErrNotExist)
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// children lists the entries of the directory f, the trash excluded.
+func (fs *FileSystem) children(ctx context.Context, f *file.File) ([]file.File, error) {
+	var (
+		files  []file.File
+		cursor = pagination.NewCursor("", pageSize)
+	)
+
+	for {
+		page, err := fs.FileStore.ListCursor(ctx, f.FullPath(), cursor, file.Filter{})
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, page...)
+
+		if cursor.NextToken() == "" {
+			return files, nil
+		}
+
+		cursor = pagination.NewCursor(cursor.NextToken(), pageSize)
+	}
+}
+
+func (fs *FileSystem) canView(ctx context.Context, op string, name string, f *file.File) error {
+	var (
+		ok  bool
+		err error
+	)
+
+	if f.IsDir {
+		ok, err = fs.PermissionService.CanViewDirectory(ctx, fs.User.ID.String(), f.ID.String())
+	} else {
+		ok, err = fs.PermissionService.CanViewFile(ctx, fs.User.ID.String(), f.ID.String())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return pathError(op, name, os.ErrPermission)
+	}
+
+	return nil
+}
+
+func (fs *FileSystem) canEdit(ctx context.Context, op string, name string, f *file.File) error {
+	var (
+		ok  bool
+		err error
+	)
+
+	if f.IsDir {
+		ok, err = fs.PermissionService.CanEditDirectory(ctx, fs.User.ID.String(), f.ID.String())
+	} else {
+		ok, err = fs.PermissionService.CanEditFile(ctx, fs.User.ID.String(), f.ID.String())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return pathError(op, name, os.ErrPermission)
+	}
+
+	return nil
+}
+
+func (fs *FileSystem) writeLogs(ctx context.Context, logs ...file.Log) {
+	if len(logs) == 0 {
+		return
+	}
+
+	if err := fs.FileStore.WriteLogs(ctx, logs); err != nil {
+		fs.Logger.Errorw(err.Error())
+	}
+}
+
+func (fs *FileSystem) writeChanges(ctx context.Context, changes ...file.Change) {
+	if err := services.RecordChanges(ctx, fs.FileStore, fs.PubSubService, changes...); err != nil {
+		fs.Logger.Errorw(err.Error())
+	}
+}
+
+// applied logs err when the operation was applied but not recorded, which
+// then succeeded.
+func (fs *FileSystem) applied(err error) error {
+	if errors.Is(err, services.ErrNotRecorded) {
+		fs.Logger.Errorw(err.Error())
+		return nil
+	}
+
+	return err
+}
+
+func clean(name string) string {
+	return path.Clean("/" + name)
+}
+
+func pathError(op string, name string, err error) error {
+	return &os.PathError{Op: op, Path: name, Err: err}
+}
@@ -0,0 +1,116 @@
+package httpserver
+
+import (
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAccessKey godoc
+// @Summary CreateAccessKey
+// @Description CreateAccessKey creates a key signing the requests to the S3 gateway, its secret is only returned now
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request body model.CreateAccessKeyRequest true "Create access key request"
+// @Success 200 {object} model.SuccessResponse{data=identity.AccessKey}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/access-keys [post]
+func (s *Server) CreateAccessKey(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateAccessKeyRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	key := identity.NewAccessKey(user.ID, req.Name)
+	if err := s.UserStore.CreateAccessKey(ctx, key); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, key)
+}
+
+// ListAccessKeys godoc
+// @Summary ListAccessKeys
+// @Description ListAccessKeys lists the access keys of the user without their secrets
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=[]identity.AccessKey}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/access-keys [get]
+func (s *Server) ListAccessKeys(c echo.Context) error {
+	var ctx = app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	keys, err := s.UserStore.ListAccessKeys(ctx, user.ID)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	for i := range keys {
+		keys[i].Response()
+	}
+
+	return s.success(c, keys)
+}
+
+// DeleteAccessKey godoc
+// @Summary DeleteAccessKey
+// @Description DeleteAccessKey revokes an access key of the user
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteAccessKeyRequest true "Delete access key request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/access-keys/{id} [delete]
+func (s *Server) DeleteAccessKey(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteAccessKeyRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	if err := s.UserStore.DeleteAccessKey(ctx, user.ID, uuid.MustParse(req.ID)); err != nil {
+		if errors.Is(err, identity.ErrAccessKeyNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
@@ -11,6 +11,7 @@ import (
 	gonanoid "github.com/matoous/go-nanoid/v2"
 
 	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/adapters/services"
 	"github.com/SeaCloudHub/backend/domain/file"
 	"github.com/SeaCloudHub/backend/domain/identity"
 	"github.com/SeaCloudHub/backend/pkg/app"
@@ -18,6 +19,7 @@ import (
 	"github.com/SeaCloudHub/backend/pkg/pagination"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
 // AdminMe godoc
@@ -664,6 +666,16 @@ func (s *Server) DeleteIdentity(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
+	// Delete tags by user
+	if err := s.FileStore.DeleteTagsByUserID(ctx, user.ID); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// Delete smart folders by user
+	if err := s.FileStore.DeleteSmartFoldersByUserID(ctx, user.ID); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
 	// Delete permissions
 	if err := s.PermissionService.DeleteUserPermissions(ctx, user.ID.String()); err != nil {
 		return s.error(c, apperror.ErrInternalServer(err))
@@ -695,6 +707,26 @@ func (s *Server) DeleteIdentity(c echo.Context) error {
 		if err := s.FileStore.DeleteStarByFileID(ctx, f.ID); err != nil {
 			return s.error(c, apperror.ErrInternalServer(err))
 		}
+
+		// Delete tags applied to files
+		if err := s.FileStore.DeleteFileTagsByFileID(ctx, f.ID); err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		// Delete comments on files
+		if err := s.FileStore.DeleteCommentsByFileID(ctx, f.ID); err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		// Delete media metadata
+		if err := s.FileStore.DeleteMediaByFileID(ctx, f.ID); err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		// Delete previews
+		if err := s.FileStore.DeletePreviewByFileID(ctx, f.ID); err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
 	}
 
 	return s.success(c, nil)
@@ -771,6 +803,324 @@ func (s *Server) Logs(c echo.Context) error {
 	})
 }
 
+// CreateBackfill godoc
+// @Summary CreateBackfill
+// @Description CreateBackfill sends the matching files to the thumbnail worker again, the progress is reported by GetBackfill
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.CreateBackfillRequest true "Create backfill request"
+// @Success 200 {object} model.SuccessResponse{data=file.Backfill}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/thumbnails/backfills [post]
+func (s *Server) CreateBackfill(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateBackfillRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	b := file.NewBackfill(file.BackfillFilter{
+		Type:    req.Type,
+		After:   req.After,
+		Before:  req.Before,
+		Missing: req.Missing,
+	}, &user.ID)
+	if err := s.FileStore.CreateBackfill(ctx, b); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// enqueueing can take a while on large installations
+	requestID := s.requestID(c)
+	go func() {
+		if err := services.EnqueueBackfill(context.Background(), s.FileStore, s.PubSubService, b); err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", requestID))
+		}
+	}()
+
+	return s.success(c, b)
+}
+
+// ListBackfills godoc
+// @Summary ListBackfills
+// @Description ListBackfills
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request query model.ListBackfillsRequest true "List backfills request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListBackfillsResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/thumbnails/backfills [get]
+func (s *Server) ListBackfills(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListBackfillsRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	backfills, err := s.FileStore.ListBackfills(ctx, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListBackfillsResponse{
+		Backfills: backfills,
+		Cursor:    cursor.NextToken(),
+	})
+}
+
+// GetBackfill godoc
+// @Summary GetBackfill
+// @Description GetBackfill returns how many files of a backfill succeeded, failed or were skipped
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.GetBackfillRequest true "Get backfill request"
+// @Success 200 {object} model.SuccessResponse{data=file.Backfill}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/thumbnails/backfills/{id} [get]
+func (s *Server) GetBackfill(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.GetBackfillRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	b, err := s.FileStore.GetBackfill(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, b)
+}
+
+// CreateScrub godoc
+// @Summary CreateScrub
+// @Description CreateScrub compares the files with their content in the storage, the issues found are listed by ListScrubIssues
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.CreateScrubRequest true "Create scrub request"
+// @Success 200 {object} model.SuccessResponse{data=file.Scrub}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/scrubs [post]
+func (s *Server) CreateScrub(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateScrubRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	sc := file.NewScrub(file.ScrubOptions{
+		Repair:     req.Repair,
+		Quarantine: req.Quarantine,
+	}, &user.ID)
+	if err := s.FileStore.CreateScrub(ctx, sc); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// every file is checked against the storage, this takes a while
+	requestID := s.requestID(c)
+	job := *sc
+	go func() {
+		grace := time.Duration(req.GraceMinutes) * time.Minute
+		if err := services.Scrub(context.Background(), s.FileStore, s.FileService, &job, req.Concurrency, grace); err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", requestID))
+		}
+	}()
+
+	return s.success(c, sc)
+}
+
+// ListScrubs godoc
+// @Summary ListScrubs
+// @Description ListScrubs
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request query model.ListScrubsRequest true "List scrubs request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListScrubsResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/scrubs [get]
+func (s *Server) ListScrubs(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListScrubsRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	scrubs, err := s.FileStore.ListScrubs(ctx, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListScrubsResponse{
+		Scrubs: scrubs,
+		Cursor: cursor.NextToken(),
+	})
+}
+
+// GetScrub godoc
+// @Summary GetScrub
+// @Description GetScrub returns the progress of a scrub and how many missing, mismatched and orphaned files it found
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.GetScrubRequest true "Get scrub request"
+// @Success 200 {object} model.SuccessResponse{data=file.Scrub}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/scrubs/{id} [get]
+func (s *Server) GetScrub(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.GetScrubRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	sc, err := s.FileStore.GetScrub(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, sc)
+}
+
+// ListScrubIssues godoc
+// @Summary ListScrubIssues
+// @Description ListScrubIssues lists the issues found by a scrub in the order they were found
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "Scrub ID"
+// @Param request query model.ListScrubIssuesRequest true "List scrub issues request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListScrubIssuesResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/scrubs/{id}/issues [get]
+func (s *Server) ListScrubIssues(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListScrubIssuesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	sc, err := s.FileStore.GetScrub(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	issues, err := s.FileStore.ListScrubIssues(ctx, sc.ID, req.Kind, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListScrubIssuesResponse{
+		Issues: issues,
+		Cursor: cursor.NextToken(),
+	})
+}
+
 func (s *Server) RegisterAdminRoutes(router *echo.Group) {
 	router.Use(s.adminMiddleware)
 	router.GET("/me", s.AdminMe)
@@ -790,8 +1140,19 @@ func (s *Server) RegisterAdminRoutes(router *echo.Group) {
 	router.PATCH("/identities/:identity_id/state", s.UpdateIdentityState)
 	router.PATCH("/identities/:identity_id/storage", s.ChangeUserStorageCapacity)
 	router.GET("/identities/:identity_id/files", s.GetIdentityFiles)
+	router.POST("/identities/:identity_id/exports", s.CreateIdentityExport)
+	router.GET("/identities/:identity_id/exports", s.ListIdentityExports)
 
 	router.GET("/storages", s.ListStorages)
+
+	router.POST("/thumbnails/backfills", s.CreateBackfill)
+	router.GET("/thumbnails/backfills", s.ListBackfills)
+	router.GET("/thumbnails/backfills/:id", s.GetBackfill)
+
+	router.POST("/scrubs", s.CreateScrub)
+	router.GET("/scrubs", s.ListScrubs)
+	router.GET("/scrubs/:id", s.GetScrub)
+	router.GET("/scrubs/:id/issues", s.ListScrubIssues)
 }
 
 func (s *Server) createUser(ctx context.Context, user *identity.User, rootID string) error {
@@ -0,0 +1,100 @@
+package httpserver
+
+import (
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListChanges godoc
+// @Summary ListChanges
+// @Description ListChanges lists the changes of the files of the user after the cursor, oldest first. The returned cursor lists the changes that follow, it is returned even when there are none yet. Without a cursor the whole journal is listed.
+// @Tags change
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request query model.ListChangesRequest true "List changes request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListChangesResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /changes [get]
+func (s *Server) ListChanges(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListChangesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	changes, more, err := s.FileStore.ListChanges(ctx, user.ID, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	for i := range changes {
+		changes[i].Response()
+	}
+
+	return s.success(c, model.ListChangesResponse{
+		Changes: changes,
+		Cursor:  cursor.NextToken(),
+		HasMore: more,
+	})
+}
+
+// GetChangesStartCursor godoc
+// @Summary GetChangesStartCursor
+// @Description GetChangesStartCursor returns the cursor of the changes that follow, to be taken before an initial sync of the files
+// @Tags change
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=model.GetChangesStartCursorResponse}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /changes/start-cursor [get]
+func (s *Server) GetChangesStartCursor(c echo.Context) error {
+	ctx := app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	token, err := s.FileStore.GetChangesStartToken(ctx, user.ID)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.GetChangesStartCursorResponse{Cursor: token})
+}
+
+func (s *Server) RegisterChangeRoutes(router *echo.Group) {
+	router.Use(s.passwordChangedAtMiddleware)
+	router.GET("", s.ListChanges)
+	router.GET("/start-cursor", s.GetChangesStartCursor)
+}
+
+func (s *Server) writeChanges(c echo.Context, changes ...file.Change) {
+	if err := services.RecordChanges(app.NewEchoContextAdapter(c), s.FileStore, s.PubSubService, changes...); err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+	}
+}
@@ -0,0 +1,454 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/notification"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CreateComment godoc
+// @Summary CreateComment
+// @Description CreateComment starts a thread, or replies to one when parent_id is set
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param payload body model.CreateCommentRequest true "Create comment request"
+// @Success 200 {object} model.SuccessResponse{data=file.Comment}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/comments [post]
+func (s *Server) CreateComment(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateCommentRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.commentedFile(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	comment := file.NewComment(e.ID, user.ID, req.Content)
+
+	if req.ParentID != "" {
+		parent, err := s.fileComment(ctx, e, req.ParentID)
+		if err != nil {
+			return s.error(c, err)
+		}
+
+		// replies always belong to the thread, never to another reply
+		threadID := parent.ID
+		if !parent.IsThread() {
+			threadID = *parent.ParentID
+		}
+
+		comment.WithParentID(&threadID)
+	}
+
+	if err := s.FileStore.CreateComment(ctx, comment); err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	comment.User = user
+
+	if mentions := comment.Mentions(); len(mentions) > 0 {
+		token := *c.Get(ContextKeyIdentity).(*identity.Identity).Session.Token
+
+		go s.notifyMentions(c, user, e, comment, mentions, token)
+	}
+
+	// write log
+	if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(e.ID, user.ID, file.LogActionComment)}); err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+	}
+
+	return s.success(c, comment)
+}
+
+// ListComments godoc
+// @Summary ListComments
+// @Description ListComments lists the comment threads of a file, newest first, with their replies
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param request query model.ListCommentsRequest true "List comments request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListCommentsResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/comments [get]
+func (s *Server) ListComments(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListCommentsRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.commentedFile(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	comments, err := s.FileStore.ListComments(ctx, e.ID, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListCommentsResponse{
+		Comments: comments,
+		Cursor:   cursor.NextToken(),
+	})
+}
+
+// UpdateComment godoc
+// @Summary UpdateComment
+// @Description UpdateComment edits the content of a comment, only the author can edit it
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param comment_id path string true "Comment ID"
+// @Param payload body model.UpdateCommentRequest true "Update comment request"
+// @Success 200 {object} model.SuccessResponse{data=file.Comment}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/comments/{comment_id} [patch]
+func (s *Server) UpdateComment(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.UpdateCommentRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.commentedFile(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	comment, err := s.fileComment(ctx, e, req.CommentID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	if comment.UserID != user.ID {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
+	}
+
+	comment.Content = req.Content
+
+	if err := s.FileStore.UpdateComment(ctx, comment); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, comment)
+}
+
+// ResolveComment godoc
+// @Summary ResolveComment
+// @Description ResolveComment resolves or reopens a thread, allowed for its author and the file editors
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param comment_id path string true "Comment ID"
+// @Param payload body model.ResolveCommentRequest true "Resolve comment request"
+// @Success 200 {object} model.SuccessResponse{data=file.Comment}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/comments/{comment_id}/resolve [patch]
+func (s *Server) ResolveComment(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ResolveCommentRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.commentedFile(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	comment, err := s.fileComment(ctx, e, req.CommentID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	if !comment.IsThread() {
+		return s.error(c, apperror.ErrInvalidParam(errors.New("only threads can be resolved")))
+	}
+
+	if comment.UserID != user.ID {
+		canEdit, err := s.canEditFile(ctx, user, e)
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		if !canEdit {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
+		}
+	}
+
+	if req.Resolved {
+		comment.Resolve(user.ID)
+	} else {
+		comment.Reopen()
+	}
+
+	if err := s.FileStore.UpdateComment(ctx, comment); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, comment)
+}
+
+// DeleteComment godoc
+// @Summary DeleteComment
+// @Description DeleteComment deletes a comment and its replies, allowed for its author and the file editors
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteCommentRequest true "Delete comment request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/comments/{comment_id} [delete]
+func (s *Server) DeleteComment(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteCommentRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.commentedFile(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	comment, err := s.fileComment(ctx, e, req.CommentID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	if comment.UserID != user.ID {
+		canEdit, err := s.canEditFile(ctx, user, e)
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		if !canEdit {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToDelete))
+		}
+	}
+
+	if err := s.FileStore.DeleteComment(ctx, comment.ID); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// commentedFile returns the file being commented on. Everyone who can view a
+// file can read and write comments on it.
+func (s *Server) commentedFile(ctx context.Context, user *identity.User, id string) (*file.File, error) {
+	var canView bool
+
+	e, err := s.FileStore.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if e.IsDir {
+		canView, err = s.PermissionService.CanViewDirectory(ctx, user.ID.String(), e.ID.String())
+	} else {
+		canView, err = s.PermissionService.CanViewFile(ctx, user.ID.String(), e.ID.String())
+	}
+
+	if err != nil {
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if !canView {
+		return nil, apperror.ErrForbidden(permission.ErrNotPermittedToView)
+	}
+
+	return e, nil
+}
+
+// fileComment returns the comment only if it was left on the given file.
+func (s *Server) fileComment(ctx context.Context, e *file.File, id string) (*file.Comment, error) {
+	comment, err := s.FileStore.GetComment(ctx, uuid.MustParse(id))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if comment.FileID != e.ID {
+		return nil, apperror.ErrEntityNotFound(file.ErrNotFound)
+	}
+
+	return comment, nil
+}
+
+func (s *Server) canEditFile(ctx context.Context, user *identity.User, e *file.File) (bool, error) {
+	if e.IsDir {
+		return s.PermissionService.CanEditDirectory(ctx, user.ID.String(), e.ID.String())
+	}
+
+	return s.PermissionService.CanEditFile(ctx, user.ID.String(), e.ID.String())
+}
+
+// notifyMentions pushes a notification to every mentioned user who can view the file.
+func (s *Server) notifyMentions(c echo.Context, user *identity.User, e *file.File, comment *file.Comment, emails []string, token string) {
+	var (
+		ctx           = context.Background()
+		notifications []notification.Notification
+	)
+
+	users, err := s.UserStore.ListByEmails(ctx, emails)
+	if err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+		return
+	}
+
+	for _, u := range users {
+		if u.ID == user.ID {
+			continue
+		}
+
+		var canView bool
+		if e.IsDir {
+			canView, err = s.PermissionService.CanViewDirectory(ctx, u.ID.String(), e.ID.String())
+		} else {
+			canView, err = s.PermissionService.CanViewFile(ctx, u.ID.String(), e.ID.String())
+		}
+
+		if err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+			continue
+		}
+
+		if !canView {
+			continue
+		}
+
+		content := map[string]interface{}{
+			"file":         e.Name,
+			"file_id":      e.ID.String(),
+			"is_dir":       e.IsDir,
+			"comment_id":   comment.ID.String(),
+			"comment":      comment.Content,
+			"owner_avatar": user.AvatarURL,
+			"owner_name":   fmt.Sprint(user.FirstName, " ", user.LastName),
+		}
+
+		contentBytes, _ := json.Marshal(content)
+
+		notifications = append(notifications, notification.Notification{
+			UserID:  u.ID.String(),
+			Content: string(contentBytes),
+		})
+	}
+
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err := s.NotificationService.SendNotification(ctx, notifications, user.ID.String(), token); err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+	}
+}
@@ -0,0 +1,370 @@
+package httpserver
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventsKeepAlive is how often a comment is sent on an idle stream, so
+	// that the proxies keep it open.
+	eventsKeepAlive = 30 * time.Second
+
+	// eventsBuffer is how many events a client can lag behind before it is
+	// disconnected, it resumes from the last event it received once it
+	// reconnects.
+	eventsBuffer = 256
+
+	// eventsReplayPage is the number of missed events read at once.
+	eventsReplayPage = 100
+
+	// eventsPermissionTTL is how long a stream trusts that the user can, or
+	// cannot, view a directory.
+	eventsPermissionTTL = 30 * time.Second
+)
+
+var ErrInvalidLastEventID = errors.New("invalid last event id")
+
+// StreamEvents godoc
+// @Summary StreamEvents
+// @Description StreamEvents streams as server-sent events the events of the files in the directories the user can view, and the events of the user such as new shares and finished thumbnails. A client that reconnects with the Last-Event-ID header receives the events it missed, as long as they are still kept.
+// @Tags event
+// @Produce text/event-stream
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} file.Event
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Router /events [get]
+func (s *Server) StreamEvents(c echo.Context) error {
+	ctx := app.NewEchoContextAdapter(c)
+
+	lastID := c.Request().Header.Get("Last-Event-ID")
+	if _, _, ok := parseEventID(lastID); lastID != "" && !ok {
+		return s.error(c, apperror.ErrInvalidParam(ErrInvalidLastEventID))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+	perms := make(dirPermissions)
+
+	// subscribe before the missed events are read, so that none is lost in
+	// between
+	hub := s.events()
+	events := hub.subscribe()
+	defer hub.unsubscribe(events)
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	for lastID != "" {
+		msgs, err := s.PubSubService.Range(ctx, file.EventsChannel, lastID, eventsReplayPage)
+		if err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+			return nil
+		}
+
+		for _, msg := range msgs {
+			e := &streamedEvent{}
+			if err := json.Unmarshal([]byte(msg.Payload), &e.Event); err != nil {
+				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+				continue
+			}
+
+			e.ID = msg.ID
+			if err := s.sendEvent(c, user, perms, e); err != nil {
+				return nil
+			}
+
+			lastID = msg.ID
+		}
+
+		if len(msgs) < eventsReplayPage {
+			break
+		}
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			// sent with the missed events already
+			if lastID != "" && compareEventIDs(e.ID, lastID) <= 0 {
+				continue
+			}
+
+			if err := s.sendEvent(c, user, perms, e); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+
+			w.Flush()
+		}
+	}
+}
+
+func (s *Server) RegisterEventRoutes(router *echo.Group) {
+	router.Use(s.passwordChangedAtMiddleware)
+	router.GET("", s.StreamEvents)
+}
+
+// sendEvent writes e to the stream when the user can see it.
+func (s *Server) sendEvent(c echo.Context, user *identity.User, perms dirPermissions, e *streamedEvent) error {
+	if !s.canViewEvent(c, user, perms, e) {
+		return nil
+	}
+
+	event := e.Event
+
+	data, err := json.Marshal(event.Response())
+	if err != nil {
+		return err
+	}
+
+	w := c.Response()
+	if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", e.ID, data); err != nil {
+		return err
+	}
+
+	w.Flush()
+
+	return nil
+}
+
+// canViewEvent reports whether the user can view the directory of the file of
+// the event, before or after a move, or whether the event is for the user
+// only.
+func (s *Server) canViewEvent(c echo.Context, user *identity.User, perms dirPermissions, e *streamedEvent) bool {
+	if e.UserID != nil {
+		return *e.UserID == user.ID
+	}
+
+	if e.TreeOwnerID() == user.ID {
+		return true
+	}
+
+	if s.canViewEventDir(c, user, perms, &e.dir, e.Dir()) {
+		return true
+	}
+
+	return e.FromPath != "" && s.canViewEventDir(c, user, perms, &e.fromDir, e.FromDir())
+}
+
+func (s *Server) canViewEventDir(c echo.Context, user *identity.User, perms dirPermissions, dir *dirLookup, fullPath string) bool {
+	ctx := app.NewEchoContextAdapter(c)
+
+	dirID, err := dir.id(ctx, s.FileStore, fullPath)
+	if err != nil {
+		if !errors.Is(err, file.ErrNotFound) {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+		}
+
+		return false
+	}
+
+	if canView, ok := perms.get(dirID); ok {
+		return canView
+	}
+
+	canView, err := s.PermissionService.CanViewDirectory(ctx, user.ID.String(), dirID)
+	if err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+		return false
+	}
+
+	perms.set(dirID, canView)
+
+	return canView
+}
+
+// dirPermissions caches whether the user of a stream can view a directory,
+// the events of a busy directory are not checked one by one.
+type dirPermissions map[string]dirPermission
+
+type dirPermission struct {
+	canView   bool
+	expiresAt time.Time
+}
+
+func (p dirPermissions) get(dirID string) (bool, bool) {
+	perm, ok := p[dirID]
+	if !ok || time.Now().After(perm.expiresAt) {
+		return false, false
+	}
+
+	return perm.canView, true
+}
+
+func (p dirPermissions) set(dirID string, canView bool) {
+	now := time.Now()
+
+	// drop the expired entries so a long stream does not grow forever
+	for id, perm := range p {
+		if now.After(perm.expiresAt) {
+			delete(p, id)
+		}
+	}
+
+	p[dirID] = dirPermission{canView: canView, expiresAt: now.Add(eventsPermissionTTL)}
+}
+
+// streamedEvent is an event shared by the clients, the directories of its
+// file are looked up once for all of them.
+type streamedEvent struct {
+	file.Event
+
+	dir     dirLookup
+	fromDir dirLookup
+}
+
+type dirLookup struct {
+	once  sync.Once
+	dirID string
+	err   error
+}
+
+func (l *dirLookup) id(ctx context.Context, fileStore file.Store, fullPath string) (string, error) {
+	l.once.Do(func() {
+		// the lookup is shared, it does not end with the first client
+		dir, err := fileStore.GetByFullPath(context.WithoutCancel(ctx), fullPath)
+		if err != nil {
+			l.err = err
+			return
+		}
+
+		l.dirID = dir.ID.String()
+	})
+
+	return l.dirID, l.err
+}
+
+// eventHub forwards the published events to the connected clients.
+type eventHub struct {
+	mu      sync.Mutex
+	clients map[chan *streamedEvent]struct{}
+}
+
+// events returns the hub of the server, it subscribes to the events the first
+// time.
+func (s *Server) events() *eventHub {
+	s.eventHubOnce.Do(func() {
+		s.eventHub = &eventHub{clients: make(map[chan *streamedEvent]struct{})}
+
+		go s.eventHub.run(s.PubSubService.Subscribe(context.Background(), file.EventsChannel), s.Logger)
+	})
+
+	return s.eventHub
+}
+
+func (h *eventHub) run(ps pubsub.PubSub, logger *zap.SugaredLogger) {
+	for {
+		msg, err := ps.ReceiveMessage(context.Background())
+		if err != nil {
+			logger.Errorw(err.Error())
+			time.Sleep(time.Second)
+
+			continue
+		}
+
+		e := &streamedEvent{}
+		if err := json.Unmarshal([]byte(msg.Payload), &e.Event); err != nil {
+			logger.Errorw(err.Error())
+			continue
+		}
+
+		h.broadcast(e)
+	}
+}
+
+func (h *eventHub) broadcast(e *streamedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- e:
+		default:
+			// too slow, the client resumes once it reconnects
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *eventHub) subscribe() chan *streamedEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan *streamedEvent, eventsBuffer)
+	h.clients[ch] = struct{}{}
+
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan *streamedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// parseEventID splits an ID of the history of the events, made of a time in
+// milliseconds and a sequence number.
+func parseEventID(id string) (uint64, uint64, bool) {
+	ms, seq, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	m, err := strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	s, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return m, s, true
+}
+
+func compareEventIDs(a, b string) int {
+	am, as, _ := parseEventID(a)
+	bm, bs, _ := parseEventID(b)
+
+	return cmp.Or(cmp.Compare(am, bm), cmp.Compare(as, bs))
+}
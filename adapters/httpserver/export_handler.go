@@ -0,0 +1,327 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/notification"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"go.uber.org/zap"
+)
+
+// CreateExport godoc
+// @Summary CreateExport
+// @Description CreateExport archives every file of the user with a manifest of their metadata, the user is notified with a download link once it is ready
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=file.Export}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/export [post]
+func (s *Server) CreateExport(c echo.Context) error {
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	return s.startExport(c, user, user)
+}
+
+// ListExports godoc
+// @Summary ListExports
+// @Description ListExports
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request query model.ListExportsRequest true "List exports request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListExportsResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/exports [get]
+func (s *Server) ListExports(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListExportsRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	return s.listExports(c, user.ID, req.Cursor, req.Limit)
+}
+
+// GetExport godoc
+// @Summary GetExport
+// @Description GetExport returns the status of an export and its download link once it is ready
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.GetExportRequest true "Get export request"
+// @Success 200 {object} model.SuccessResponse{data=file.Export}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/exports/{id} [get]
+func (s *Server) GetExport(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.GetExportRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.FileStore.GetExport(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if e.UserID != user.ID {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	return s.success(c, e.Response())
+}
+
+// DownloadExport godoc
+// @Summary DownloadExport
+// @Description DownloadExport serves the archive of an export, the link is authorized by its token until the export expires
+// @Tags export
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param request query model.DownloadExportRequest true "Download export request"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /exports/{id}/download [get]
+func (s *Server) DownloadExport(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DownloadExportRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	e, err := s.FileStore.GetExport(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// an invalid token is reported like an unknown export
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(e.Token)) != 1 {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	if !e.Available(time.Now()) {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrExportExpired))
+	}
+
+	f, _, err := s.FileService.DownloadFile(ctx, e.StoragePath())
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+	defer f.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "export-"+e.ID.String()+".zip"))
+	c.Response().Header().Set(echo.HeaderContentLength, fmt.Sprint(e.Size))
+
+	return c.Stream(http.StatusOK, "application/zip", f)
+}
+
+// CreateIdentityExport godoc
+// @Summary CreateIdentityExport
+// @Description CreateIdentityExport archives every file of a user like CreateExport, the user is notified with the download link
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.CreateIdentityExportRequest true "Create identity export request"
+// @Success 200 {object} model.SuccessResponse{data=file.Export}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/identities/{identity_id}/exports [post]
+func (s *Server) CreateIdentityExport(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateIdentityExportRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, err := s.UserStore.GetByID(ctx, req.IdentityId)
+	if err != nil {
+		if errors.Is(err, identity.ErrIdentityNotFound) {
+			return s.error(c, apperror.ErrIdentityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	admin, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	return s.startExport(c, user, admin)
+}
+
+// ListIdentityExports godoc
+// @Summary ListIdentityExports
+// @Description ListIdentityExports
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param identity_id path string true "Identity ID"
+// @Param request query model.ListIdentityExportsRequest true "List identity exports request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListExportsResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /admin/identities/{identity_id}/exports [get]
+func (s *Server) ListIdentityExports(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListIdentityExportsRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	return s.listExports(c, uuid.MustParse(req.IdentityId), req.Cursor, req.Limit)
+}
+
+func (s *Server) RegisterExportRoutes(router *echo.Group) {
+	router.GET("/:id/download", s.DownloadExport)
+}
+
+// startExport builds the archive of user in the background and notifies the
+// user once it can be downloaded. Only one export per user runs at a time.
+func (s *Server) startExport(c echo.Context, user *identity.User, createdBy *identity.User) error {
+	ctx := app.NewEchoContextAdapter(c)
+
+	if _, err := s.FileStore.GetPendingExport(ctx, user.ID); err == nil {
+		return s.error(c, apperror.ErrExportInProgress(file.ErrExportInProgress))
+	} else if !errors.Is(err, file.ErrNotFound) {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	e := file.NewExport(user.ID, gonanoid.Must(32), &createdBy.ID)
+	if err := s.FileStore.CreateExport(ctx, e); err != nil {
+		if errors.Is(err, file.ErrExportInProgress) {
+			return s.error(c, apperror.ErrExportInProgress(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	var (
+		requestID = s.requestID(c)
+		token     = *c.Get(ContextKeyIdentity).(*identity.Identity).Session.Token
+		job       = *e
+	)
+
+	go func() {
+		ctx := context.Background()
+
+		if err := services.Export(ctx, s.FileStore, s.FileService, user, &job, s.Config.Export.TTL); err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", requestID))
+			return
+		}
+
+		content, _ := json.Marshal(map[string]interface{}{
+			"export_id":    job.ID.String(),
+			"download_url": job.Response().DownloadURL,
+			"expires_at":   job.ExpiresAt,
+			"size":         job.Size,
+		})
+
+		notifications := []notification.Notification{{UserID: user.ID.String(), Content: string(content)}}
+		if err := s.NotificationService.SendNotification(ctx, notifications, createdBy.ID.String(), token); err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", requestID))
+		}
+	}()
+
+	return s.success(c, e)
+}
+
+func (s *Server) listExports(c echo.Context, userID uuid.UUID, cursorToken string, limit int) error {
+	ctx := app.NewEchoContextAdapter(c)
+
+	cursor := pagination.NewCursor(cursorToken, limit)
+
+	exports, err := s.FileStore.ListExports(ctx, userID, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	for i := range exports {
+		exports[i] = *exports[i].Response()
+	}
+
+	return s.success(c, model.ListExportsResponse{
+		Exports: exports,
+		Cursor:  cursor.NextToken(),
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/adapters/services"
 
 	"github.com/SeaCloudHub/backend/domain/file"
 	"github.com/SeaCloudHub/backend/domain/identity"
@@ -119,6 +120,17 @@ func (s *Server) GetMetadata(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
+	tags, err := s.FileStore.ListFileTags(ctx, f.ID, uuid.MustParse(id.ID))
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// media metadata only exists once the thumbnail worker has processed the file
+	media, err := s.FileStore.GetMedia(ctx, f.ID)
+	if err != nil && !errors.Is(err, file.ErrNotFound) {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
 	userIDs := lo.Map(users, func(user permission.FileUser, _ int) string {
 		return user.UserID
 	})
@@ -144,9 +156,10 @@ func (s *Server) GetMetadata(c echo.Context) error {
 	})
 
 	return s.success(c, model.GetMetadataResponse{
-		File:    *f.Response().WithUserRoles(userRoles).WithIsStarred(isStarred),
+		File:    *f.Response().WithUserRoles(userRoles).WithIsStarred(isStarred).WithTags(tags),
 		Parents: parents,
 		Users:   users,
+		Media:   media,
 	})
 }
 
@@ -156,11 +169,14 @@ func (s *Server) GetMetadata(c echo.Context) error {
 // @Tags file
 // @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
 // @Param request path model.DownloadRequest true "Download file request"
+// @Param Range header string false "Single byte range, e.g. bytes=0-1023"
 // @Success 200 {file} file
+// @Success 206 {file} file
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
 // @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /files/{id}/download [get]
 func (s *Server) Download(c echo.Context) error {
@@ -210,7 +226,28 @@ func (s *Server) Download(c echo.Context) error {
 		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
 	}
 
-	f, mime, err := s.FileService.DownloadFile(ctx, e.ID.String())
+	if e.Quarantined() {
+		return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+	}
+
+	rng, err := app.ParseRange(c.Request().Header.Get("Range"), int64(e.Size))
+	if err != nil {
+		c.Response().Header().Set("Content-Range", fmt.Sprintf("bytes */%d", e.Size))
+		return c.NoContent(http.StatusRequestedRangeNotSatisfiable)
+	}
+
+	var (
+		f      io.ReadCloser
+		mime   string
+		status = http.StatusOK
+	)
+
+	if rng != nil {
+		f, mime, err = s.FileService.DownloadRange(ctx, e.ID.String(), rng.Offset, rng.Length)
+		status = http.StatusPartialContent
+	} else {
+		f, mime, err = s.FileService.DownloadFile(ctx, e.ID.String())
+	}
 	if err != nil {
 		if errors.Is(err, file.ErrNotFound) {
 			return s.error(c, apperror.ErrEntityNotFound(err))
@@ -220,12 +257,332 @@ func (s *Server) Download(c echo.Context) error {
 	}
 	defer f.Close()
 
-	// write log
-	if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(e.ID, uuid.MustParse(id.ID), file.LogActionOpen)}); err != nil {
-		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+	// write log, once per download rather than for every range
+	if rng == nil || rng.Offset == 0 {
+		if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(e.ID, uuid.MustParse(id.ID), file.LogActionOpen)}); err != nil {
+			s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+		}
+	}
+
+	c.Response().Header().Set("Accept-Ranges", "bytes")
+	if rng != nil {
+		c.Response().Header().Set("Content-Range", rng.ContentRange(int64(e.Size)))
+		c.Response().Header().Set(echo.HeaderContentLength, fmt.Sprint(rng.Length))
+	}
+
+	return c.Stream(status, mime, f)
+}
+
+// GetThumbnail godoc
+// @Summary GetThumbnail
+// @Description GetThumbnail returns the thumbnail closest to the requested size, as WebP when the client accepts it
+// @Tags file
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param request query model.GetThumbnailRequest true "Get thumbnail request"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/thumbnail [get]
+func (s *Server) GetThumbnail(c echo.Context) error {
+	var (
+		ctx     = app.NewEchoContextAdapter(c)
+		req     model.GetThumbnailRequest
+		canView bool
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	id, _ := c.Get(ContextKeyIdentity).(*identity.Identity)
+
+	e, err := s.FileStore.GetByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if e.IsDir {
+		canView, err = s.PermissionService.CanViewDirectory(ctx, id.ID, e.ID.String())
+	} else {
+		canView, err = s.PermissionService.CanViewFile(ctx, id.ID, e.ID.String())
+	}
+
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !canView {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+	}
+
+	if e.Quarantined() {
+		return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+	}
+
+	// files processed before thumbnail sets existed only have the single PNG
+	var url string
+	if t := file.PickThumbnail(e.Thumbnails, req.Size); t != nil {
+		url = t.PNG
+
+		acceptsWebP := strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "image/webp")
+		if t.WebP != "" && (req.Format == "webp" || (req.Format == "" && acceptsWebP)) {
+			url = t.WebP
+		}
+	} else if e.Thumbnail != nil {
+		url = *e.Thumbnail
+	}
+
+	if url == "" {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	reader, contentType, err := s.FileService.DownloadFile(ctx, filepath.Join("/assets", "images", filepath.Base(url)))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+	defer reader.Close()
+
+	c.Response().Header().Set(echo.HeaderVary, echo.HeaderAccept)
+
+	return c.Stream(http.StatusOK, contentType, reader)
+}
+
+// GetRendition godoc
+// @Summary GetRendition
+// @Description GetRendition returns the PDF rendition of an office document generated by the thumbnail worker
+// @Tags file
+// @Produce application/pdf
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.GetRenditionRequest true "Get rendition request"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/rendition [get]
+func (s *Server) GetRendition(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.GetRenditionRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	id, _ := c.Get(ContextKeyIdentity).(*identity.Identity)
+
+	e, err := s.FileStore.GetByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if e.IsDir {
+		return s.error(c, apperror.ErrFileOnlyOperation())
+	}
+
+	canView, err := s.PermissionService.CanViewFile(ctx, id.ID, e.ID.String())
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !canView {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+	}
+
+	if e.Quarantined() {
+		return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+	}
+
+	// only office documents get a rendition, and only once the worker is done
+	if e.Rendition == nil {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	reader, contentType, err := s.FileService.DownloadFile(ctx, filepath.Join("/assets", "renditions", e.ID.String()+".pdf"))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+	defer reader.Close()
+
+	return c.Stream(http.StatusOK, contentType, reader)
+}
+
+// GetPreview godoc
+// @Summary GetPreview
+// @Description GetPreview returns the text snippet of text and JSON files, or the waveform peaks of audio files
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.GetPreviewRequest true "Get preview request"
+// @Success 200 {object} model.SuccessResponse{data=file.Preview}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/preview [get]
+func (s *Server) GetPreview(c echo.Context) error {
+	var (
+		ctx     = app.NewEchoContextAdapter(c)
+		req     model.GetPreviewRequest
+		canView bool
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	id, _ := c.Get(ContextKeyIdentity).(*identity.Identity)
+
+	e, err := s.FileStore.GetByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !e.IsDir {
+		canView, err = s.PermissionService.CanViewFile(ctx, id.ID, e.ID.String())
+	} else {
+		canView, err = s.PermissionService.CanViewDirectory(ctx, id.ID, e.ID.String())
+	}
+
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !canView {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+	}
+
+	if e.Quarantined() {
+		return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+	}
+
+	// previews only exist once the thumbnail worker has processed the file
+	preview, err := s.FileStore.GetPreview(ctx, e.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, preview)
+}
+
+// Stream godoc
+// @Summary Stream
+// @Description Stream serves the HLS master playlist (master.m3u8), the rendition playlists and the segments of a video
+// @Tags file
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param name path string true "Playlist or segment, e.g. master.m3u8 or 720p/segment_000.ts"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/stream/{name} [get]
+func (s *Server) Stream(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.StreamRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
 	}
 
-	return c.Stream(http.StatusOK, mime, f)
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	id, _ := c.Get(ContextKeyIdentity).(*identity.Identity)
+
+	e, err := s.FileStore.GetByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if e.IsDir {
+		return s.error(c, apperror.ErrFileOnlyOperation())
+	}
+
+	canView, err := s.PermissionService.CanViewFile(ctx, id.ID, e.ID.String())
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !canView {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+	}
+
+	if e.Quarantined() {
+		return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+	}
+
+	// the stream only exists once the transcoding worker is done
+	if e.Stream == nil {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	// cleaning from the root keeps the name inside the stream directory
+	name := filepath.Clean("/" + req.Name)
+
+	reader, contentType, err := s.FileService.DownloadFile(ctx, filepath.Join("/assets", "streams", e.ID.String(), name))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+	defer reader.Close()
+
+	return c.Stream(http.StatusOK, contentType, reader)
 }
 
 // DownloadBatch godoc
@@ -358,7 +715,7 @@ func (s *Server) UploadFiles(c echo.Context) error {
 
 		wp.Submit(func() {
 			// save files
-			f, err := s.createFile(ctx, e, src, file.Filename, user.ID, false, nil)
+			f, err := s.createFile(ctx, e, src, file.Filename, user.ID, false)
 			if err != nil {
 				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 				return
@@ -390,7 +747,7 @@ func (s *Server) UploadFiles(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
-	if err := s.PubSubService.Publish(ctx, "thumbnails", string(message)); err != nil {
+	if err := s.PubSubService.Enqueue(ctx, "thumbnails", string(message)); err != nil {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
@@ -403,6 +760,10 @@ func (s *Server) UploadFiles(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, lo.Map(resp, func(f file.File, index int) file.Change {
+		return file.NewChange(&f, file.ChangeCreate)
+	})...)
+
 	return s.success(c, resp)
 }
 
@@ -493,7 +854,7 @@ func (s *Server) UploadChunk(c echo.Context) error {
 				return s.error(c, apperror.ErrInternalServer(err))
 			}
 
-			if err := s.PubSubService.Publish(ctx, "thumbnails", string(message)); err != nil {
+			if err := s.PubSubService.Enqueue(ctx, "thumbnails", string(message)); err != nil {
 				return s.error(c, apperror.ErrInternalServer(err))
 			}
 
@@ -501,6 +862,8 @@ func (s *Server) UploadChunk(c echo.Context) error {
 			if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(f.ID, user.ID, file.LogActionCreate)}); err != nil {
 				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 			}
+
+			s.writeChanges(c, file.NewChange(f, file.ChangeCreate))
 		}
 	} else {
 		// check storage limit from client input
@@ -509,7 +872,7 @@ func (s *Server) UploadChunk(c echo.Context) error {
 		}
 
 		// create file
-		f, err = s.createFile(ctx, e, mpFile, fileHeader.Filename, user.ID, true, nil)
+		f, err = s.createFile(ctx, e, mpFile, fileHeader.Filename, user.ID, true)
 		if err != nil {
 			return s.error(c, apperror.ErrInternalServer(err))
 		}
@@ -575,8 +938,13 @@ func (s *Server) ListEntries(c echo.Context) error {
 		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
 	}
 
+	tag, err := s.tagFilter(ctx, user, req.Tag)
+	if err != nil {
+		return s.error(c, err)
+	}
+
 	cursor := pagination.NewCursor(req.Cursor, req.Limit)
-	filter := file.NewFilter(req.Type, req.After)
+	filter := file.NewFilter(req.Type, req.After).WithTag(tag)
 
 	files, err := s.FileStore.ListCursor(ctx, e.FullPath(), cursor, filter)
 	if err != nil {
@@ -600,9 +968,19 @@ func (s *Server) ListEntries(c echo.Context) error {
 
 	files = s.mapUserRolesAndStarred(ctx, user, files)
 
+	// smart folders are listed alongside the directories on the first page only
+	var smartFolders []file.SmartFolder
+	if req.Cursor == "" {
+		smartFolders, err = s.FileStore.ListSmartFoldersByParent(ctx, user.ID, e.ID)
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+	}
+
 	return s.success(c, model.ListEntriesResponse{
-		Entries: files,
-		Cursor:  cursor.NextToken(),
+		Entries:      files,
+		SmartFolders: smartFolders,
+		Cursor:       cursor.NextToken(),
 	})
 }
 
@@ -658,8 +1036,13 @@ func (s *Server) ListPageEntries(c echo.Context) error {
 		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
 	}
 
+	tag, err := s.tagFilter(ctx, user, req.Tag)
+	if err != nil {
+		return s.error(c, err)
+	}
+
 	pager := pagination.NewPager(req.Page, req.Limit)
-	filter := file.NewFilter(req.Type, req.After)
+	filter := file.NewFilter(req.Type, req.After).WithTag(tag).WithSort(req.Sort)
 	files, err := s.FileStore.ListPager(ctx, e.FullPath(), pager, filter, req.Query)
 	if err != nil {
 		return s.error(c, apperror.ErrInternalServer(err))
@@ -779,8 +1162,8 @@ func (s *Server) CreateDirectory(c echo.Context) error {
 		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
 	}
 
-	f := file.NewDirectory(req.Name).WithID(uuid.New()).WithPath(parent.FullPath()).WithOwnerID(user.ID)
-	if err := s.FileStore.Create(ctx, f); err != nil {
+	f := file.NewDirectory(req.Name).WithID(uuid.New()).WithOwnerID(user.ID)
+	if err := services.CreateDirectory(ctx, s.FileStore, s.PermissionService, parent, f); err != nil {
 		if errors.Is(err, file.ErrDirAlreadyExists) {
 			return s.error(c, apperror.ErrDirAlreadyExists(err))
 		}
@@ -788,15 +1171,13 @@ func (s *Server) CreateDirectory(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
-	if err := s.PermissionService.CreateDirectoryPermissions(ctx, user.ID.String(), f.ID.String(), parent.ID.String()); err != nil {
-		return s.error(c, apperror.ErrInternalServer(err))
-	}
-
 	// write log
 	if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(f.ID, user.ID, file.LogActionCreate)}); err != nil {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, file.NewChange(f, file.ChangeCreate))
+
 	return s.success(c, f.Response())
 }
 
@@ -907,6 +1288,14 @@ func (s *Server) Share(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	// the users it is shared with learn about the file too
+	changes := []file.Change{file.NewChange(e, file.ChangePermission)}
+	for _, userID := range userIDs {
+		changes = append(changes, changes[0].WithUserID(userID))
+	}
+
+	s.writeChanges(c, changes...)
+
 	return s.success(c, nil)
 }
 
@@ -1009,6 +1398,8 @@ func (s *Server) Access(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
+	s.writeChanges(c, file.NewChange(e, file.ChangePermission).WithUserID(user.ID))
+
 	return s.success(c, nil)
 }
 
@@ -1077,6 +1468,8 @@ func (s *Server) UpdateGeneralAccess(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, file.NewChange(e, file.ChangePermission))
+
 	return s.success(c, nil)
 }
 
@@ -1177,6 +1570,16 @@ func (s *Server) UpdateAccess(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	// the users whose access changed learn about the file too
+	changes := []file.Change{file.NewChange(e, file.ChangePermission)}
+	for _, a := range req.Access {
+		if a.UserID != user.ID.String() {
+			changes = append(changes, changes[0].WithUserID(uuid.MustParse(a.UserID)))
+		}
+	}
+
+	s.writeChanges(c, changes...)
+
 	return s.success(c, nil)
 }
 
@@ -1264,19 +1667,14 @@ func (s *Server) CopyFiles(c echo.Context) error {
 			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
 		}
 
+		if e.Quarantined() {
+			return s.error(c, apperror.ErrFileQuarantined(file.ErrQuarantined))
+		}
+
 		// copy file
 		wp.Submit(func() {
-			src, _, err := s.FileService.DownloadFile(ctx, e.ID.String())
-			if err != nil {
-				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
-				return
-			}
-			defer src.Close()
-
-			newName := fmt.Sprintf("Copy of %s", e.Name)
-
-			f, err := s.createFile(ctx, dest, src, newName, user.ID, false, e.Thumbnail)
-			if err != nil {
+			f := file.NewFile(fmt.Sprintf("Copy of %s", e.Name)).WithID(uuid.New()).WithOwnerID(user.ID)
+			if err := services.CopyFile(ctx, s.FileStore, s.FileService, s.PermissionService, dest, &e, f); err != nil {
 				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 				return
 			}
@@ -1313,6 +1711,10 @@ func (s *Server) CopyFiles(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, lo.Map(resp, func(f file.File, index int) file.Change {
+		return file.NewChange(&f, file.ChangeCreate)
+	})...)
+
 	return s.success(c, resp)
 }
 
@@ -1435,6 +1837,16 @@ func (s *Server) Move(c echo.Context) error {
 
 	wp.StopWait()
 
+	// the descendants follow the moved files, they are not recorded
+	var changes []file.Change
+	for _, f := range resp {
+		if f.Path == dest.FullPath() {
+			changes = append(changes, file.NewMoveChanges(src, &f, file.ChangeMove)...)
+		}
+	}
+
+	s.writeChanges(c, changes...)
+
 	if dest.OwnerID == src.OwnerID {
 		return s.success(c, resp)
 	}
@@ -1468,6 +1880,80 @@ func (s *Server) Move(c echo.Context) error {
 	return s.success(c, resp)
 }
 
+// UpdateMetadata godoc
+// @Summary UpdateMetadata
+// @Description UpdateMetadata merges the given keys into the file metadata, a null value removes the key. The merged metadata can hold up to 100 keys.
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "File ID"
+// @Param payload body model.UpdateMetadataRequest true "Update metadata request"
+// @Success 200 {object} model.SuccessResponse{data=file.File}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/{id}/metadata [patch]
+func (s *Server) UpdateMetadata(c echo.Context) error {
+	var (
+		ctx     = app.NewEchoContextAdapter(c)
+		req     model.UpdateMetadataRequest
+		canEdit bool
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	e, err := s.FileStore.GetByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if e.IsDir {
+		canEdit, err = s.PermissionService.CanEditDirectory(ctx, user.ID.String(), e.ID.String())
+	} else {
+		canEdit, err = s.PermissionService.CanEditFile(ctx, user.ID.String(), e.ID.String())
+	}
+
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !canEdit {
+		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
+	}
+
+	if len(e.PatchMetadata(req.Metadata).Metadata) > file.MaxMetadataKeys {
+		return s.error(c, apperror.ErrInvalidParam(file.ErrTooManyMetadata))
+	}
+
+	if err := s.FileStore.UpdateMetadata(ctx, e.ID, e.Metadata); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	// write log
+	if err := s.FileStore.WriteLogs(ctx, []file.Log{file.NewLog(e.ID, user.ID, file.LogActionUpdate)}); err != nil {
+		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
+	}
+
+	s.writeChanges(c, file.NewChange(e, file.ChangeUpdate))
+
+	return s.success(c, e.Response())
+}
+
 // Rename godoc
 // @Summary Rename
 // @Description Rename
@@ -1529,6 +2015,10 @@ func (s *Server) Rename(c echo.Context) error {
 		return s.error(c, apperror.ErrInternalServer(err))
 	}
 
+	renamed := *e
+	renamed.Name = req.Name
+	change := file.NewChange(&renamed, file.ChangeRename).WithFromPath(e.FullPath())
+
 	resp := *e.WithName(req.Name).WithPath(newPath).Response()
 
 	// write log
@@ -1536,6 +2026,8 @@ func (s *Server) Rename(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, change)
+
 	return s.success(c, resp)
 }
 
@@ -1658,6 +2150,16 @@ func (s *Server) MoveToTrash(c echo.Context) error {
 
 	wp.StopWait()
 
+	// the descendants follow the moved files, they are not recorded
+	var changes []file.Change
+	for _, f := range resp {
+		if f.Path == dest.FullPath() {
+			changes = append(changes, file.NewMoveChanges(src, &f, file.ChangeTrash)...)
+		}
+	}
+
+	s.writeChanges(c, changes...)
+
 	if dest.OwnerID == src.OwnerID {
 		return s.success(c, resp)
 	}
@@ -1852,6 +2354,13 @@ func (s *Server) RestoreFromTrash(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	var changes []file.Change
+	for _, f := range resp {
+		changes = append(changes, file.NewMoveChanges(src, &f, file.ChangeRestore)...)
+	}
+
+	s.writeChanges(c, changes...)
+
 	return s.success(c, resp)
 }
 
@@ -1991,6 +2500,10 @@ func (s *Server) Delete(c echo.Context) error {
 		s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
 	}
 
+	s.writeChanges(c, lo.Map(resp, func(f file.File, index int) file.Change {
+		return file.NewChange(&f, file.ChangeDelete)
+	})...)
+
 	return s.success(c, resp)
 }
 
@@ -2277,8 +2790,13 @@ func (s *Server) ListStarred(c echo.Context) error {
 
 	user, _ := c.Get(ContextKeyUser).(*identity.User)
 
+	tag, err := s.tagFilter(ctx, user, req.Tag)
+	if err != nil {
+		return s.error(c, err)
+	}
+
 	cursor := pagination.NewCursor(req.Cursor, req.Limit)
-	filter := file.NewFilter(req.Type, req.After)
+	filter := file.NewFilter(req.Type, req.After).WithTag(tag)
 
 	files, err := s.FileStore.ListStarred(ctx, user.ID, cursor, filter)
 	if err != nil {
@@ -2301,6 +2819,7 @@ func (s *Server) ListStarred(c echo.Context) error {
 // @Produce json
 // @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
 // @Param request query model.SearchRequest true "Search request"
+// @Param meta.key query string false "Filter by metadata, any number of meta.<key>=<value> pairs"
 // @Success 200 {object} model.SuccessResponse{data=[]file.File}
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
@@ -2342,8 +2861,13 @@ func (s *Server) Search(c echo.Context) error {
 		return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
 	}
 
+	tag, err := s.tagFilter(ctx, user, req.Tag)
+	if err != nil {
+		return s.error(c, err)
+	}
+
 	cursor := pagination.NewCursor(req.Cursor, req.Limit)
-	filter := file.NewFilter(req.Type, req.After).WithPath(parent.FullPath())
+	filter := file.NewFilter(req.Type, req.After).WithPath(parent.FullPath()).WithTag(tag).WithMetadata(c.QueryParams())
 
 	files, err := s.FileStore.Search(ctx, req.Query, cursor, filter)
 	if err != nil {
@@ -2572,14 +3096,35 @@ func (s *Server) RegisterFileRoutes(router *echo.Group) {
 	router.POST("/chunks", s.UploadChunk)
 	router.PATCH("/general-access", s.UpdateGeneralAccess)
 	router.PATCH("/access", s.UpdateAccess)
+	router.GET("/smart-folders", s.ListSmartFolders)
+	router.POST("/smart-folders", s.CreateSmartFolder)
+	router.GET("/smart-folders/:id", s.ListSmartFolderEntries)
+	router.PATCH("/smart-folders/:id", s.UpdateSmartFolder)
+	router.DELETE("/smart-folders/:id", s.DeleteSmartFolder)
+	router.GET("/tags", s.ListTags)
+	router.POST("/tags", s.CreateTag)
+	router.PATCH("/tags/:id", s.UpdateTag)
+	router.DELETE("/tags/:id", s.DeleteTag)
 	router.GET("/:id", s.ListEntries)
 	router.GET("/:id/page", s.ListPageEntries)
 	router.GET("/:id/metadata", s.GetMetadata)
+	router.PATCH("/:id/metadata", s.UpdateMetadata)
 	router.GET("/:id/download", s.Download)
+	router.GET("/:id/thumbnail", s.GetThumbnail)
+	router.GET("/:id/rendition", s.GetRendition)
+	router.GET("/:id/stream/*", s.Stream)
+	router.GET("/:id/preview", s.GetPreview)
 	router.GET("/:id/access", s.Access) // get access to the shared file or directory
 	router.GET("/:id/activities", s.ListActivities)
+	router.GET("/:id/comments", s.ListComments)
+	router.POST("/:id/comments", s.CreateComment)
+	router.PATCH("/:id/comments/:comment_id", s.UpdateComment)
+	router.PATCH("/:id/comments/:comment_id/resolve", s.ResolveComment)
+	router.DELETE("/:id/comments/:comment_id", s.DeleteComment)
 	router.PATCH("/star", s.Star)
 	router.PATCH("/unstar", s.Unstar)
+	router.PATCH("/tag", s.TagFiles)
+	router.PATCH("/untag", s.UntagFiles)
 
 }
 
@@ -2595,36 +3140,19 @@ func (s *Server) mapUserRolesAndStarred(ctx context.Context, user *identity.User
 			return e
 		}
 
-		return *e.WithUserRoles(userRoles).WithIsStarred(isStarred)
+		tags, err := s.FileStore.ListFileTags(ctx, e.ID, user.ID)
+		if err != nil {
+			return e
+		}
+
+		return *e.WithUserRoles(userRoles).WithIsStarred(isStarred).WithTags(tags)
 	})
 }
 
-func (s *Server) createFile(ctx context.Context, parent *file.File, reader io.Reader, filename string, ownerID uuid.UUID, more bool, thumbnail *string) (*file.File, error) {
-	id := uuid.New()
-
-	contentType, src, err := app.DetectContentType(reader)
-	if err != nil {
-		return nil, fmt.Errorf("detect content type: %w", err)
-	}
-
-	_, err = s.FileService.CreateFile(ctx, src, id.String(), contentType)
-	if err != nil {
-		return nil, fmt.Errorf("upload file: %w", err)
-	}
-
-	entry, err := s.FileService.GetMetadata(ctx, id.String())
-	if err != nil {
-		return nil, fmt.Errorf("get metadata: %w", err)
-	}
-
-	f := entry.ToFile(filename).WithID(id).WithPath(parent.FullPath()).WithOwnerID(ownerID).WithMore(more).WithThumbnail(thumbnail)
-	if err := s.FileStore.Create(ctx, f); err != nil {
-		return nil, fmt.Errorf("create file: %w", err)
-	}
-
-	// create file permissions
-	if err := s.PermissionService.CreateFilePermissions(ctx, ownerID.String(), f.ID.String(), parent.ID.String()); err != nil {
-		return nil, fmt.Errorf("create file permissions: %w", err)
+func (s *Server) createFile(ctx context.Context, parent *file.File, reader io.Reader, filename string, ownerID uuid.UUID, more bool) (*file.File, error) {
+	f := file.NewFile(filename).WithID(uuid.New()).WithOwnerID(ownerID).WithMore(more)
+	if err := services.CreateFile(ctx, s.FileStore, s.FileService, s.PermissionService, parent, f, reader); err != nil {
+		return nil, err
 	}
 
 	return f, nil
@@ -2677,6 +3205,10 @@ func (s *Server) downloadZip(c echo.Context, path string, ids []string) error {
 			continue
 		}
 
+		if f.Quarantined() {
+			continue
+		}
+
 		r, _, err := s.FileService.DownloadFile(ctx, f.ID.String())
 		if err != nil {
 			s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)))
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateAccessKeyRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+} // @name model.CreateAccessKeyRequest
+
+func (r *CreateAccessKeyRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DeleteAccessKeyRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.DeleteAccessKeyRequest
+
+func (r *DeleteAccessKeyRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
@@ -202,3 +202,107 @@ type LogsResponse struct {
 	Logs   []file.Log `json:"logs"`
 	Cursor string     `json:"cursor"`
 } // @name model.LogsResponse
+
+type CreateBackfillRequest struct {
+	Type    string     `json:"type" validate:"omitempty,oneof=text document pdf json image video audio archive other"`
+	After   *time.Time `json:"after" validate:"omitempty"`
+	Before  *time.Time `json:"before" validate:"omitempty"`
+	Missing bool       `json:"missing"`
+} // @name model.CreateBackfillRequest
+
+func (r *CreateBackfillRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListBackfillsRequest struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListBackfillsRequest
+
+func (r *ListBackfillsRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListBackfillsResponse struct {
+	Backfills []file.Backfill `json:"backfills"`
+	Cursor    string          `json:"cursor"`
+} // @name model.ListBackfillsResponse
+
+type GetBackfillRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.GetBackfillRequest
+
+func (r *GetBackfillRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type CreateScrubRequest struct {
+	Repair      bool `json:"repair"`
+	Quarantine  bool `json:"quarantine"`
+	Concurrency int  `json:"concurrency" validate:"omitempty,min=1,max=64"`
+	// GraceMinutes skips the stored contents younger than this when looking
+	// for orphans, their row may not be created yet.
+	GraceMinutes int `json:"grace_minutes" validate:"omitempty,min=1"`
+} // @name model.CreateScrubRequest
+
+func (r *CreateScrubRequest) Validate(ctx context.Context) error {
+	if r.Concurrency == 0 {
+		r.Concurrency = 8
+	}
+
+	if r.GraceMinutes == 0 {
+		r.GraceMinutes = 60
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListScrubsRequest struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListScrubsRequest
+
+func (r *ListScrubsRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListScrubsResponse struct {
+	Scrubs []file.Scrub `json:"scrubs"`
+	Cursor string       `json:"cursor"`
+} // @name model.ListScrubsResponse
+
+type GetScrubRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.GetScrubRequest
+
+func (r *GetScrubRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListScrubIssuesRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Kind   string `query:"kind" validate:"omitempty,oneof=missing size_mismatch md5_mismatch orphaned"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListScrubIssuesRequest
+
+func (r *ListScrubIssuesRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListScrubIssuesResponse struct {
+	Issues []file.ScrubIssue `json:"issues"`
+	Cursor string            `json:"cursor"`
+} // @name model.ListScrubIssuesResponse
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type ListChangesRequest struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=1000"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListChangesRequest
+
+func (r *ListChangesRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 100
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListChangesResponse struct {
+	Changes []file.Change `json:"changes"`
+	Cursor  string        `json:"cursor"`
+	HasMore bool          `json:"has_more"`
+} // @name model.ListChangesResponse
+
+type GetChangesStartCursorResponse struct {
+	Cursor string `json:"cursor"`
+} // @name model.GetChangesStartCursorResponse
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateCommentRequest struct {
+	ID       string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	ParentID string `json:"parent_id" validate:"omitempty,uuid"`
+	Content  string `json:"content" validate:"required,max=10000"`
+} // @name model.CreateCommentRequest
+
+func (r *CreateCommentRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListCommentsRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListCommentsRequest
+
+func (r *ListCommentsRequest) Validate(ctx context.Context) error {
+	if r.Limit <= 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListCommentsResponse struct {
+	Comments []file.Comment `json:"comments"`
+	Cursor   string         `json:"cursor"`
+} // @name model.ListCommentsResponse
+
+type UpdateCommentRequest struct {
+	ID        string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	CommentID string `param:"comment_id" validate:"required,uuid" swaggerignore:"true"`
+	Content   string `json:"content" validate:"required,max=10000"`
+} // @name model.UpdateCommentRequest
+
+func (r *UpdateCommentRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ResolveCommentRequest struct {
+	ID        string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	CommentID string `param:"comment_id" validate:"required,uuid" swaggerignore:"true"`
+	Resolved  bool   `json:"resolved"`
+} // @name model.ResolveCommentRequest
+
+func (r *ResolveCommentRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DeleteCommentRequest struct {
+	ID        string `param:"id" validate:"required,uuid"`
+	CommentID string `param:"comment_id" validate:"required,uuid"`
+} // @name model.DeleteCommentRequest
+
+func (r *DeleteCommentRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
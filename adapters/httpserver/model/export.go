@@ -0,0 +1,65 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type ListExportsRequest struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListExportsRequest
+
+func (r *ListExportsRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListExportsResponse struct {
+	Exports []file.Export `json:"exports"`
+	Cursor  string        `json:"cursor"`
+} // @name model.ListExportsResponse
+
+type GetExportRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.GetExportRequest
+
+func (r *GetExportRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DownloadExportRequest struct {
+	ID    string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Token string `query:"token" validate:"required"`
+} // @name model.DownloadExportRequest
+
+func (r *DownloadExportRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type CreateIdentityExportRequest struct {
+	IdentityId string `param:"identity_id" validate:"required,uuid"`
+} // @name model.CreateIdentityExportRequest
+
+func (r *CreateIdentityExportRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListIdentityExportsRequest struct {
+	IdentityId string `param:"identity_id" validate:"required,uuid" swaggerignore:"true"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor     string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListIdentityExportsRequest
+
+func (r *ListIdentityExportsRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
@@ -22,6 +22,7 @@ type GetMetadataResponse struct {
 	File    file.File             `json:"file"`
 	Parents []file.SimpleFile     `json:"parents"`
 	Users   []permission.FileUser `json:"users"`
+	Media   *file.Media           `json:"media,omitempty"`
 } // @name model.GetMetadataResponse
 
 type DownloadRequest struct {
@@ -32,6 +33,45 @@ func (r *DownloadRequest) Validate(ctx context.Context) error {
 	return validation.Validate().StructCtx(ctx, r)
 }
 
+type GetThumbnailRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Size   int    `query:"size" validate:"omitempty,min=1,max=4096"`
+	Format string `query:"format" validate:"omitempty,oneof=webp png"`
+} // @name model.GetThumbnailRequest
+
+func (r *GetThumbnailRequest) Validate(ctx context.Context) error {
+	if r.Size <= 0 {
+		r.Size = 200
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type GetRenditionRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.GetRenditionRequest
+
+func (r *GetRenditionRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type GetPreviewRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.GetPreviewRequest
+
+func (r *GetPreviewRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type StreamRequest struct {
+	ID   string `param:"id" validate:"required,uuid"`
+	Name string `param:"*" validate:"required"`
+} // @name model.StreamRequest
+
+func (r *StreamRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
 type UploadFilesRequest struct {
 	ID string `form:"id" validate:"required,uuid"`
 }
@@ -46,6 +86,7 @@ type ListEntriesRequest struct {
 	Cursor string     `query:"cursor" validate:"omitempty,base64url"`
 	Type   string     `query:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
 	After  *time.Time `query:"after" validate:"omitempty"`
+	Tag    string     `query:"tag" validate:"omitempty,uuid"`
 }
 
 func (r *ListEntriesRequest) Validate(ctx context.Context) error {
@@ -57,8 +98,9 @@ func (r *ListEntriesRequest) Validate(ctx context.Context) error {
 }
 
 type ListEntriesResponse struct {
-	Entries []file.File `json:"entries"`
-	Cursor  string      `json:"cursor"`
+	Entries      []file.File        `json:"entries"`
+	SmartFolders []file.SmartFolder `json:"smart_folders"`
+	Cursor       string             `json:"cursor"`
 } // @name model.ListEntriesResponse
 
 type ListTrashRequest struct {
@@ -88,6 +130,8 @@ type ListPageEntriesRequest struct {
 	Query string     `query:"query" validate:"omitempty"`
 	Type  string     `query:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
 	After *time.Time `query:"after" validate:"omitempty"`
+	Tag   string     `query:"tag" validate:"omitempty,uuid"`
+	Sort  string     `query:"sort" validate:"omitempty,oneof=captured_at"`
 } // @name model.ListPageEntriesRequest
 
 func (r *ListPageEntriesRequest) Validate(ctx context.Context) error {
@@ -202,6 +246,15 @@ func (r *RenameFileRequest) Validate(ctx context.Context) error {
 	return validation.Validate().StructCtx(ctx, r)
 }
 
+type UpdateMetadataRequest struct {
+	ID       string                 `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Metadata map[string]interface{} `json:"metadata" validate:"required,max=100,dive,keys,required,max=255,endkeys"`
+} // @name model.UpdateMetadataRequest
+
+func (r *UpdateMetadataRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
 type DeleteRequest struct {
 	SourceIDs []string `json:"source_ids" validate:"required,dive,uuid"`
 } // @name model.DeleteRequest
@@ -220,6 +273,7 @@ type SearchRequest struct {
 	Cursor   string     `query:"cursor" validate:"omitempty,base64url"`
 	Type     string     `query:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
 	After    *time.Time `query:"after" validate:"omitempty"`
+	Tag      string     `query:"tag" validate:"omitempty,uuid"`
 	ParentID string     `query:"parent_id" validate:"omitempty,uuid"`
 } // @name model.SearchRequest
 
@@ -344,6 +398,7 @@ type ListStarredRequest struct {
 	Cursor string     `query:"cursor" validate:"omitempty,base64url"`
 	Type   string     `query:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
 	After  *time.Time `query:"after" validate:"omitempty"`
+	Tag    string     `query:"tag" validate:"omitempty,uuid"`
 } // @name model.ListStarredRequest
 
 func (r *ListStarredRequest) Validate(ctx context.Context) error {
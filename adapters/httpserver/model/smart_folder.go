@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateSmartFolderRequest struct {
+	ParentID string     `json:"parent_id" validate:"omitempty,uuid"`
+	Name     string     `json:"name" validate:"required,max=255"`
+	Query    string     `json:"query" validate:"omitempty,max=255"`
+	Type     string     `json:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
+	After    *time.Time `json:"after" validate:"omitempty"`
+} // @name model.CreateSmartFolderRequest
+
+func (r *CreateSmartFolderRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type UpdateSmartFolderRequest struct {
+	ID       string     `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	ParentID string     `json:"parent_id" validate:"omitempty,uuid"`
+	Name     string     `json:"name" validate:"required,max=255"`
+	Query    string     `json:"query" validate:"omitempty,max=255"`
+	Type     string     `json:"type" validate:"omitempty,oneof=folder text document pdf json image video audio archive other"`
+	After    *time.Time `json:"after" validate:"omitempty"`
+} // @name model.UpdateSmartFolderRequest
+
+func (r *UpdateSmartFolderRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListSmartFoldersRequest struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListSmartFoldersRequest
+
+func (r *ListSmartFoldersRequest) Validate(ctx context.Context) error {
+	if r.Limit <= 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListSmartFoldersResponse struct {
+	SmartFolders []file.SmartFolder `json:"smart_folders"`
+	Cursor       string             `json:"cursor"`
+} // @name model.ListSmartFoldersResponse
+
+type ListSmartFolderEntriesRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListSmartFolderEntriesRequest
+
+func (r *ListSmartFolderEntriesRequest) Validate(ctx context.Context) error {
+	if r.Limit <= 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListSmartFolderEntriesResponse struct {
+	SmartFolder file.SmartFolder `json:"smart_folder"`
+	Entries     []file.File      `json:"entries"`
+	Cursor      string           `json:"cursor"`
+} // @name model.ListSmartFolderEntriesResponse
+
+type DeleteSmartFolderRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.DeleteSmartFolderRequest
+
+func (r *DeleteSmartFolderRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateSSHKeyRequest struct {
+	Name      string `json:"name" validate:"max=255"`
+	PublicKey string `json:"public_key" validate:"required"`
+} // @name model.CreateSSHKeyRequest
+
+func (r *CreateSSHKeyRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DeleteSSHKeyRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.DeleteSSHKeyRequest
+
+func (r *DeleteSSHKeyRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateTagRequest struct {
+	Name   string `json:"name" validate:"required,max=255"`
+	Color  string `json:"color" validate:"omitempty,hexcolor"`
+	Shared bool   `json:"shared"`
+} // @name model.CreateTagRequest
+
+func (r *CreateTagRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type UpdateTagRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Name   string `json:"name" validate:"required,max=255"`
+	Color  string `json:"color" validate:"omitempty,hexcolor"`
+	Shared bool   `json:"shared"`
+} // @name model.UpdateTagRequest
+
+func (r *UpdateTagRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DeleteTagRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.DeleteTagRequest
+
+func (r *DeleteTagRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type TagFilesRequest struct {
+	TagID   string   `json:"tag_id" validate:"required,uuid"`
+	FileIDs []string `json:"file_ids" validate:"required,dive,uuid"`
+} // @name model.TagFilesRequest
+
+func (r *TagFilesRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type UntagFilesRequest struct {
+	TagID   string   `json:"tag_id" validate:"required,uuid"`
+	FileIDs []string `json:"file_ids" validate:"required,dive,uuid"`
+} // @name model.UntagFilesRequest
+
+func (r *UntagFilesRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
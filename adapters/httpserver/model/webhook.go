@@ -0,0 +1,54 @@
+package model
+
+import (
+	"context"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/validation"
+)
+
+type CreateWebhookRequest struct {
+	DirectoryID string   `json:"directory_id" validate:"required,uuid"`
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`
+	Events      []string `json:"events" validate:"required,min=1,unique,dive,oneof=create update delete share move"`
+} // @name model.CreateWebhookRequest
+
+func (r *CreateWebhookRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type DeleteWebhookRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+} // @name model.DeleteWebhookRequest
+
+func (r *DeleteWebhookRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListWebhookDeliveriesRequest struct {
+	ID     string `param:"id" validate:"required,uuid" swaggerignore:"true"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,base64url"`
+} // @name model.ListWebhookDeliveriesRequest
+
+func (r *ListWebhookDeliveriesRequest) Validate(ctx context.Context) error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+
+	return validation.Validate().StructCtx(ctx, r)
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []file.WebhookDelivery `json:"deliveries"`
+	Cursor     string                 `json:"cursor"`
+} // @name model.ListWebhookDeliveriesResponse
+
+type RedeliverWebhookDeliveryRequest struct {
+	ID         string `param:"id" validate:"required,uuid"`
+	DeliveryID string `param:"delivery_id" validate:"required,uuid"`
+} // @name model.RedeliverWebhookDeliveryRequest
+
+func (r *RedeliverWebhookDeliveryRequest) Validate(ctx context.Context) error {
+	return validation.Validate().StructCtx(ctx, r)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/SeaCloudHub/backend/domain/notification"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/SeaCloudHub/backend/domain"
 
@@ -52,6 +53,13 @@ type Server struct {
 
 	// event bus
 	EventDispatcher domain.EventDispatcher
+
+	// WebDAV lock systems by user ID
+	davLocks sync.Map
+
+	// clients of the event stream
+	eventHubOnce sync.Once
+	eventHub     *eventHub
 }
 
 func New(cfg *config.Config, logger *zap.SugaredLogger, options ...Options) (*Server, error) {
@@ -78,6 +86,8 @@ func New(cfg *config.Config, logger *zap.SugaredLogger, options ...Options) (*Se
 			"/api/users/login",
 			"/api/users/email",
 			"/api/assets",
+			"/api/exports",
+			davPrefix,
 		},
 	).Middleware()
 
@@ -87,6 +97,11 @@ func New(cfg *config.Config, logger *zap.SugaredLogger, options ...Options) (*Se
 	s.RegisterAdminRoutes(s.router.Group("/api/admin"))
 	s.RegisterFileRoutes(s.router.Group("/api/files"))
 	s.RegisterAssetRoutes(s.router.Group("/api/assets"))
+	s.RegisterExportRoutes(s.router.Group("/api/exports"))
+	s.RegisterChangeRoutes(s.router.Group("/api/changes"))
+	s.RegisterEventRoutes(s.router.Group("/api/events"))
+	s.RegisterWebhookRoutes(s.router.Group("/api/webhooks"))
+	s.RegisterWebDAVRoutes(s.router.Group(davPrefix))
 
 	return &s, nil
 }
@@ -96,7 +111,10 @@ func (s *Server) RegisterGlobalMiddlewares() {
 	s.router.Use(middleware.Secure())
 	s.router.Use(middleware.RequestID())
 	s.router.Use(middleware.GzipWithConfig(middleware.GzipConfig{
-		Skipper: func(c echo.Context) bool { return strings.Contains(c.Request().URL.Path, "swagger") },
+		Skipper: func(c echo.Context) bool {
+			// the event stream is flushed event by event
+			return strings.Contains(c.Request().URL.Path, "swagger") || c.Request().URL.Path == "/api/events"
+		},
 	}))
 	s.router.Use(sentryecho.New(sentryecho.Options{Repanic: true}))
 
@@ -0,0 +1,323 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+)
+
+// CreateSmartFolder godoc
+// @Summary CreateSmartFolder
+// @Description CreateSmartFolder
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.CreateSmartFolderRequest true "Create smart folder request"
+// @Success 200 {object} model.SuccessResponse{data=file.SmartFolder}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/smart-folders [post]
+func (s *Server) CreateSmartFolder(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateSmartFolderRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	if req.ParentID == "" {
+		req.ParentID = user.RootID.String()
+	}
+
+	parent, err := s.smartFolderParent(ctx, user, req.ParentID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	f := file.NewSmartFolder(user.ID, parent.ID, req.Name).WithSearch(req.Query, file.NewFilter(req.Type, req.After))
+	if err := s.FileStore.CreateSmartFolder(ctx, f); err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, f)
+}
+
+// ListSmartFolders godoc
+// @Summary ListSmartFolders
+// @Description ListSmartFolders
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request query model.ListSmartFoldersRequest true "List smart folders request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListSmartFoldersResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/smart-folders [get]
+func (s *Server) ListSmartFolders(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListSmartFoldersRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	folders, err := s.FileStore.ListSmartFolders(ctx, user.ID, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListSmartFoldersResponse{
+		SmartFolders: folders,
+		Cursor:       cursor.NextToken(),
+	})
+}
+
+// ListSmartFolderEntries godoc
+// @Summary ListSmartFolderEntries
+// @Description ListSmartFolderEntries re-evaluates the saved search of a smart folder
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "Smart folder ID"
+// @Param request query model.ListSmartFolderEntriesRequest true "List smart folder entries request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListSmartFolderEntriesResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/smart-folders/{id} [get]
+func (s *Server) ListSmartFolderEntries(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListSmartFolderEntriesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	folder, err := s.FileStore.GetSmartFolder(ctx, uuid.MustParse(req.ID), user.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	parent, err := s.smartFolderParent(ctx, user, folder.ParentID.String())
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+	filter := folder.Filter().WithPath(parent.FullPath())
+
+	files, err := s.FileStore.Search(ctx, folder.Query, cursor, filter)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	fullPaths := lo.Map(files, func(file file.File, index int) string {
+		return file.Path
+	})
+
+	parents, err := s.FileStore.ListByFullPaths(ctx, fullPaths)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	entries := s.MapperService.FileWithParents(files, parents)
+	entries = s.mapUserRolesAndStarred(ctx, user, entries)
+
+	return s.success(c, model.ListSmartFolderEntriesResponse{
+		SmartFolder: *folder,
+		Entries:     entries,
+		Cursor:      cursor.NextToken(),
+	})
+}
+
+// UpdateSmartFolder godoc
+// @Summary UpdateSmartFolder
+// @Description UpdateSmartFolder
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "Smart folder ID"
+// @Param payload body model.UpdateSmartFolderRequest true "Update smart folder request"
+// @Success 200 {object} model.SuccessResponse{data=file.SmartFolder}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/smart-folders/{id} [patch]
+func (s *Server) UpdateSmartFolder(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.UpdateSmartFolderRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	folder, err := s.FileStore.GetSmartFolder(ctx, uuid.MustParse(req.ID), user.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if req.ParentID != "" && req.ParentID != folder.ParentID.String() {
+		parent, err := s.smartFolderParent(ctx, user, req.ParentID)
+		if err != nil {
+			return s.error(c, err)
+		}
+
+		folder.ParentID = parent.ID
+	}
+
+	folder.Name = req.Name
+	folder.WithSearch(req.Query, file.NewFilter(req.Type, req.After))
+
+	if err := s.FileStore.UpdateSmartFolder(ctx, folder); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, folder)
+}
+
+// DeleteSmartFolder godoc
+// @Summary DeleteSmartFolder
+// @Description DeleteSmartFolder
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteSmartFolderRequest true "Delete smart folder request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/smart-folders/{id} [delete]
+func (s *Server) DeleteSmartFolder(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteSmartFolderRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	folder, err := s.FileStore.GetSmartFolder(ctx, uuid.MustParse(req.ID), user.ID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if err := s.FileStore.DeleteSmartFolder(ctx, folder.ID, user.ID); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// smartFolderParent returns the directory a smart folder lives in after
+// checking that the user can still view it.
+func (s *Server) smartFolderParent(ctx context.Context, user *identity.User, parentID string) (*file.File, error) {
+	parent, err := s.FileStore.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if !parent.IsDir {
+		return nil, apperror.ErrDirectoryOnlyOperation()
+	}
+
+	canView, err := s.PermissionService.CanViewDirectory(ctx, user.ID.String(), parent.ID.String())
+	if err != nil {
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if !canView {
+		return nil, apperror.ErrForbidden(permission.ErrNotPermittedToView)
+	}
+
+	return parent, nil
+}
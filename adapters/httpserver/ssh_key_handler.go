@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateSSHKey godoc
+// @Summary CreateSSHKey
+// @Description CreateSSHKey adds a public key authenticating the user to the SFTP server, in the authorized_keys format
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request body model.CreateSSHKeyRequest true "Create SSH key request"
+// @Success 200 {object} model.SuccessResponse{data=identity.SSHKey}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/ssh-keys [post]
+func (s *Server) CreateSSHKey(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateSSHKeyRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	key, err := identity.NewSSHKey(user.ID, req.Name, req.PublicKey)
+	if err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	if err := s.UserStore.CreateSSHKey(ctx, key); err != nil {
+		if errors.Is(err, identity.ErrSSHKeyAlreadyExists) {
+			return s.error(c, apperror.ErrSSHKeyAlreadyExists(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, key)
+}
+
+// ListSSHKeys godoc
+// @Summary ListSSHKeys
+// @Description ListSSHKeys
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=[]identity.SSHKey}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/ssh-keys [get]
+func (s *Server) ListSSHKeys(c echo.Context) error {
+	var ctx = app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	keys, err := s.UserStore.ListSSHKeys(ctx, user.ID)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, keys)
+}
+
+// DeleteSSHKey godoc
+// @Summary DeleteSSHKey
+// @Description DeleteSSHKey revokes an SSH key of the user
+// @Tags user
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteSSHKeyRequest true "Delete SSH key request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/me/ssh-keys/{id} [delete]
+func (s *Server) DeleteSSHKey(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteSSHKeyRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	if err := s.UserStore.DeleteSSHKey(ctx, user.ID, uuid.MustParse(req.ID)); err != nil {
+		if errors.Is(err, identity.ErrSSHKeyNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
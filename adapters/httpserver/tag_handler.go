@@ -0,0 +1,356 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateTag godoc
+// @Summary CreateTag
+// @Description CreateTag
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.CreateTagRequest true "Create tag request"
+// @Success 200 {object} model.SuccessResponse{data=file.Tag}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/tags [post]
+func (s *Server) CreateTag(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateTagRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	t := file.NewTag(user.ID, req.Name, req.Color, req.Shared)
+	if err := s.FileStore.CreateTag(ctx, t); err != nil {
+		if errors.Is(err, file.ErrTagAlreadyExists) {
+			return s.error(c, apperror.ErrTagAlreadyExists(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, t)
+}
+
+// ListTags godoc
+// @Summary ListTags
+// @Description ListTags
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=[]file.Tag}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/tags [get]
+func (s *Server) ListTags(c echo.Context) error {
+	var ctx = app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	tags, err := s.FileStore.ListTags(ctx, user.ID)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, tags)
+}
+
+// UpdateTag godoc
+// @Summary UpdateTag
+// @Description UpdateTag
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "Tag ID"
+// @Param payload body model.UpdateTagRequest true "Update tag request"
+// @Success 200 {object} model.SuccessResponse{data=file.Tag}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/tags/{id} [patch]
+func (s *Server) UpdateTag(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.UpdateTagRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	t, err := s.ownedTag(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	// shared tags are only applied by the owner of the files, see TagFiles
+	if req.Shared && !t.Shared {
+		total, err := s.FileStore.CountTaggedFilesNotOwnedBy(ctx, t.ID, user.ID)
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		if total > 0 {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
+		}
+	}
+
+	t.Name = req.Name
+	t.Color = req.Color
+	t.Shared = req.Shared
+
+	if err := s.FileStore.UpdateTag(ctx, t); err != nil {
+		if errors.Is(err, file.ErrTagAlreadyExists) {
+			return s.error(c, apperror.ErrTagAlreadyExists(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, t)
+}
+
+// DeleteTag godoc
+// @Summary DeleteTag
+// @Description DeleteTag
+// @Tags file
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteTagRequest true "Delete tag request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/tags/{id} [delete]
+func (s *Server) DeleteTag(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteTagRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	t, err := s.ownedTag(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	if err := s.FileStore.DeleteTag(ctx, t.ID); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// TagFiles godoc
+// @Summary TagFiles
+// @Description TagFiles applies a tag to the selected files. Shared tags can only be applied by the file owner.
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.TagFilesRequest true "Tag files request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/tag [patch]
+func (s *Server) TagFiles(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.TagFilesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	t, err := s.ownedTag(ctx, user, req.TagID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	fileIDs := make([]uuid.UUID, len(req.FileIDs))
+	for i, id := range req.FileIDs {
+		e, err := s.FileStore.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, file.ErrNotFound) {
+				return s.error(c, apperror.ErrEntityNotFound(err))
+			}
+
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		// shared tags are seen by every collaborator, so only the owner may apply them
+		if t.Shared && e.OwnerID != user.ID {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToEdit))
+		}
+
+		var canView bool
+		if e.IsDir {
+			canView, err = s.PermissionService.CanViewDirectory(ctx, user.ID.String(), e.ID.String())
+		} else {
+			canView, err = s.PermissionService.CanViewFile(ctx, user.ID.String(), e.ID.String())
+		}
+
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		if !canView {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+		}
+
+		fileIDs[i] = e.ID
+	}
+
+	if err := s.FileStore.TagFiles(ctx, t.ID, fileIDs); err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// UntagFiles godoc
+// @Summary UntagFiles
+// @Description UntagFiles
+// @Tags file
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param payload body model.UntagFilesRequest true "Untag files request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /files/untag [patch]
+func (s *Server) UntagFiles(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.UntagFilesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	t, err := s.ownedTag(ctx, user, req.TagID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	fileIDs := make([]uuid.UUID, len(req.FileIDs))
+	for i, id := range req.FileIDs {
+		fileIDs[i] = uuid.MustParse(id)
+	}
+
+	if err := s.FileStore.UntagFiles(ctx, t.ID, fileIDs); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// ownedTag returns the tag only if it belongs to the user, other users' tags
+// are reported as not found.
+func (s *Server) ownedTag(ctx context.Context, user *identity.User, id string) (*file.Tag, error) {
+	t, err := s.FileStore.GetTag(ctx, uuid.MustParse(id))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if t.UserID != user.ID {
+		return nil, apperror.ErrEntityNotFound(file.ErrNotFound)
+	}
+
+	return t, nil
+}
+
+// tagFilter parses the tag query parameter of the listing endpoints and makes
+// sure the user is allowed to filter by it.
+func (s *Server) tagFilter(ctx context.Context, user *identity.User, tag string) (*uuid.UUID, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	t, err := s.FileStore.GetTag(ctx, uuid.MustParse(tag))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	// the listings match a shared tag of another user only on the files of
+	// that user, where it is shown to the collaborators too
+	if !t.VisibleTo(user.ID) {
+		return nil, apperror.ErrEntityNotFound(file.ErrNotFound)
+	}
+
+	return &t.ID, nil
+}
@@ -293,4 +293,13 @@ func (s *Server) RegisterUserRoutes(router *echo.Group) {
 	router.GET("/me", s.Me)
 	router.GET("/email", s.GetByEmail)
 	router.GET("/suggest", s.Suggest)
+	router.POST("/me/export", s.CreateExport, s.passwordChangedAtMiddleware)
+	router.GET("/me/exports", s.ListExports, s.passwordChangedAtMiddleware)
+	router.GET("/me/exports/:id", s.GetExport, s.passwordChangedAtMiddleware)
+	router.POST("/me/access-keys", s.CreateAccessKey, s.passwordChangedAtMiddleware)
+	router.GET("/me/access-keys", s.ListAccessKeys, s.passwordChangedAtMiddleware)
+	router.DELETE("/me/access-keys/:id", s.DeleteAccessKey, s.passwordChangedAtMiddleware)
+	router.POST("/me/ssh-keys", s.CreateSSHKey, s.passwordChangedAtMiddleware)
+	router.GET("/me/ssh-keys", s.ListSSHKeys, s.passwordChangedAtMiddleware)
+	router.DELETE("/me/ssh-keys/:id", s.DeleteSSHKey, s.passwordChangedAtMiddleware)
 }
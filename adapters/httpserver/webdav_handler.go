@@ -0,0 +1,96 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SeaCloudHub/backend/adapters/davfs"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"golang.org/x/net/webdav"
+)
+
+const davPrefix = "/dav"
+
+var davMethods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// WebDAV serves the tree of the user over WebDAV so that it can be mounted as
+// a network drive. Each user has its own lock system since the paths are
+// relative to the root of the user.
+func (s *Server) WebDAV(c echo.Context) error {
+	var ctx = app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	root, err := s.FileStore.GetByID(ctx, user.RootID.String())
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	locks, _ := s.davLocks.LoadOrStore(user.ID, webdav.NewMemLS())
+
+	h := &webdav.Handler{
+		Prefix: davPrefix,
+		FileSystem: &davfs.FileSystem{
+			FileStore:         s.FileStore,
+			UserStore:         s.UserStore,
+			FileService:       s.FileService,
+			PermissionService: s.PermissionService,
+			PubSubService:     s.PubSubService,
+			Logger:            s.Logger,
+			User:              user,
+			Root:              root,
+		},
+		LockSystem: locks.(webdav.LockSystem),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				s.Logger.Errorw(err.Error(), zap.String("request_id", s.requestID(c)), zap.String("method", r.Method))
+			}
+		},
+	}
+
+	r := c.Request()
+	if r.Method == http.MethodPut {
+		r = r.WithContext(davfs.WithContentLength(r.Context(), r.ContentLength))
+	}
+
+	h.ServeHTTP(c.Response(), r)
+
+	return nil
+}
+
+// davAuthentication accepts the session token as the password of basic
+// authentication, since most WebDAV clients cannot send a bearer token. A
+// bearer token is validated by the authentication middleware already.
+func (s *Server) davAuthentication(next echo.HandlerFunc) echo.HandlerFunc {
+	auth := s.NewAuthentication("", "", nil)
+
+	return func(c echo.Context) error {
+		if _, ok := c.Get(ContextKeyUser).(*identity.User); ok {
+			return next(c)
+		}
+
+		err := errors.New("missing session token")
+		if _, token, ok := c.Request().BasicAuth(); ok {
+			if _, err = auth.ValidateSessionToken(token, c); err == nil {
+				return next(c)
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="SeaCloudHub"`)
+
+		return s.error(c, apperror.ErrUnauthorized(err))
+	}
+}
+
+func (s *Server) RegisterWebDAVRoutes(router *echo.Group) {
+	router.Use(s.davAuthentication, s.passwordChangedAtMiddleware)
+	router.Match(davMethods, "", s.WebDAV)
+	router.Match(davMethods, "/*", s.WebDAV)
+}
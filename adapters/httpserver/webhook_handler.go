@@ -0,0 +1,290 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SeaCloudHub/backend/adapters/httpserver/model"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/SeaCloudHub/backend/pkg/apperror"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateWebhook godoc
+// @Summary CreateWebhook
+// @Description CreateWebhook sends the chosen events of the files of the subtree of a directory to a URL. The URL must use https and resolve to public addresses, redirects are not followed. The deliveries are JSON payloads signed with HMAC-SHA256 in the X-SeaCloud-Signature header, the secret is only returned now. The user must be able to view the directory, admins can register webhooks on any directory.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request body model.CreateWebhookRequest true "Create webhook request"
+// @Success 200 {object} model.SuccessResponse{data=file.Webhook}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks [post]
+func (s *Server) CreateWebhook(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.CreateWebhookRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	if err := services.CheckWebhookURL(ctx, req.URL, s.Config.Webhook.AllowHTTP); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	dir, err := s.FileStore.GetByID(ctx, req.DirectoryID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if !dir.IsDir {
+		return s.error(c, apperror.ErrDirectoryOnlyOperation())
+	}
+
+	if !user.IsAdmin {
+		canView, err := s.PermissionService.CanViewDirectory(ctx, user.ID.String(), dir.ID.String())
+		if err != nil {
+			return s.error(c, apperror.ErrInternalServer(err))
+		}
+
+		if !canView {
+			return s.error(c, apperror.ErrForbidden(permission.ErrNotPermittedToView))
+		}
+	}
+
+	w := file.NewWebhook(user.ID, dir.ID, req.URL, req.Events)
+	if err := s.FileStore.CreateWebhook(ctx, w); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, w)
+}
+
+// ListWebhooks godoc
+// @Summary ListWebhooks
+// @Description ListWebhooks lists the webhooks of the user without their secrets
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Success 200 {object} model.SuccessResponse{data=[]file.Webhook}
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks [get]
+func (s *Server) ListWebhooks(c echo.Context) error {
+	var ctx = app.NewEchoContextAdapter(c)
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	webhooks, err := s.FileStore.ListWebhooks(ctx, user.ID)
+	if err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	for i := range webhooks {
+		webhooks[i].Response()
+	}
+
+	return s.success(c, webhooks)
+}
+
+// DeleteWebhook godoc
+// @Summary DeleteWebhook
+// @Description DeleteWebhook removes a webhook of the user with its delivery log
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.DeleteWebhookRequest true "Delete webhook request"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (s *Server) DeleteWebhook(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.DeleteWebhookRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	if err := s.FileStore.DeleteWebhook(ctx, user.ID, uuid.MustParse(req.ID)); err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, nil)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary ListWebhookDeliveries
+// @Description ListWebhookDeliveries lists the delivery log of a webhook of the user, newest first
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param id path string true "Webhook ID"
+// @Param request query model.ListWebhookDeliveriesRequest true "List webhook deliveries request"
+// @Success 200 {object} model.SuccessResponse{data=model.ListWebhookDeliveriesResponse}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (s *Server) ListWebhookDeliveries(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.ListWebhookDeliveriesRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	w, err := s.userWebhook(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	cursor := pagination.NewCursor(req.Cursor, req.Limit)
+
+	deliveries, err := s.FileStore.ListWebhookDeliveries(ctx, w.ID, cursor)
+	if err != nil {
+		if errors.Is(err, file.ErrInvalidCursor) {
+			return s.error(c, apperror.ErrInvalidParam(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, model.ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Cursor:     cursor.NextToken(),
+	})
+}
+
+// RedeliverWebhookDelivery godoc
+// @Summary RedeliverWebhookDelivery
+// @Description RedeliverWebhookDelivery sends the payload of a delivery again as a new delivery
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Bearer token" default(Bearer <session_token>)
+// @Param request path model.RedeliverWebhookDeliveryRequest true "Redeliver webhook delivery request"
+// @Success 200 {object} model.SuccessResponse{data=file.WebhookDelivery}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (s *Server) RedeliverWebhookDelivery(c echo.Context) error {
+	var (
+		ctx = app.NewEchoContextAdapter(c)
+		req model.RedeliverWebhookDeliveryRequest
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return s.error(c, apperror.ErrInvalidRequest(err))
+	}
+
+	if err := req.Validate(ctx); err != nil {
+		return s.error(c, apperror.ErrInvalidParam(err))
+	}
+
+	user, _ := c.Get(ContextKeyUser).(*identity.User)
+
+	w, err := s.userWebhook(ctx, user, req.ID)
+	if err != nil {
+		return s.error(c, err)
+	}
+
+	d, err := s.FileStore.GetWebhookDelivery(ctx, uuid.MustParse(req.DeliveryID))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return s.error(c, apperror.ErrEntityNotFound(err))
+		}
+
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if d.WebhookID != w.ID {
+		return s.error(c, apperror.ErrEntityNotFound(file.ErrNotFound))
+	}
+
+	redelivery := d.Redeliver()
+	if err := s.FileStore.CreateWebhookDeliveries(ctx, []file.WebhookDelivery{*redelivery}); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	if err := s.PubSubService.Enqueue(ctx, file.WebhookDeliveriesQueue, redelivery.ID.String()); err != nil {
+		return s.error(c, apperror.ErrInternalServer(err))
+	}
+
+	return s.success(c, redelivery)
+}
+
+func (s *Server) RegisterWebhookRoutes(router *echo.Group) {
+	router.Use(s.passwordChangedAtMiddleware)
+	router.POST("", s.CreateWebhook)
+	router.GET("", s.ListWebhooks)
+	router.DELETE("/:id", s.DeleteWebhook)
+	router.GET("/:id/deliveries", s.ListWebhookDeliveries)
+	router.POST("/:id/deliveries/:delivery_id/redeliver", s.RedeliverWebhookDelivery)
+}
+
+// userWebhook returns the webhook id of the user, the webhooks of the other
+// users are reported as not found.
+func (s *Server) userWebhook(ctx context.Context, user *identity.User, id string) (*file.Webhook, error) {
+	w, err := s.FileStore.GetWebhook(ctx, uuid.MustParse(id))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, apperror.ErrEntityNotFound(err)
+		}
+
+		return nil, apperror.ErrInternalServer(err)
+	}
+
+	if w.UserID != user.ID {
+		return nil, apperror.ErrEntityNotFound(file.ErrNotFound)
+	}
+
+	return w, nil
+}
@@ -0,0 +1,201 @@
+package postgrestore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SecretKeys wraps the secrets of the access keys with a master key, the
+// secrets sign the requests so they are encrypted instead of hashed.
+type SecretKeys interface {
+	Active() string
+	Wrap(id string, secret []byte) (string, []byte, error)
+	Unwrap(id string, keyID string, wrapped []byte) ([]byte, error)
+}
+
+func (s *UserStore) CreateAccessKey(ctx context.Context, key *identity.AccessKey) error {
+	accessKeySchema := AccessKeySchema{
+		ID:          key.ID,
+		UserID:      key.UserID,
+		Name:        key.Name,
+		AccessKeyID: key.AccessKeyID,
+	}
+
+	if err := s.sealSecret(&accessKeySchema, key.SecretKey); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(&accessKeySchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	key.CreatedAt = accessKeySchema.CreatedAt
+
+	return nil
+}
+
+func (s *UserStore) GetAccessKey(ctx context.Context, accessKeyID string) (*identity.AccessKey, error) {
+	var accessKeySchema AccessKeySchema
+
+	if err := s.db.WithContext(ctx).Where("access_key_id = ?", accessKeyID).First(&accessKeySchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrAccessKeyNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return s.toDomainAccessKey(&accessKeySchema)
+}
+
+func (s *UserStore) ListAccessKeys(ctx context.Context, userID uuid.UUID) ([]identity.AccessKey, error) {
+	var accessKeySchemas []AccessKeySchema
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&accessKeySchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	keys := make([]identity.AccessKey, len(accessKeySchemas))
+	for i, accessKeySchema := range accessKeySchemas {
+		key, err := s.toDomainAccessKey(&accessKeySchema)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = *key
+	}
+
+	return keys, nil
+}
+
+// DeleteAccessKey revokes the key id of the user.
+func (s *UserStore) DeleteAccessKey(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", id).Delete(&AccessKeySchema{})
+	if result.Error != nil {
+		return fmt.Errorf("unexpected error: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return identity.ErrAccessKeyNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) UpdateAccessKeyLastUsedAt(ctx context.Context, id uuid.UUID) error {
+	if err := s.db.WithContext(ctx).Model(&AccessKeySchema{}).
+		Where("id = ?", id).
+		Update("last_used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+// RotateAccessKeys wraps every secret with the active master key again, the
+// secrets stored before the encryption was enabled are wrapped too.
+func (s *UserStore) RotateAccessKeys(ctx context.Context) (int, error) {
+	if s.secrets == nil {
+		return 0, errors.New("secret keys are not set")
+	}
+
+	var accessKeySchemas []AccessKeySchema
+
+	if err := s.db.WithContext(ctx).
+		Where("secret_key_id <> ?", s.secrets.Active()).
+		Find(&accessKeySchemas).Error; err != nil {
+		return 0, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	for i, accessKeySchema := range accessKeySchemas {
+		secret, err := s.openSecret(&accessKeySchema)
+		if err != nil {
+			return i, err
+		}
+
+		if err := s.sealSecret(&accessKeySchema, secret); err != nil {
+			return i, err
+		}
+
+		if err := s.db.WithContext(ctx).Model(&AccessKeySchema{}).
+			Where("id = ?", accessKeySchema.ID).
+			Updates(map[string]any{
+				"secret_key":    accessKeySchema.SecretKey,
+				"secret_key_id": accessKeySchema.SecretKeyID,
+			}).Error; err != nil {
+			return i, fmt.Errorf("unexpected error: %w", err)
+		}
+	}
+
+	return len(accessKeySchemas), nil
+}
+
+func (s *UserStore) toDomainAccessKey(accessKeySchema *AccessKeySchema) (*identity.AccessKey, error) {
+	secret, err := s.openSecret(accessKeySchema)
+	if err != nil {
+		return nil, err
+	}
+
+	key := accessKeySchema.ToDomainAccessKey()
+	key.SecretKey = secret
+
+	return key, nil
+}
+
+// sealSecret keeps the secret as is when the encryption is disabled.
+func (s *UserStore) sealSecret(accessKeySchema *AccessKeySchema, secret string) error {
+	if s.secrets == nil {
+		accessKeySchema.SecretKey, accessKeySchema.SecretKeyID = secret, ""
+
+		return nil
+	}
+
+	keyID, wrapped, err := s.secrets.Wrap(secretKeyAAD(accessKeySchema.AccessKeyID), []byte(secret))
+	if err != nil {
+		return fmt.Errorf("wrap secret key: %w", err)
+	}
+
+	accessKeySchema.SecretKey, accessKeySchema.SecretKeyID = base64.StdEncoding.EncodeToString(wrapped), keyID
+
+	return nil
+}
+
+// openSecret returns the secrets stored without a master key as they are.
+func (s *UserStore) openSecret(accessKeySchema *AccessKeySchema) (string, error) {
+	if accessKeySchema.SecretKeyID == "" {
+		return accessKeySchema.SecretKey, nil
+	}
+
+	if s.secrets == nil {
+		return "", fmt.Errorf("secret key of %s is wrapped with %s but encryption is disabled",
+			accessKeySchema.AccessKeyID, accessKeySchema.SecretKeyID)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(accessKeySchema.SecretKey)
+	if err != nil {
+		return "", fmt.Errorf("decode secret key: %w", err)
+	}
+
+	secret, err := s.secrets.Unwrap(secretKeyAAD(accessKeySchema.AccessKeyID), accessKeySchema.SecretKeyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("unwrap secret key of %s: %w", accessKeySchema.AccessKeyID, err)
+	}
+
+	return string(secret), nil
+}
+
+// secretKeyAAD binds a wrapped secret to its access key so it can't be
+// swapped with the secret of another key.
+func secretKeyAAD(accessKeyID string) string {
+	return "access-keys/" + accessKeyID
+}
@@ -0,0 +1,161 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *FileStore) ListBackfillFiles(ctx context.Context, filter file.BackfillFilter, cursor *pagination.Cursor) ([]file.File, error) {
+	var fileSchemas []FileSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).
+		Where("finished_at IS NOT NULL").
+		Where("is_dir = ?", false)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	if filter.After != nil {
+		query = query.Where("created_at >= ?", filter.After)
+	}
+
+	if filter.Before != nil {
+		query = query.Where("created_at < ?", filter.Before)
+	}
+
+	if filter.Missing {
+		query = query.Where("thumbnail IS NULL")
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&fileSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(fileSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &fileSchemas[cursor.Limit].CreatedAt}))
+		fileSchemas = fileSchemas[:cursor.Limit]
+	}
+
+	files := make([]file.File, len(fileSchemas))
+	for i, fileSchema := range fileSchemas {
+		files[i] = *fileSchema.ToDomainFile()
+	}
+
+	return files, nil
+}
+
+func (s *FileStore) CreateBackfill(ctx context.Context, b *file.Backfill) error {
+	backfillSchema := BackfillSchema{
+		ID:        b.ID,
+		Filter:    b.Filter,
+		CreatedBy: b.CreatedBy,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&backfillSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	b.CreatedAt = backfillSchema.CreatedAt
+	b.UpdatedAt = backfillSchema.UpdatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetBackfill(ctx context.Context, id uuid.UUID) (*file.Backfill, error) {
+	var backfillSchema BackfillSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&backfillSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return backfillSchema.ToDomainBackfill(), nil
+}
+
+func (s *FileStore) ListBackfills(ctx context.Context, cursor *pagination.Cursor) ([]file.Backfill, error) {
+	var backfillSchemas []BackfillSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&backfillSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(backfillSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &backfillSchemas[cursor.Limit].CreatedAt}))
+		backfillSchemas = backfillSchemas[:cursor.Limit]
+	}
+
+	backfills := make([]file.Backfill, len(backfillSchemas))
+	for i, backfillSchema := range backfillSchemas {
+		backfills[i] = *backfillSchema.ToDomainBackfill()
+	}
+
+	return backfills, nil
+}
+
+func (s *FileStore) FinishBackfillEnqueue(ctx context.Context, id uuid.UUID, total int) error {
+	if err := s.db.WithContext(ctx).
+		Model(&BackfillSchema{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"total":       total,
+			"enqueued_at": time.Now(),
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementBackfill counts one processed file, result is one of the
+// file.Backfill* constants and is used as the column name.
+func (s *FileStore) IncrementBackfill(ctx context.Context, id uuid.UUID, result string) error {
+	switch result {
+	case file.BackfillSucceeded, file.BackfillFailed, file.BackfillSkipped:
+	default:
+		return fmt.Errorf("unexpected backfill result: %s", result)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Model(&BackfillSchema{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			result:       gorm.Expr(result + " + 1"),
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package postgrestore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type changeCursor struct {
+	ID int64
+}
+
+// WriteChanges appends changes to the journals of their users. The journals
+// are locked until the changes are committed, so that a reader never sees a
+// change before another one with a lower ID is committed.
+func (s *FileStore) WriteChanges(ctx context.Context, changes []file.Change) error {
+	var (
+		changeSchemas = make([]ChangeSchema, 0, len(changes))
+		userIDs       = make([]string, 0, len(changes))
+	)
+
+	for _, change := range changes {
+		// the files out of the trees of the users have no journal
+		if change.UserID == uuid.Nil {
+			continue
+		}
+
+		changeSchemas = append(changeSchemas, ChangeSchema{
+			UserID:   change.UserID,
+			FileID:   change.FileID,
+			Type:     change.Type,
+			Path:     change.Path,
+			FromPath: change.FromPath,
+			IsDir:    change.IsDir,
+		})
+
+		userIDs = append(userIDs, change.UserID.String())
+	}
+
+	if len(changeSchemas) == 0 {
+		return nil
+	}
+
+	// in the same order in every transaction, to avoid deadlocks
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "changes:"+userID).Error; err != nil {
+				return fmt.Errorf("unexpected error: %w", err)
+			}
+		}
+
+		if err := tx.Create(&changeSchemas).Error; err != nil {
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListChanges lists the changes of the user after the cursor, oldest first.
+// The next token is the position after the last change listed, or the same
+// position when there are none, so it can always be polled again. It reports
+// whether more changes follow.
+func (s *FileStore) ListChanges(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]file.Change, bool, error) {
+	var changeSchemas []ChangeSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[changeCursor](cursor.Token)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Preload("File").
+		Where("user_id = ?", userID).
+		Where("id > ?", cursorObj.ID).
+		Limit(cursor.Limit + 1).
+		Order("id ASC").
+		Find(&changeSchemas).Error; err != nil {
+		return nil, false, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	more := len(changeSchemas) > cursor.Limit
+	if more {
+		changeSchemas = changeSchemas[:cursor.Limit]
+	}
+
+	if len(changeSchemas) > 0 {
+		cursorObj.ID = changeSchemas[len(changeSchemas)-1].ID
+	}
+
+	cursor.SetNextToken(pagination.EncodeToken(cursorObj))
+
+	changes := make([]file.Change, len(changeSchemas))
+	for i, changeSchema := range changeSchemas {
+		changes[i] = *changeSchema.ToDomainChange()
+	}
+
+	return changes, more, nil
+}
+
+// GetChangesStartToken returns the position after the last change of the
+// user, to list the changes that follow an initial sync.
+func (s *FileStore) GetChangesStartToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	var id int64
+
+	if err := s.db.WithContext(ctx).
+		Model(&ChangeSchema{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error; err != nil {
+		return "", fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return pagination.EncodeToken(changeCursor{ID: id}), nil
+}
@@ -0,0 +1,124 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *FileStore) CreateComment(ctx context.Context, c *file.Comment) error {
+	commentSchema := CommentSchema{
+		ID:       c.ID,
+		FileID:   c.FileID,
+		UserID:   c.UserID,
+		ParentID: c.ParentID,
+		Content:  c.Content,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&commentSchema).Error; err != nil {
+		// the file or the thread was deleted in the meantime
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return file.ErrNotFound
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	c.CreatedAt = commentSchema.CreatedAt
+	c.UpdatedAt = commentSchema.UpdatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetComment(ctx context.Context, id uuid.UUID) (*file.Comment, error) {
+	var commentSchema CommentSchema
+
+	if err := s.db.WithContext(ctx).Preload("User").
+		Where("id = ?", id).
+		First(&commentSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return commentSchema.ToDomainComment(), nil
+}
+
+func (s *FileStore) ListComments(ctx context.Context, fileID uuid.UUID, cursor *pagination.Cursor) ([]file.Comment, error) {
+	var commentSchemas []CommentSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	// threads are paginated, their replies are always returned in full
+	query := s.db.WithContext(ctx).Where("file_id = ?", fileID).Where("parent_id IS NULL")
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit+1).Order("created_at DESC").Order("id DESC").
+		Preload("User").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Replies.User").
+		Find(&commentSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(commentSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &commentSchemas[cursor.Limit].CreatedAt}))
+		commentSchemas = commentSchemas[:cursor.Limit]
+	}
+
+	comments := make([]file.Comment, len(commentSchemas))
+	for i, commentSchema := range commentSchemas {
+		comments[i] = *commentSchema.ToDomainComment()
+	}
+
+	return comments, nil
+}
+
+func (s *FileStore) UpdateComment(ctx context.Context, c *file.Comment) error {
+	if err := s.db.WithContext(ctx).
+		Model(&CommentSchema{}).
+		Where("id = ?", c.ID).
+		Updates(map[string]interface{}{
+			"content":     c.Content,
+			"resolved_at": c.ResolvedAt,
+			"resolved_by": c.ResolvedBy,
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	// replies are removed by the foreign key cascade
+	if err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&CommentSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteCommentsByFileID(ctx context.Context, fileID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("file_id = ?", fileID).
+		Delete(&CommentSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *FileStore) CreateExport(ctx context.Context, e *file.Export) error {
+	exportSchema := ExportSchema{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		Status:    e.Status,
+		Token:     e.Token,
+		CreatedBy: e.CreatedBy,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&exportSchema).Error; err != nil {
+		// only one export per user can be pending
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return file.ErrExportInProgress
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	e.CreatedAt = exportSchema.CreatedAt
+	e.UpdatedAt = exportSchema.UpdatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetExport(ctx context.Context, id uuid.UUID) (*file.Export, error) {
+	var exportSchema ExportSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&exportSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return exportSchema.ToDomainExport(), nil
+}
+
+// GetPendingExport returns the export of the user that is still being
+// built, if any. The stale pending exports are marked as failed first.
+func (s *FileStore) GetPendingExport(ctx context.Context, userID uuid.UUID) (*file.Export, error) {
+	var (
+		exportSchema ExportSchema
+		now          = time.Now()
+	)
+
+	if err := s.db.WithContext(ctx).
+		Model(&ExportSchema{}).
+		Where("user_id = ?", userID).
+		Where("status = ?", file.ExportPending).
+		Where("created_at < ?", now.Add(-file.ExportStaleAfter)).
+		Updates(map[string]interface{}{
+			"status":      file.ExportFailed,
+			"error":       file.ErrExportInterrupted.Error(),
+			"finished_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("status = ?", file.ExportPending).
+		First(&exportSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return exportSchema.ToDomainExport(), nil
+}
+
+func (s *FileStore) ListExports(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]file.Export, error) {
+	var exportSchemas []ExportSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&exportSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(exportSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &exportSchemas[cursor.Limit].CreatedAt}))
+		exportSchemas = exportSchemas[:cursor.Limit]
+	}
+
+	exports := make([]file.Export, len(exportSchemas))
+	for i, exportSchema := range exportSchemas {
+		exports[i] = *exportSchema.ToDomainExport()
+	}
+
+	return exports, nil
+}
+
+func (s *FileStore) ListExportsByIDs(ctx context.Context, ids []uuid.UUID) ([]file.Export, error) {
+	var exportSchemas []ExportSchema
+
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&exportSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	exports := make([]file.Export, len(exportSchemas))
+	for i, exportSchema := range exportSchemas {
+		exports[i] = *exportSchema.ToDomainExport()
+	}
+
+	return exports, nil
+}
+
+// UpdateExport saves the outcome of an export.
+func (s *FileStore) UpdateExport(ctx context.Context, e *file.Export) error {
+	e.UpdatedAt = time.Now()
+
+	if err := s.db.WithContext(ctx).
+		Model(&ExportSchema{}).
+		Where("id = ?", e.ID).
+		Updates(map[string]interface{}{
+			"status":      e.Status,
+			"files":       e.Files,
+			"size":        e.Size,
+			"error":       e.Error,
+			"expires_at":  e.ExpiresAt,
+			"finished_at": e.FinishedAt,
+			"updated_at":  e.UpdatedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) ListSharesByFileIDs(ctx context.Context, fileIDs []uuid.UUID) ([]file.Share, error) {
+	var shareSchemas []ShareSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("file_id IN ?", fileIDs).
+		Order("created_at ASC").
+		Find(&shareSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	shares := make([]file.Share, len(shareSchemas))
+	for i, shareSchema := range shareSchemas {
+		shares[i] = file.Share{
+			FileID:    shareSchema.FileID,
+			UserID:    shareSchema.UserID,
+			Role:      shareSchema.Role,
+			CreatedAt: shareSchema.CreatedAt,
+		}
+	}
+
+	return shares, nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/SeaCloudHub/backend/domain/file"
@@ -39,6 +41,18 @@ func (s *FileStore) Create(ctx context.Context, f *file.File) error {
 		GeneralAccess: "restricted",
 		OwnerID:       f.OwnerID,
 		Thumbnail:     f.Thumbnail,
+		Metadata:      f.Metadata,
+		Thumbnails:    f.Thumbnails,
+		CreatedAt:     f.CreatedAt, // set to now when zero
+		UpdatedAt:     f.UpdatedAt,
+	}
+
+	if fileSchema.Metadata == nil {
+		fileSchema.Metadata = map[string]interface{}{}
+	}
+
+	if fileSchema.Thumbnails == nil {
+		fileSchema.Thumbnails = []file.Thumbnail{}
 	}
 
 	query := s.db.WithContext(ctx)
@@ -70,6 +84,10 @@ func (s *FileStore) ListPager(ctx context.Context, dirpath string, pager *pagina
 		query = query.Order(fmt.Sprintf("similarity(name, '%s') DESC", q))
 	}
 
+	if filter.Sort == file.SortByCapturedAt {
+		query = query.Order("(SELECT captured_at FROM file_media WHERE file_media.file_id = files.id) DESC NULLS LAST")
+	}
+
 	query.Order("created_at DESC")
 
 	if filter.Type != "" {
@@ -80,6 +98,10 @@ func (s *FileStore) ListPager(ctx context.Context, dirpath string, pager *pagina
 		query = query.Where("updated_at > ?", filter.After)
 	}
 
+	if filter.Tag != nil {
+		query = query.Where("id IN (?)", s.taggedFileIDs(*filter.Tag))
+	}
+
 	if err := query.WithContext(ctx).Count(&total).Error; err != nil {
 		return nil, fmt.Errorf("unexpected error: %w", err)
 	}
@@ -123,6 +145,10 @@ func (s *FileStore) ListCursor(ctx context.Context, dirpath string, cursor *pagi
 		query = query.Where("updated_at > ?", filter.After)
 	}
 
+	if filter.Tag != nil {
+		query = query.Where("id IN (?)", s.taggedFileIDs(*filter.Tag))
+	}
+
 	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Order("id DESC").Preload("Owner").
 		Find(&fileSchemas).Error; err != nil {
 		return nil, fmt.Errorf("unexpected error: %w", err)
@@ -208,6 +234,19 @@ func (s *FileStore) Search(ctx context.Context, q string, cursor *pagination.Cur
 		query = query.Where("updated_at > ?", filter.After)
 	}
 
+	if filter.Tag != nil {
+		query = query.Where("id IN (?)", s.taggedFileIDs(*filter.Tag))
+	}
+
+	for key, value := range filter.Metadata {
+		conds, args, err := metadataConds(key, value)
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+
 	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Order("id DESC").Preload("Owner").
 		Find(&fileSchemas).Error; err != nil {
 		return nil, fmt.Errorf("unexpected error: %w", err)
@@ -586,6 +625,82 @@ func (s *FileStore) UpdateThumbnail(ctx context.Context, fileID uuid.UUID, thumb
 	return nil
 }
 
+func (s *FileStore) UpdateThumbnails(ctx context.Context, fileID uuid.UUID, thumbnails []file.Thumbnail) error {
+	if thumbnails == nil {
+		thumbnails = []file.Thumbnail{}
+	}
+
+	b, err := json.Marshal(thumbnails)
+	if err != nil {
+		return fmt.Errorf("marshal thumbnails: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Update("thumbnails", string(b)).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) UpdateRendition(ctx context.Context, fileID uuid.UUID, rendition string) error {
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Update("rendition", rendition).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) UpdateStream(ctx context.Context, fileID uuid.UUID, stream string) error {
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Update("stream", stream).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+// ListThumbnailsByURLs returns the urls still used as the thumbnail of a file.
+func (s *FileStore) ListThumbnailsByURLs(ctx context.Context, urls []string) ([]string, error) {
+	var thumbnails []string
+
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("thumbnail IN ?", urls).
+		Distinct().Pluck("thumbnail", &thumbnails).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return thumbnails, nil
+}
+
+func (s *FileStore) UpdateMetadata(ctx context.Context, fileID uuid.UUID, metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Update("metadata", string(b)).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
 func (s *FileStore) UpdateChunk(ctx context.Context, fileID uuid.UUID, size uint64, last bool) (*file.File, error) {
 	fileSchema := FileSchema{
 		ID:   fileID,
@@ -791,6 +906,10 @@ func (s *FileStore) ListStarred(ctx context.Context, userID uuid.UUID, cursor *p
 		query = query.Where("files.updated_at > ?", filter.After)
 	}
 
+	if filter.Tag != nil {
+		query = query.Where("files.id IN (?)", s.taggedFileIDs(*filter.Tag))
+	}
+
 	if err := query.Limit(cursor.Limit + 1).Order("files.created_at DESC").Preload("Owner").
 		Find(&fileSchemas).Error; err != nil {
 		return nil, fmt.Errorf("unexpected error: %w", err)
@@ -1110,3 +1229,45 @@ func (s *FileStore) ListFiles(ctx context.Context, path string, cursor *paginati
 
 	return files, nil
 }
+
+// metadataConds matches the metadata value of key with a query parameter,
+// which does not tell whether 3 is a number or a string, so both are tried.
+// Containment keeps the GIN index of the metadata in use.
+func metadataConds(key string, value string) ([]string, []interface{}, error) {
+	docs := []map[string]interface{}{{key: value}}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		switch v.(type) {
+		case float64, bool:
+			docs = append(docs, map[string]interface{}{key: json.RawMessage(value)})
+		}
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	for _, doc := range docs {
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal metadata filter: %w", err)
+		}
+
+		conds = append(conds, "metadata @> ?")
+		args = append(args, string(b))
+	}
+
+	return conds, args, nil
+}
+
+// taggedFileIDs selects the IDs of the files tagged with tagID. A shared tag
+// only counts on the files of its owner, like in ListFileTags.
+func (s *FileStore) taggedFileIDs(tagID uuid.UUID) *gorm.DB {
+	return s.db.Model(&FileTagSchema{}).
+		Select("file_tags.file_id").
+		Joins("JOIN tags ON tags.id = file_tags.tag_id").
+		Joins("JOIN files AS tagged ON tagged.id = file_tags.file_id").
+		Where("file_tags.tag_id = ? AND (NOT tags.shared OR tags.user_id = tagged.owner_id)", tagID)
+}
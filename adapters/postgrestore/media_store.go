@@ -0,0 +1,69 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *FileStore) UpsertMedia(ctx context.Context, m *file.Media) error {
+	mediaSchema := MediaSchema{
+		FileID:      m.FileID,
+		Width:       m.Width,
+		Height:      m.Height,
+		Duration:    m.Duration,
+		VideoCodec:  m.VideoCodec,
+		AudioCodec:  m.AudioCodec,
+		CapturedAt:  m.CapturedAt,
+		CameraMake:  m.CameraMake,
+		CameraModel: m.CameraModel,
+		Latitude:    m.Latitude,
+		Longitude:   m.Longitude,
+	}
+
+	if err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "file_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"width", "height", "duration", "video_codec", "audio_codec",
+				"captured_at", "camera_make", "camera_model", "latitude", "longitude", "updated_at"}),
+		}).
+		Create(&mediaSchema).Error; err != nil {
+		// the file was deleted in the meantime
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return file.ErrNotFound
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) GetMedia(ctx context.Context, fileID uuid.UUID) (*file.Media, error) {
+	var mediaSchema MediaSchema
+
+	if err := s.db.WithContext(ctx).Where("file_id = ?", fileID).First(&mediaSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return mediaSchema.ToDomainMedia(), nil
+}
+
+func (s *FileStore) DeleteMediaByFileID(ctx context.Context, fileID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("file_id = ?", fileID).
+		Delete(&MediaSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package postgrestore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *FileStore) CreateMultipartUpload(ctx context.Context, upload *file.MultipartUpload) error {
+	uploadSchema := MultipartUploadSchema{
+		ID:       upload.ID,
+		UserID:   upload.UserID,
+		BucketID: upload.BucketID,
+		Key:      upload.Key,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&uploadSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	upload.CreatedAt = uploadSchema.CreatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetMultipartUpload(ctx context.Context, id uuid.UUID) (*file.MultipartUpload, error) {
+	var uploadSchema MultipartUploadSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&uploadSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return uploadSchema.ToDomainMultipartUpload(), nil
+}
+
+func (s *FileStore) ListMultipartUploadsByIDs(ctx context.Context, ids []uuid.UUID) ([]file.MultipartUpload, error) {
+	var uploadSchemas []MultipartUploadSchema
+
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&uploadSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	uploads := make([]file.MultipartUpload, len(uploadSchemas))
+	for i, uploadSchema := range uploadSchemas {
+		uploads[i] = *uploadSchema.ToDomainMultipartUpload()
+	}
+
+	return uploads, nil
+}
+
+// ListStaleMultipartUploads lists the uploads started before a time, oldest
+// first.
+func (s *FileStore) ListStaleMultipartUploads(ctx context.Context, before time.Time, limit int) ([]file.MultipartUpload, error) {
+	var uploadSchemas []MultipartUploadSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&uploadSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	uploads := make([]file.MultipartUpload, len(uploadSchemas))
+	for i, uploadSchema := range uploadSchemas {
+		uploads[i] = *uploadSchema.ToDomainMultipartUpload()
+	}
+
+	return uploads, nil
+}
+
+// DeleteMultipartUpload deletes the upload along with its parts and releases
+// the storage reserved for them, the content of the parts is left to the
+// caller.
+func (s *FileStore) DeleteMultipartUpload(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var uploadSchema MultipartUploadSchema
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&uploadSchema).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		if err := releaseStorage(tx, uploadSchema.UserID, uploadSchema.Reserved); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", id).Delete(&MultipartUploadSchema{}).Error; err != nil {
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReserveMultipartStorage charges size to the storage usage of the user of
+// the upload, as long as it stays within the capacity of the user.
+func (s *FileStore) ReserveMultipartStorage(ctx context.Context, id uuid.UUID, size uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var uploadSchema MultipartUploadSchema
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&uploadSchema).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return file.ErrNotFound
+			}
+
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		result := tx.Model(&UserSchema{}).
+			Where("id = ?", uploadSchema.UserID).
+			Where("storage_usage + ? <= storage_capacity", size).
+			Update("storage_usage", gorm.Expr("storage_usage + ?", size))
+		if result.Error != nil {
+			return fmt.Errorf("unexpected error: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return file.ErrStorageCapacityExceeded
+		}
+
+		if err := tx.Model(&MultipartUploadSchema{}).
+			Where("id = ?", id).
+			Update("reserved", gorm.Expr("reserved + ?", size)).Error; err != nil {
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReleaseMultipartStorage gives back size of the storage reserved for the
+// upload, such as the reservation of a part that could not be stored.
+func (s *FileStore) ReleaseMultipartStorage(ctx context.Context, id uuid.UUID, size uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var uploadSchema MultipartUploadSchema
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&uploadSchema).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		size = min(size, uploadSchema.Reserved)
+
+		if err := releaseStorage(tx, uploadSchema.UserID, size); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&MultipartUploadSchema{}).
+			Where("id = ?", id).
+			Update("reserved", gorm.Expr("reserved - ?", size)).Error; err != nil {
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func releaseStorage(tx *gorm.DB, userID uuid.UUID, size uint64) error {
+	if size == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&UserSchema{}).
+		Where("id = ?", userID).
+		Update("storage_usage", gorm.Expr("GREATEST(storage_usage - ?, 0)", size)).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertUploadPart records a part, uploading a part number again replaces it.
+func (s *FileStore) UpsertUploadPart(ctx context.Context, part *file.UploadPart) error {
+	part.UpdatedAt = time.Now()
+
+	partSchema := UploadPartSchema{
+		UploadID:  part.UploadID,
+		Number:    part.Number,
+		Size:      part.Size,
+		MD5:       hex.EncodeToString(part.MD5),
+		UpdatedAt: part.UpdatedAt,
+	}
+
+	if err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "upload_id"}, {Name: "number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"size", "md5", "updated_at"}),
+		}).
+		Create(&partSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) ListUploadParts(ctx context.Context, uploadID uuid.UUID) ([]file.UploadPart, error) {
+	var partSchemas []UploadPartSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("upload_id = ?", uploadID).
+		Order("number ASC").
+		Find(&partSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	parts := make([]file.UploadPart, len(partSchemas))
+	for i, partSchema := range partSchemas {
+		parts[i] = *partSchema.ToDomainUploadPart()
+	}
+
+	return parts, nil
+}
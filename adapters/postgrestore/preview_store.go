@@ -0,0 +1,64 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *FileStore) UpsertPreview(ctx context.Context, p *file.Preview) error {
+	previewSchema := PreviewSchema{
+		FileID:    p.FileID,
+		Kind:      p.Kind,
+		Content:   p.Content,
+		Language:  p.Language,
+		Lines:     p.Lines,
+		Truncated: p.Truncated,
+		Peaks:     p.Peaks,
+	}
+
+	if previewSchema.Peaks == nil {
+		previewSchema.Peaks = []float64{}
+	}
+
+	if err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "file_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"kind", "content", "language", "lines", "truncated",
+				"peaks", "updated_at"}),
+		}).
+		Create(&previewSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) GetPreview(ctx context.Context, fileID uuid.UUID) (*file.Preview, error) {
+	var previewSchema PreviewSchema
+
+	if err := s.db.WithContext(ctx).Where("file_id = ?", fileID).First(&previewSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return previewSchema.ToDomainPreview(), nil
+}
+
+func (s *FileStore) DeletePreviewByFileID(ctx context.Context, fileID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("file_id = ?", fileID).
+		Delete(&PreviewSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
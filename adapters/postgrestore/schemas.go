@@ -63,23 +63,28 @@ func (s *UserSchema) ToDomainUser() *identity.User {
 }
 
 type FileSchema struct {
-	ID            uuid.UUID    `gorm:"column:id"`
-	Name          string       `gorm:"column:name"`
-	Path          string       `gorm:"column:path"`
-	PreviousPath  *string      `gorm:"column:previous_path"` // user for move to trash
-	Size          uint64       `gorm:"column:size"`
-	Mode          uint32       `gorm:"column:mode"`
-	MimeType      string       `gorm:"column:mime_type"`
-	Type          string       `gorm:"column:type;->"`
-	Thumbnail     *string      `gorm:"column:thumbnail"`
-	MD5           string       `gorm:"column:md5"`
-	IsDir         bool         `gorm:"column:is_dir"`
-	GeneralAccess string       `gorm:"column:general_access"`
-	OwnerID       uuid.UUID    `gorm:"column:owner_id"`
-	CreatedAt     time.Time    `gorm:"column:created_at"`
-	UpdatedAt     time.Time    `gorm:"column:updated_at"`
-	DeletedAt     *time.Time   `gorm:"column:deleted_at"`
-	FinishedAt    sql.NullTime `gorm:"column:finished_at"`
+	ID            uuid.UUID              `gorm:"column:id"`
+	Name          string                 `gorm:"column:name"`
+	Path          string                 `gorm:"column:path"`
+	PreviousPath  *string                `gorm:"column:previous_path"` // user for move to trash
+	Size          uint64                 `gorm:"column:size"`
+	Mode          uint32                 `gorm:"column:mode"`
+	MimeType      string                 `gorm:"column:mime_type"`
+	Type          string                 `gorm:"column:type;->"`
+	Thumbnail     *string                `gorm:"column:thumbnail"`
+	Thumbnails    []file.Thumbnail       `gorm:"column:thumbnails;serializer:json"`
+	Rendition     *string                `gorm:"column:rendition"`
+	Stream        *string                `gorm:"column:stream"`
+	MD5           string                 `gorm:"column:md5"`
+	IsDir         bool                   `gorm:"column:is_dir"`
+	GeneralAccess string                 `gorm:"column:general_access"`
+	OwnerID       uuid.UUID              `gorm:"column:owner_id"`
+	Metadata      map[string]interface{} `gorm:"column:metadata;serializer:json"`
+	CreatedAt     time.Time              `gorm:"column:created_at"`
+	UpdatedAt     time.Time              `gorm:"column:updated_at"`
+	DeletedAt     *time.Time             `gorm:"column:deleted_at"`
+	FinishedAt    sql.NullTime           `gorm:"column:finished_at"`
+	QuarantinedAt *time.Time             `gorm:"column:quarantined_at"`
 
 	Owner *UserSchema `gorm:"foreignKey:OwnerID;references:ID"`
 }
@@ -114,10 +119,15 @@ func (s *FileSchema) ToDomainFile() *file.File {
 		MimeType:      s.MimeType,
 		Type:          s.Type,
 		Thumbnail:     s.Thumbnail,
+		Thumbnails:    s.Thumbnails,
+		Rendition:     s.Rendition,
+		Stream:        s.Stream,
 		MD5:           md5,
 		IsDir:         s.IsDir,
 		GeneralAccess: s.GeneralAccess,
 		OwnerID:       s.OwnerID,
+		Metadata:      s.Metadata,
+		QuarantinedAt: s.QuarantinedAt,
 		CreatedAt:     s.CreatedAt,
 		UpdatedAt:     s.UpdatedAt,
 		Owner:         owner,
@@ -185,3 +195,543 @@ func (s *LogSchema) ToDomainFile() *file.File {
 
 	return file
 }
+
+type ChangeSchema struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    uuid.UUID `gorm:"column:user_id"`
+	FileID    uuid.UUID `gorm:"column:file_id"`
+	Type      string    `gorm:"column:type"`
+	Path      string    `gorm:"column:path"`
+	FromPath  string    `gorm:"column:from_path"`
+	IsDir     bool      `gorm:"column:is_dir"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	File *FileSchema `gorm:"foreignKey:FileID;references:ID"`
+}
+
+func (ChangeSchema) TableName() string { return "changes" }
+
+func (s *ChangeSchema) ToDomainChange() *file.Change {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Change{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		FileID:    s.FileID,
+		Type:      s.Type,
+		Path:      s.Path,
+		FromPath:  s.FromPath,
+		IsDir:     s.IsDir,
+		CreatedAt: s.CreatedAt,
+		File:      s.File.ToDomainFile(),
+	}
+}
+
+type SmartFolderSchema struct {
+	ID        uuid.UUID  `gorm:"column:id"`
+	UserID    uuid.UUID  `gorm:"column:user_id"`
+	ParentID  uuid.UUID  `gorm:"column:parent_id"`
+	Name      string     `gorm:"column:name"`
+	Query     string     `gorm:"column:query"`
+	Type      string     `gorm:"column:type"`
+	After     *time.Time `gorm:"column:after"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at"`
+}
+
+func (SmartFolderSchema) TableName() string { return "smart_folders" }
+
+func (s *SmartFolderSchema) ToDomainSmartFolder() *file.SmartFolder {
+	if s == nil {
+		return nil
+	}
+
+	return &file.SmartFolder{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		ParentID:  s.ParentID,
+		Name:      s.Name,
+		Query:     s.Query,
+		Type:      s.Type,
+		After:     s.After,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type TagSchema struct {
+	ID        uuid.UUID `gorm:"column:id"`
+	UserID    uuid.UUID `gorm:"column:user_id"`
+	Name      string    `gorm:"column:name"`
+	Color     string    `gorm:"column:color"`
+	Shared    bool      `gorm:"column:shared"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (TagSchema) TableName() string { return "tags" }
+
+func (s *TagSchema) ToDomainTag() *file.Tag {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Tag{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Name:      s.Name,
+		Color:     s.Color,
+		Shared:    s.Shared,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type FileTagSchema struct {
+	FileID    uuid.UUID `gorm:"column:file_id"`
+	TagID     uuid.UUID `gorm:"column:tag_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (FileTagSchema) TableName() string { return "file_tags" }
+
+type CommentSchema struct {
+	ID         uuid.UUID  `gorm:"column:id"`
+	FileID     uuid.UUID  `gorm:"column:file_id"`
+	UserID     uuid.UUID  `gorm:"column:user_id"`
+	ParentID   *uuid.UUID `gorm:"column:parent_id"`
+	Content    string     `gorm:"column:content"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+	ResolvedBy *uuid.UUID `gorm:"column:resolved_by"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+
+	User    *UserSchema     `gorm:"foreignKey:UserID;references:ID"`
+	Replies []CommentSchema `gorm:"foreignKey:ParentID;references:ID"`
+}
+
+func (CommentSchema) TableName() string { return "comments" }
+
+func (s *CommentSchema) ToDomainComment() *file.Comment {
+	if s == nil {
+		return nil
+	}
+
+	var replies []file.Comment
+	for _, reply := range s.Replies {
+		replies = append(replies, *reply.ToDomainComment())
+	}
+
+	return &file.Comment{
+		ID:         s.ID,
+		FileID:     s.FileID,
+		UserID:     s.UserID,
+		ParentID:   s.ParentID,
+		Content:    s.Content,
+		ResolvedAt: s.ResolvedAt,
+		ResolvedBy: s.ResolvedBy,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+		User:       s.User.ToDomainUser(),
+		Replies:    replies,
+	}
+}
+
+type MediaSchema struct {
+	FileID      uuid.UUID  `gorm:"column:file_id"`
+	Width       int        `gorm:"column:width"`
+	Height      int        `gorm:"column:height"`
+	Duration    float64    `gorm:"column:duration"`
+	VideoCodec  string     `gorm:"column:video_codec"`
+	AudioCodec  string     `gorm:"column:audio_codec"`
+	CapturedAt  *time.Time `gorm:"column:captured_at"`
+	CameraMake  string     `gorm:"column:camera_make"`
+	CameraModel string     `gorm:"column:camera_model"`
+	Latitude    *float64   `gorm:"column:latitude"`
+	Longitude   *float64   `gorm:"column:longitude"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (MediaSchema) TableName() string { return "file_media" }
+
+func (s *MediaSchema) ToDomainMedia() *file.Media {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Media{
+		FileID:      s.FileID,
+		Width:       s.Width,
+		Height:      s.Height,
+		Duration:    s.Duration,
+		VideoCodec:  s.VideoCodec,
+		AudioCodec:  s.AudioCodec,
+		CapturedAt:  s.CapturedAt,
+		CameraMake:  s.CameraMake,
+		CameraModel: s.CameraModel,
+		Latitude:    s.Latitude,
+		Longitude:   s.Longitude,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+type BackfillSchema struct {
+	ID         uuid.UUID           `gorm:"column:id"`
+	Filter     file.BackfillFilter `gorm:"column:filter;serializer:json"`
+	Total      int                 `gorm:"column:total"`
+	Succeeded  int                 `gorm:"column:succeeded"`
+	Failed     int                 `gorm:"column:failed"`
+	Skipped    int                 `gorm:"column:skipped"`
+	CreatedBy  *uuid.UUID          `gorm:"column:created_by"`
+	EnqueuedAt *time.Time          `gorm:"column:enqueued_at"`
+	CreatedAt  time.Time           `gorm:"column:created_at"`
+	UpdatedAt  time.Time           `gorm:"column:updated_at"`
+}
+
+func (BackfillSchema) TableName() string { return "thumbnail_backfills" }
+
+func (s *BackfillSchema) ToDomainBackfill() *file.Backfill {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Backfill{
+		ID:         s.ID,
+		Filter:     s.Filter,
+		Total:      s.Total,
+		Succeeded:  s.Succeeded,
+		Failed:     s.Failed,
+		Skipped:    s.Skipped,
+		CreatedBy:  s.CreatedBy,
+		EnqueuedAt: s.EnqueuedAt,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+type PreviewSchema struct {
+	FileID    uuid.UUID `gorm:"column:file_id"`
+	Kind      string    `gorm:"column:kind"`
+	Content   string    `gorm:"column:content"`
+	Language  string    `gorm:"column:language"`
+	Lines     int       `gorm:"column:lines"`
+	Truncated bool      `gorm:"column:truncated"`
+	Peaks     []float64 `gorm:"column:peaks;serializer:json"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (PreviewSchema) TableName() string { return "file_previews" }
+
+func (s *PreviewSchema) ToDomainPreview() *file.Preview {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Preview{
+		FileID:    s.FileID,
+		Kind:      s.Kind,
+		Content:   s.Content,
+		Language:  s.Language,
+		Lines:     s.Lines,
+		Truncated: s.Truncated,
+		Peaks:     s.Peaks,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type ScrubSchema struct {
+	ID          uuid.UUID         `gorm:"column:id"`
+	Options     file.ScrubOptions `gorm:"column:options;serializer:json"`
+	Status      string            `gorm:"column:status"`
+	Checked     int               `gorm:"column:checked"`
+	Missing     int               `gorm:"column:missing"`
+	Mismatched  int               `gorm:"column:mismatched"`
+	Orphaned    int               `gorm:"column:orphaned"`
+	Unchecked   int               `gorm:"column:unchecked"`
+	Repaired    int               `gorm:"column:repaired"`
+	Quarantined int               `gorm:"column:quarantined"`
+	Error       string            `gorm:"column:error"`
+	CreatedBy   *uuid.UUID        `gorm:"column:created_by"`
+	FinishedAt  *time.Time        `gorm:"column:finished_at"`
+	CreatedAt   time.Time         `gorm:"column:created_at"`
+	UpdatedAt   time.Time         `gorm:"column:updated_at"`
+}
+
+func (ScrubSchema) TableName() string { return "scrubs" }
+
+func (s *ScrubSchema) ToDomainScrub() *file.Scrub {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Scrub{
+		ID:          s.ID,
+		Options:     s.Options,
+		Status:      s.Status,
+		Checked:     s.Checked,
+		Missing:     s.Missing,
+		Mismatched:  s.Mismatched,
+		Orphaned:    s.Orphaned,
+		Unchecked:   s.Unchecked,
+		Repaired:    s.Repaired,
+		Quarantined: s.Quarantined,
+		Error:       s.Error,
+		CreatedBy:   s.CreatedBy,
+		FinishedAt:  s.FinishedAt,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+type ScrubIssueSchema struct {
+	ID        int64      `gorm:"column:id"`
+	ScrubID   uuid.UUID  `gorm:"column:scrub_id"`
+	FileID    *uuid.UUID `gorm:"column:file_id"`
+	Path      string     `gorm:"column:path"`
+	Kind      string     `gorm:"column:kind"`
+	Expected  string     `gorm:"column:expected"`
+	Actual    string     `gorm:"column:actual"`
+	Action    string     `gorm:"column:action"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
+
+func (ScrubIssueSchema) TableName() string { return "scrub_issues" }
+
+func (s *ScrubIssueSchema) ToDomainScrubIssue() *file.ScrubIssue {
+	if s == nil {
+		return nil
+	}
+
+	return &file.ScrubIssue{
+		ID:        s.ID,
+		ScrubID:   s.ScrubID,
+		FileID:    s.FileID,
+		Path:      s.Path,
+		Kind:      s.Kind,
+		Expected:  s.Expected,
+		Actual:    s.Actual,
+		Action:    s.Action,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type ExportSchema struct {
+	ID         uuid.UUID  `gorm:"column:id"`
+	UserID     uuid.UUID  `gorm:"column:user_id"`
+	Status     string     `gorm:"column:status"`
+	Files      int        `gorm:"column:files"`
+	Size       uint64     `gorm:"column:size"`
+	Error      string     `gorm:"column:error"`
+	Token      string     `gorm:"column:token"`
+	CreatedBy  *uuid.UUID `gorm:"column:created_by"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+}
+
+func (ExportSchema) TableName() string { return "exports" }
+
+func (s *ExportSchema) ToDomainExport() *file.Export {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Export{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		Status:     s.Status,
+		Files:      s.Files,
+		Size:       s.Size,
+		Error:      s.Error,
+		Token:      s.Token,
+		CreatedBy:  s.CreatedBy,
+		ExpiresAt:  s.ExpiresAt,
+		FinishedAt: s.FinishedAt,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+type AccessKeySchema struct {
+	ID          uuid.UUID  `gorm:"column:id"`
+	UserID      uuid.UUID  `gorm:"column:user_id"`
+	Name        string     `gorm:"column:name"`
+	AccessKeyID string     `gorm:"column:access_key_id"`
+	SecretKey   string     `gorm:"column:secret_key"`
+	SecretKeyID string     `gorm:"column:secret_key_id"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+}
+
+func (AccessKeySchema) TableName() string { return "access_keys" }
+
+func (s *AccessKeySchema) ToDomainAccessKey() *identity.AccessKey {
+	if s == nil {
+		return nil
+	}
+
+	return &identity.AccessKey{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		Name:        s.Name,
+		AccessKeyID: s.AccessKeyID,
+		SecretKey:   s.SecretKey,
+		LastUsedAt:  s.LastUsedAt,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+type MultipartUploadSchema struct {
+	ID        uuid.UUID `gorm:"column:id"`
+	UserID    uuid.UUID `gorm:"column:user_id"`
+	BucketID  uuid.UUID `gorm:"column:bucket_id"`
+	Key       string    `gorm:"column:key"`
+	Reserved  uint64    `gorm:"column:reserved"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (MultipartUploadSchema) TableName() string { return "multipart_uploads" }
+
+func (s *MultipartUploadSchema) ToDomainMultipartUpload() *file.MultipartUpload {
+	if s == nil {
+		return nil
+	}
+
+	return &file.MultipartUpload{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		BucketID:  s.BucketID,
+		Key:       s.Key,
+		Reserved:  s.Reserved,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type UploadPartSchema struct {
+	UploadID  uuid.UUID `gorm:"column:upload_id"`
+	Number    int       `gorm:"column:number"`
+	Size      uint64    `gorm:"column:size"`
+	MD5       string    `gorm:"column:md5"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (UploadPartSchema) TableName() string { return "upload_parts" }
+
+func (s *UploadPartSchema) ToDomainUploadPart() *file.UploadPart {
+	if s == nil {
+		return nil
+	}
+
+	md5, _ := hex.DecodeString(s.MD5)
+
+	return &file.UploadPart{
+		UploadID:  s.UploadID,
+		Number:    s.Number,
+		Size:      s.Size,
+		MD5:       md5,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type SSHKeySchema struct {
+	ID          uuid.UUID  `gorm:"column:id"`
+	UserID      uuid.UUID  `gorm:"column:user_id"`
+	Name        string     `gorm:"column:name"`
+	PublicKey   string     `gorm:"column:public_key"`
+	Fingerprint string     `gorm:"column:fingerprint"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+}
+
+func (SSHKeySchema) TableName() string { return "ssh_keys" }
+
+func (s *SSHKeySchema) ToDomainSSHKey() *identity.SSHKey {
+	if s == nil {
+		return nil
+	}
+
+	return &identity.SSHKey{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		Name:        s.Name,
+		PublicKey:   s.PublicKey,
+		Fingerprint: s.Fingerprint,
+		LastUsedAt:  s.LastUsedAt,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+type WebhookSchema struct {
+	ID          uuid.UUID `gorm:"column:id"`
+	UserID      uuid.UUID `gorm:"column:user_id"`
+	DirectoryID uuid.UUID `gorm:"column:directory_id"`
+	URL         string    `gorm:"column:url"`
+	Events      []string  `gorm:"column:events;serializer:json"`
+	Secret      string    `gorm:"column:secret"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (WebhookSchema) TableName() string { return "webhooks" }
+
+func (s *WebhookSchema) ToDomainWebhook() *file.Webhook {
+	if s == nil {
+		return nil
+	}
+
+	return &file.Webhook{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		DirectoryID: s.DirectoryID,
+		URL:         s.URL,
+		Events:      s.Events,
+		Secret:      s.Secret,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+type WebhookDeliverySchema struct {
+	ID          uuid.UUID  `gorm:"column:id"`
+	WebhookID   uuid.UUID  `gorm:"column:webhook_id"`
+	Event       string     `gorm:"column:event"`
+	Payload     string     `gorm:"column:payload"`
+	Status      string     `gorm:"column:status"`
+	Attempts    int        `gorm:"column:attempts"`
+	StatusCode  int        `gorm:"column:status_code"`
+	Error       string     `gorm:"column:error"`
+	Redelivery  bool       `gorm:"column:redelivery"`
+	DeliveredAt *time.Time `gorm:"column:delivered_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (WebhookDeliverySchema) TableName() string { return "webhook_deliveries" }
+
+func (s *WebhookDeliverySchema) ToDomainWebhookDelivery() *file.WebhookDelivery {
+	if s == nil {
+		return nil
+	}
+
+	return &file.WebhookDelivery{
+		ID:          s.ID,
+		WebhookID:   s.WebhookID,
+		Event:       s.Event,
+		Payload:     s.Payload,
+		Status:      s.Status,
+		Attempts:    s.Attempts,
+		StatusCode:  s.StatusCode,
+		Error:       s.Error,
+		Redelivery:  s.Redelivery,
+		DeliveredAt: s.DeliveredAt,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
@@ -0,0 +1,249 @@
+package postgrestore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type issueCursor struct {
+	ID *int64
+}
+
+// ListScrubFiles lists the uploaded files, the unfinished ones have no
+// complete content to compare with.
+func (s *FileStore) ListScrubFiles(ctx context.Context, cursor *pagination.Cursor) ([]file.File, error) {
+	var fileSchemas []FileSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).
+		Where("finished_at IS NOT NULL").
+		Where("is_dir = ?", false)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&fileSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(fileSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &fileSchemas[cursor.Limit].CreatedAt}))
+		fileSchemas = fileSchemas[:cursor.Limit]
+	}
+
+	files := make([]file.File, len(fileSchemas))
+	for i, fileSchema := range fileSchemas {
+		files[i] = *fileSchema.ToDomainFile()
+	}
+
+	return files, nil
+}
+
+// ListExistingIDs returns the ids that have a row, including the files that
+// are still being uploaded or are in the trash.
+func (s *FileStore) ListExistingIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	var existing []uuid.UUID
+
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id IN ?", ids).
+		Pluck("id", &existing).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return existing, nil
+}
+
+// RepairFile replaces the size and MD5 of a file with the ones of the
+// storage, a repaired file is no longer quarantined.
+func (s *FileStore) RepairFile(ctx context.Context, fileID uuid.UUID, size uint64, md5 []byte) error {
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Updates(map[string]interface{}{
+			"size":           size,
+			"md5":            hex.EncodeToString(md5),
+			"quarantined_at": nil,
+			"updated_at":     time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) QuarantineFile(ctx context.Context, fileID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Model(&FileSchema{}).
+		Where("id = ?", fileID).
+		Where("quarantined_at IS NULL").
+		Update("quarantined_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) CreateScrub(ctx context.Context, sc *file.Scrub) error {
+	scrubSchema := ScrubSchema{
+		ID:        sc.ID,
+		Options:   sc.Options,
+		Status:    sc.Status,
+		CreatedBy: sc.CreatedBy,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&scrubSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	sc.CreatedAt = scrubSchema.CreatedAt
+	sc.UpdatedAt = scrubSchema.UpdatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetScrub(ctx context.Context, id uuid.UUID) (*file.Scrub, error) {
+	var scrubSchema ScrubSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&scrubSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return scrubSchema.ToDomainScrub(), nil
+}
+
+func (s *FileStore) ListScrubs(ctx context.Context, cursor *pagination.Cursor) ([]file.Scrub, error) {
+	var scrubSchemas []ScrubSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&scrubSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(scrubSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &scrubSchemas[cursor.Limit].CreatedAt}))
+		scrubSchemas = scrubSchemas[:cursor.Limit]
+	}
+
+	scrubs := make([]file.Scrub, len(scrubSchemas))
+	for i, scrubSchema := range scrubSchemas {
+		scrubs[i] = *scrubSchema.ToDomainScrub()
+	}
+
+	return scrubs, nil
+}
+
+// UpdateScrub saves the status and the counters of a scrub.
+func (s *FileStore) UpdateScrub(ctx context.Context, sc *file.Scrub) error {
+	sc.UpdatedAt = time.Now()
+
+	if err := s.db.WithContext(ctx).
+		Model(&ScrubSchema{}).
+		Where("id = ?", sc.ID).
+		Updates(map[string]interface{}{
+			"status":      sc.Status,
+			"checked":     sc.Checked,
+			"missing":     sc.Missing,
+			"mismatched":  sc.Mismatched,
+			"orphaned":    sc.Orphaned,
+			"unchecked":   sc.Unchecked,
+			"repaired":    sc.Repaired,
+			"quarantined": sc.Quarantined,
+			"error":       sc.Error,
+			"finished_at": sc.FinishedAt,
+			"updated_at":  sc.UpdatedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) CreateScrubIssues(ctx context.Context, issues []file.ScrubIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	issueSchemas := make([]ScrubIssueSchema, len(issues))
+	for i, issue := range issues {
+		issueSchemas[i] = ScrubIssueSchema{
+			ScrubID:  issue.ScrubID,
+			FileID:   issue.FileID,
+			Path:     issue.Path,
+			Kind:     issue.Kind,
+			Expected: issue.Expected,
+			Actual:   issue.Actual,
+			Action:   issue.Action,
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Create(&issueSchemas).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+// ListScrubIssues lists the issues of a scrub in the order they were found,
+// kind is optional.
+func (s *FileStore) ListScrubIssues(ctx context.Context, scrubID uuid.UUID, kind string, cursor *pagination.Cursor) ([]file.ScrubIssue, error) {
+	var issueSchemas []ScrubIssueSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[issueCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).Where("scrub_id = ?", scrubID)
+	if cursorObj.ID != nil {
+		query = query.Where("id >= ?", cursorObj.ID)
+	}
+
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("id ASC").Find(&issueSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(issueSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(issueCursor{ID: &issueSchemas[cursor.Limit].ID}))
+		issueSchemas = issueSchemas[:cursor.Limit]
+	}
+
+	issues := make([]file.ScrubIssue, len(issueSchemas))
+	for i, issueSchema := range issueSchemas {
+		issues[i] = *issueSchema.ToDomainScrubIssue()
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,140 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *FileStore) CreateSmartFolder(ctx context.Context, f *file.SmartFolder) error {
+	smartFolderSchema := SmartFolderSchema{
+		ID:       f.ID,
+		UserID:   f.UserID,
+		ParentID: f.ParentID,
+		Name:     f.Name,
+		Query:    f.Query,
+		Type:     f.Type,
+		After:    f.After,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&smartFolderSchema).Error; err != nil {
+		// the parent directory was deleted in the meantime
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return file.ErrNotFound
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	*f = *smartFolderSchema.ToDomainSmartFolder()
+
+	return nil
+}
+
+func (s *FileStore) GetSmartFolder(ctx context.Context, id, userID uuid.UUID) (*file.SmartFolder, error) {
+	var smartFolderSchema SmartFolderSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("id = ?", id).Where("user_id = ?", userID).
+		First(&smartFolderSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return smartFolderSchema.ToDomainSmartFolder(), nil
+}
+
+func (s *FileStore) ListSmartFolders(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]file.SmartFolder, error) {
+	var smartFolderSchemas []SmartFolderSchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Order("id DESC").
+		Find(&smartFolderSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(smartFolderSchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &smartFolderSchemas[cursor.Limit].CreatedAt}))
+		smartFolderSchemas = smartFolderSchemas[:cursor.Limit]
+	}
+
+	folders := make([]file.SmartFolder, len(smartFolderSchemas))
+	for i, smartFolderSchema := range smartFolderSchemas {
+		folders[i] = *smartFolderSchema.ToDomainSmartFolder()
+	}
+
+	return folders, nil
+}
+
+func (s *FileStore) ListSmartFoldersByParent(ctx context.Context, userID, parentID uuid.UUID) ([]file.SmartFolder, error) {
+	var smartFolderSchemas []SmartFolderSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).Where("parent_id = ?", parentID).
+		Order("name ASC").
+		Find(&smartFolderSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	folders := make([]file.SmartFolder, len(smartFolderSchemas))
+	for i, smartFolderSchema := range smartFolderSchemas {
+		folders[i] = *smartFolderSchema.ToDomainSmartFolder()
+	}
+
+	return folders, nil
+}
+
+func (s *FileStore) UpdateSmartFolder(ctx context.Context, f *file.SmartFolder) error {
+	if err := s.db.WithContext(ctx).
+		Model(&SmartFolderSchema{}).
+		Where("id = ?", f.ID).Where("user_id = ?", f.UserID).
+		Updates(map[string]interface{}{
+			"parent_id": f.ParentID,
+			"name":      f.Name,
+			"query":     f.Query,
+			"type":      f.Type,
+			"after":     f.After,
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteSmartFolder(ctx context.Context, id, userID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&SmartFolderSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteSmartFoldersByUserID(ctx context.Context, userID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&SmartFolderSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *UserStore) CreateSSHKey(ctx context.Context, key *identity.SSHKey) error {
+	sshKeySchema := SSHKeySchema{
+		ID:          key.ID,
+		UserID:      key.UserID,
+		Name:        key.Name,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&sshKeySchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return identity.ErrSSHKeyAlreadyExists
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	key.CreatedAt = sshKeySchema.CreatedAt
+
+	return nil
+}
+
+func (s *UserStore) GetSSHKeyByFingerprint(ctx context.Context, fingerprint string) (*identity.SSHKey, error) {
+	var sshKeySchema SSHKeySchema
+
+	if err := s.db.WithContext(ctx).Where("fingerprint = ?", fingerprint).First(&sshKeySchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrSSHKeyNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return sshKeySchema.ToDomainSSHKey(), nil
+}
+
+func (s *UserStore) ListSSHKeys(ctx context.Context, userID uuid.UUID) ([]identity.SSHKey, error) {
+	var sshKeySchemas []SSHKeySchema
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&sshKeySchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	keys := make([]identity.SSHKey, len(sshKeySchemas))
+	for i, sshKeySchema := range sshKeySchemas {
+		keys[i] = *sshKeySchema.ToDomainSSHKey()
+	}
+
+	return keys, nil
+}
+
+// DeleteSSHKey revokes the key id of the user.
+func (s *UserStore) DeleteSSHKey(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", id).Delete(&SSHKeySchema{})
+	if result.Error != nil {
+		return fmt.Errorf("unexpected error: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return identity.ErrSSHKeyNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) UpdateSSHKeyLastUsedAt(ctx context.Context, id uuid.UUID) error {
+	if err := s.db.WithContext(ctx).Model(&SSHKeySchema{}).
+		Where("id = ?", id).
+		Update("last_used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *FileStore) CreateTag(ctx context.Context, t *file.Tag) error {
+	tagSchema := TagSchema{
+		ID:     t.ID,
+		UserID: t.UserID,
+		Name:   t.Name,
+		Color:  t.Color,
+		Shared: t.Shared,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&tagSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return file.ErrTagAlreadyExists
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	*t = *tagSchema.ToDomainTag()
+
+	return nil
+}
+
+func (s *FileStore) GetTag(ctx context.Context, id uuid.UUID) (*file.Tag, error) {
+	var tagSchema TagSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&tagSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return tagSchema.ToDomainTag(), nil
+}
+
+func (s *FileStore) ListTags(ctx context.Context, userID uuid.UUID) ([]file.Tag, error) {
+	var tagSchemas []TagSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tagSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	tags := make([]file.Tag, len(tagSchemas))
+	for i, tagSchema := range tagSchemas {
+		tags[i] = *tagSchema.ToDomainTag()
+	}
+
+	return tags, nil
+}
+
+func (s *FileStore) UpdateTag(ctx context.Context, t *file.Tag) error {
+	if err := s.db.WithContext(ctx).
+		Model(&TagSchema{}).
+		Where("id = ?", t.ID).
+		Updates(map[string]interface{}{
+			"name":   t.Name,
+			"color":  t.Color,
+			"shared": t.Shared,
+		}).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return file.ErrTagAlreadyExists
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	// file_tags rows are removed by the foreign key cascade
+	if err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&TagSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) TagFiles(ctx context.Context, tagID uuid.UUID, fileIDs []uuid.UUID) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	fileTagSchemas := make([]FileTagSchema, len(fileIDs))
+	for i, fileID := range fileIDs {
+		fileTagSchemas[i] = FileTagSchema{
+			FileID: fileID,
+			TagID:  tagID,
+		}
+	}
+
+	if err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&fileTagSchemas).Error; err != nil {
+		// the tag or one of the files was deleted in the meantime
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return file.ErrNotFound
+		}
+
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) UntagFiles(ctx context.Context, tagID uuid.UUID, fileIDs []uuid.UUID) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("tag_id = ? AND file_id IN ?", tagID, fileIDs).
+		Delete(&FileTagSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) ListFileTags(ctx context.Context, fileID, userID uuid.UUID) ([]file.Tag, error) {
+	var tagSchemas []TagSchema
+
+	// a shared tag is only shown to collaborators when it was applied by the file owner
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN file_tags ON file_tags.tag_id = tags.id").
+		Joins("JOIN files ON files.id = file_tags.file_id").
+		Where("file_tags.file_id = ?", fileID).
+		Where("tags.user_id = ? OR (tags.shared AND tags.user_id = files.owner_id)", userID).
+		Order("tags.name ASC").
+		Find(&tagSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	tags := make([]file.Tag, len(tagSchemas))
+	for i, tagSchema := range tagSchemas {
+		tags[i] = *tagSchema.ToDomainTag()
+	}
+
+	return tags, nil
+}
+
+func (s *FileStore) CountTaggedFilesNotOwnedBy(ctx context.Context, tagID, userID uuid.UUID) (int64, error) {
+	var total int64
+
+	if err := s.db.WithContext(ctx).Model(&FileTagSchema{}).
+		Joins("JOIN files ON files.id = file_tags.file_id").
+		Where("file_tags.tag_id = ? AND files.owner_id <> ?", tagID, userID).
+		Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return total, nil
+}
+
+func (s *FileStore) DeleteFileTagsByFileID(ctx context.Context, fileID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("file_id = ?", fileID).
+		Delete(&FileTagSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteTagsByUserID(ctx context.Context, userID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&TagSchema{}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
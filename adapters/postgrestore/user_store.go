@@ -13,13 +13,21 @@ import (
 )
 
 type UserStore struct {
-	db *gorm.DB
+	db      *gorm.DB
+	secrets SecretKeys
 }
 
 func NewUserStore(db *gorm.DB) *UserStore {
 	return &UserStore{db: db}
 }
 
+// WithSecretKeys encrypts the secrets of the new access keys with keys.
+func (s *UserStore) WithSecretKeys(keys SecretKeys) *UserStore {
+	s.secrets = keys
+
+	return s
+}
+
 func (s *UserStore) Create(ctx context.Context, user *identity.User) error {
 	userSchema := UserSchema{
 		ID:              user.ID,
@@ -195,6 +203,20 @@ func (s *UserStore) ListByIDs(ctx context.Context, ids []string) ([]identity.Use
 	return users, nil
 }
 
+// ListAvatarsByNames returns the avatar urls ending with one of the asset
+// names, avatars may be stored as relative or absolute urls.
+func (s *UserStore) ListAvatarsByNames(ctx context.Context, names []string) ([]string, error) {
+	var avatars []string
+	if err := s.db.WithContext(ctx).
+		Model(&UserSchema{}).
+		Where("substring(avatar_url from '[^/]*$') IN ?", names).
+		Distinct().Pluck("avatar_url", &avatars).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return avatars, nil
+}
+
 func (s *UserStore) FuzzySearch(ctx context.Context, query string) ([]identity.User, error) {
 	var userSchemas []UserSchema
 
@@ -0,0 +1,206 @@
+package postgrestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *FileStore) CreateWebhook(ctx context.Context, w *file.Webhook) error {
+	webhookSchema := WebhookSchema{
+		ID:          w.ID,
+		UserID:      w.UserID,
+		DirectoryID: w.DirectoryID,
+		URL:         w.URL,
+		Events:      w.Events,
+		Secret:      w.Secret,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&webhookSchema).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	w.CreatedAt = webhookSchema.CreatedAt
+
+	return nil
+}
+
+func (s *FileStore) GetWebhook(ctx context.Context, id uuid.UUID) (*file.Webhook, error) {
+	var webhookSchema WebhookSchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&webhookSchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return webhookSchema.ToDomainWebhook(), nil
+}
+
+func (s *FileStore) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]file.Webhook, error) {
+	var webhookSchemas []WebhookSchema
+
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&webhookSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	webhooks := make([]file.Webhook, len(webhookSchemas))
+	for i, webhookSchema := range webhookSchemas {
+		webhooks[i] = *webhookSchema.ToDomainWebhook()
+	}
+
+	return webhooks, nil
+}
+
+// ListWebhooksByDirectories lists the webhooks of the directories at
+// fullPaths, the directories are matched by their current path.
+func (s *FileStore) ListWebhooksByDirectories(ctx context.Context, fullPaths []string) ([]file.Webhook, error) {
+	if len(fullPaths) == 0 {
+		return nil, nil
+	}
+
+	var webhookSchemas []WebhookSchema
+
+	dirs := make([][]interface{}, len(fullPaths))
+	for i, fullPath := range fullPaths {
+		path, name := filepath.Split(fullPath)
+		dirs[i] = []interface{}{filepath.Clean(path), name}
+	}
+
+	if err := s.db.WithContext(ctx).
+		Select("webhooks.*").
+		Joins("JOIN files ON files.id = webhooks.directory_id").
+		Where("(files.path, files.name) IN ?", dirs).
+		Where("files.finished_at IS NOT NULL").
+		Find(&webhookSchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	webhooks := make([]file.Webhook, len(webhookSchemas))
+	for i, webhookSchema := range webhookSchemas {
+		webhooks[i] = *webhookSchema.ToDomainWebhook()
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook id of the user with its delivery log.
+func (s *FileStore) DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", id).Delete(&WebhookSchema{})
+	if result.Error != nil {
+		return fmt.Errorf("unexpected error: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return file.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *FileStore) CreateWebhookDeliveries(ctx context.Context, deliveries []file.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	deliverySchemas := make([]WebhookDeliverySchema, len(deliveries))
+	for i, d := range deliveries {
+		deliverySchemas[i] = WebhookDeliverySchema{
+			ID:         d.ID,
+			WebhookID:  d.WebhookID,
+			Event:      d.Event,
+			Payload:    d.Payload,
+			Status:     d.Status,
+			Redelivery: d.Redelivery,
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Create(&deliverySchemas).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	for i := range deliveries {
+		deliveries[i].CreatedAt = deliverySchemas[i].CreatedAt
+		deliveries[i].UpdatedAt = deliverySchemas[i].UpdatedAt
+	}
+
+	return nil
+}
+
+func (s *FileStore) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (*file.WebhookDelivery, error) {
+	var deliverySchema WebhookDeliverySchema
+
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&deliverySchema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return deliverySchema.ToDomainWebhookDelivery(), nil
+}
+
+func (s *FileStore) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, cursor *pagination.Cursor) ([]file.WebhookDelivery, error) {
+	var deliverySchemas []WebhookDeliverySchema
+
+	// parse cursor
+	cursorObj, err := pagination.DecodeToken[fsCursor](cursor.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", file.ErrInvalidCursor, err)
+	}
+
+	query := s.db.WithContext(ctx).Where("webhook_id = ?", webhookID)
+	if cursorObj.CreatedAt != nil {
+		query = query.Where("created_at <= ?", cursorObj.CreatedAt)
+	}
+
+	if err := query.Limit(cursor.Limit + 1).Order("created_at DESC").Find(&deliverySchemas).Error; err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	if len(deliverySchemas) > cursor.Limit {
+		cursor.SetNextToken(pagination.EncodeToken(fsCursor{CreatedAt: &deliverySchemas[cursor.Limit].CreatedAt}))
+		deliverySchemas = deliverySchemas[:cursor.Limit]
+	}
+
+	deliveries := make([]file.WebhookDelivery, len(deliverySchemas))
+	for i, deliverySchema := range deliverySchemas {
+		deliveries[i] = *deliverySchema.ToDomainWebhookDelivery()
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery saves the outcome of the last attempt of a delivery.
+func (s *FileStore) UpdateWebhookDelivery(ctx context.Context, d *file.WebhookDelivery) error {
+	d.UpdatedAt = time.Now()
+
+	if err := s.db.WithContext(ctx).
+		Model(&WebhookDeliverySchema{}).
+		Where("id = ?", d.ID).
+		Updates(map[string]interface{}{
+			"status":       d.Status,
+			"attempts":     d.Attempts,
+			"status_code":  d.StatusCode,
+			"error":        d.Error,
+			"delivered_at": d.DeliveredAt,
+			"updated_at":   d.UpdatedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+
+	return nil
+}
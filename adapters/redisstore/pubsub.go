@@ -41,3 +41,32 @@ func (r *RedisPubSub) ReceiveMessage(ctx context.Context) (pubsub.Message, error
 func (r *RedisPubSub) Close() error {
 	return r.rps.Close()
 }
+
+func (r *RedisClient) Append(ctx context.Context, stream string, payload string, maxLen int64) (string, error) {
+	return r.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"payload": payload},
+	}).Result()
+}
+
+func (r *RedisClient) Range(ctx context.Context, stream string, afterID string, count int64) ([]pubsub.Message, error) {
+	msgs, err := r.rdb.XRangeN(ctx, stream, "("+afterID, "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]pubsub.Message, len(msgs))
+	for i, msg := range msgs {
+		payload, _ := msg.Values["payload"].(string)
+
+		messages[i] = pubsub.Message{
+			ID:      msg.ID,
+			Channel: stream,
+			Payload: payload,
+		}
+	}
+
+	return messages, nil
+}
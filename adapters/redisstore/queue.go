@@ -0,0 +1,231 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisQueue is a durable queue on top of a Redis stream. Retries wait in the
+// <queue>:delayed sorted set until they are due, and jobs that keep failing
+// end up in the <queue>:dead stream.
+type RedisQueue struct {
+	rdb   *redis.Client
+	name  string
+	opts  pubsub.QueueOptions
+	block time.Duration
+}
+
+// promoteScript moves up to 100 due retries from the sorted set in KEYS[1] to
+// the stream in KEYS[2]. It runs atomically so a retry is neither lost when a
+// consumer dies halfway nor promoted twice.
+var promoteScript = redis.NewScript(`
+local members = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, member in ipairs(members) do
+	local job = cjson.decode(member)
+	redis.call('XADD', KEYS[2], '*', 'payload', job.payload, 'attempts', job.attempts)
+	redis.call('ZREM', KEYS[1], member)
+end
+return #members
+`)
+
+type delayedJob struct {
+	ID       string `json:"id"`
+	Payload  string `json:"payload"`
+	Attempts int    `json:"attempts"`
+}
+
+func (r *RedisClient) Enqueue(ctx context.Context, queue string, payload string) error {
+	return r.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: queue,
+		Values: map[string]interface{}{"payload": payload, "attempts": 0},
+	}).Err()
+}
+
+func (r *RedisClient) Consume(ctx context.Context, queue string, opts pubsub.QueueOptions) (pubsub.Queue, error) {
+	// start from the beginning of the stream so jobs enqueued before the group
+	// existed are not lost
+	if err := r.rdb.XGroupCreateMkStream(ctx, queue, opts.Group, "0").Err(); err != nil &&
+		!strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("create consumer group: %w", err)
+	}
+
+	return &RedisQueue{rdb: r.rdb, name: queue, opts: opts, block: time.Second}, nil
+}
+
+func (q *RedisQueue) Receive(ctx context.Context) (pubsub.Job, error) {
+	for {
+		if err := q.promote(ctx); err != nil {
+			return pubsub.Job{}, err
+		}
+
+		// take over the jobs of consumers that died while processing them
+		if q.opts.VisibilityTimeout > 0 {
+			msgs, _, err := q.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   q.name,
+				Group:    q.opts.Group,
+				Consumer: q.opts.Consumer,
+				MinIdle:  q.opts.VisibilityTimeout,
+				Start:    "0-0",
+				Count:    1,
+			}).Result()
+			if err != nil {
+				return pubsub.Job{}, fmt.Errorf("claim jobs: %w", err)
+			}
+
+			if len(msgs) > 0 {
+				job, err := q.claimed(ctx, msgs[0])
+				if err != nil {
+					return pubsub.Job{}, err
+				}
+
+				if job.Attempts < q.opts.MaxAttempts {
+					return job, nil
+				}
+
+				if err := q.DeadLetter(ctx, job, errors.New("visibility timeout expired")); err != nil {
+					return pubsub.Job{}, fmt.Errorf("dead-letter job: %w", err)
+				}
+
+				continue
+			}
+		}
+
+		streams, err := q.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.opts.Group,
+			Consumer: q.opts.Consumer,
+			Streams:  []string{q.name, ">"},
+			Count:    1,
+			Block:    q.block,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+
+			return pubsub.Job{}, fmt.Errorf("read jobs: %w", err)
+		}
+
+		for _, stream := range streams {
+			if len(stream.Messages) > 0 {
+				return q.job(stream.Messages[0]), nil
+			}
+		}
+	}
+}
+
+func (q *RedisQueue) Ack(ctx context.Context, job pubsub.Job) error {
+	_, err := q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.name, q.opts.Group, job.ID)
+		pipe.XDel(ctx, q.name, job.ID)
+
+		return nil
+	})
+
+	return err
+}
+
+func (q *RedisQueue) Retry(ctx context.Context, job pubsub.Job, cause error) error {
+	attempts := job.Attempts + 1
+	if attempts >= q.opts.MaxAttempts {
+		return q.DeadLetter(ctx, job, cause)
+	}
+
+	b, err := json.Marshal(delayedJob{ID: job.ID, Payload: job.Payload, Attempts: attempts})
+	if err != nil {
+		return fmt.Errorf("marshal job: %w", err)
+	}
+
+	due := time.Now().Add(q.opts.RetryAfter(attempts))
+
+	_, err = q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, q.delayed(), redis.Z{Score: float64(due.UnixMilli()), Member: string(b)})
+		pipe.XAck(ctx, q.name, q.opts.Group, job.ID)
+		pipe.XDel(ctx, q.name, job.ID)
+
+		return nil
+	})
+
+	return err
+}
+
+func (q *RedisQueue) DeadLetter(ctx context.Context, job pubsub.Job, cause error) error {
+	reason := ""
+	if cause != nil {
+		reason = cause.Error()
+	}
+
+	_, err := q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.name + ":dead",
+			Values: map[string]interface{}{
+				"payload":  job.Payload,
+				"attempts": job.Attempts + 1,
+				"error":    reason,
+			},
+		})
+		pipe.XAck(ctx, q.name, q.opts.Group, job.ID)
+		pipe.XDel(ctx, q.name, job.ID)
+
+		return nil
+	})
+
+	return err
+}
+
+// promote moves the retries that are due back to the stream.
+func (q *RedisQueue) promote(ctx context.Context) error {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := promoteScript.Run(ctx, q.rdb, []string{q.delayed(), q.name}, now).Err(); err != nil {
+		return fmt.Errorf("promote delayed jobs: %w", err)
+	}
+
+	return nil
+}
+
+// claimed returns a job taken over from another consumer. The consumers that
+// died while processing it never retried it, so every earlier delivery counts
+// as a failed attempt.
+func (q *RedisQueue) claimed(ctx context.Context, msg redis.XMessage) (pubsub.Job, error) {
+	job := q.job(msg)
+
+	pending, err := q.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: q.name,
+		Group:  q.opts.Group,
+		Start:  msg.ID,
+		End:    msg.ID,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return pubsub.Job{}, fmt.Errorf("get pending job: %w", err)
+	}
+
+	if len(pending) > 0 && pending[0].RetryCount > 1 {
+		job.Attempts += int(pending[0].RetryCount) - 1
+	}
+
+	return job, nil
+}
+
+func (q *RedisQueue) delayed() string {
+	return q.name + ":delayed"
+}
+
+func (q *RedisQueue) job(msg redis.XMessage) pubsub.Job {
+	payload, _ := msg.Values["payload"].(string)
+	attempts, _ := strconv.Atoi(fmt.Sprint(msg.Values["attempts"]))
+
+	return pubsub.Job{
+		ID:       msg.ID,
+		Queue:    q.name,
+		Payload:  payload,
+		Attempts: attempts,
+	}
+}
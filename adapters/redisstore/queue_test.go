@@ -0,0 +1,219 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testQueue = "jobs"
+
+func newTestClient(t *testing.T) *RedisClient {
+	t.Helper()
+
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return NewRedisClient(rdb)
+}
+
+func newTestQueue(t *testing.T, r *RedisClient, consumer string, opts pubsub.QueueOptions) *RedisQueue {
+	t.Helper()
+
+	opts.Group, opts.Consumer = "workers", consumer
+
+	q, err := r.Consume(context.Background(), testQueue, opts)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	rq := q.(*RedisQueue)
+	rq.block = 10 * time.Millisecond
+
+	return rq
+}
+
+// attemptsOf returns the attempts of the messages of a stream.
+func attemptsOf(t *testing.T, r *RedisClient, stream string) []int {
+	t.Helper()
+
+	msgs, err := r.rdb.XRange(context.Background(), stream, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange(%s) error = %v", stream, err)
+	}
+
+	attempts := make([]int, len(msgs))
+	for i, msg := range msgs {
+		attempts[i], _ = strconv.Atoi(msg.Values["attempts"].(string))
+	}
+
+	return attempts
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		delayed  int
+		dead     []int
+	}{
+		{"first failure", 0, 1, []int{}},
+		{"before the last attempt", 1, 1, []int{}},
+		{"last attempt", 2, 0, []int{3}},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		r := newTestClient(t)
+		q := newTestQueue(t, r, "a", pubsub.QueueOptions{MaxAttempts: 3, Backoff: time.Minute})
+
+		if err := r.Enqueue(ctx, testQueue, "payload"); err != nil {
+			t.Fatalf("%s: Enqueue() error = %v", tt.name, err)
+		}
+
+		job, err := q.Receive(ctx)
+		if err != nil {
+			t.Fatalf("%s: Receive() error = %v", tt.name, err)
+		}
+
+		job.Attempts = tt.attempts
+		if err := q.Retry(ctx, job, errors.New("failed")); err != nil {
+			t.Fatalf("%s: Retry() error = %v", tt.name, err)
+		}
+
+		if n := len(attemptsOf(t, r, testQueue)); n != 0 {
+			t.Errorf("%s: %d jobs left in the queue; want 0", tt.name, n)
+		}
+
+		members, err := r.rdb.ZRange(ctx, q.delayed(), 0, -1).Result()
+		if err != nil {
+			t.Fatalf("%s: ZRange() error = %v", tt.name, err)
+		}
+
+		if len(members) != tt.delayed {
+			t.Errorf("%s: %d delayed jobs; want %d", tt.name, len(members), tt.delayed)
+		}
+
+		for _, member := range members {
+			var delayed delayedJob
+			if err := json.Unmarshal([]byte(member), &delayed); err != nil {
+				t.Fatalf("%s: unmarshal delayed job error = %v", tt.name, err)
+			}
+
+			if delayed.Payload != "payload" || delayed.Attempts != tt.attempts+1 {
+				t.Errorf("%s: delayed job = %+v; want payload with %d attempts", tt.name, delayed, tt.attempts+1)
+			}
+		}
+
+		if dead := attemptsOf(t, r, testQueue+":dead"); !slices.Equal(dead, tt.dead) {
+			t.Errorf("%s: dead jobs with attempts %v; want %v", tt.name, dead, tt.dead)
+		}
+	}
+}
+
+func TestPromote(t *testing.T) {
+	ctx := context.Background()
+
+	r := newTestClient(t)
+	q := newTestQueue(t, r, "a", pubsub.QueueOptions{MaxAttempts: 5})
+
+	now := time.Now()
+	jobs := []struct {
+		job delayedJob
+		due time.Time
+	}{
+		{delayedJob{ID: "1-0", Payload: "past", Attempts: 1}, now.Add(-time.Minute)},
+		{delayedJob{ID: "2-0", Payload: "now", Attempts: 2}, now},
+		{delayedJob{ID: "3-0", Payload: "future", Attempts: 1}, now.Add(time.Hour)},
+	}
+
+	for _, j := range jobs {
+		b, _ := json.Marshal(j.job)
+		if err := r.rdb.ZAdd(ctx, q.delayed(), redis.Z{Score: float64(j.due.UnixMilli()), Member: string(b)}).Err(); err != nil {
+			t.Fatalf("ZAdd() error = %v", err)
+		}
+	}
+
+	if err := q.promote(ctx); err != nil {
+		t.Fatalf("promote() error = %v", err)
+	}
+
+	// the due jobs are received in order with their attempts
+	for _, want := range []pubsub.Job{{Payload: "past", Attempts: 1}, {Payload: "now", Attempts: 2}} {
+		job, err := q.Receive(ctx)
+		if err != nil {
+			t.Fatalf("Receive() error = %v", err)
+		}
+
+		if job.Payload != want.Payload || job.Attempts != want.Attempts {
+			t.Errorf("Receive() = %s with %d attempts; want %s with %d", job.Payload, job.Attempts, want.Payload, want.Attempts)
+		}
+	}
+
+	left, err := r.rdb.ZCard(ctx, q.delayed()).Result()
+	if err != nil {
+		t.Fatalf("ZCard() error = %v", err)
+	}
+
+	if left != 1 {
+		t.Errorf("%d delayed jobs left; want 1", left)
+	}
+}
+
+func TestReceiveClaimed(t *testing.T) {
+	const visibility = 50 * time.Millisecond
+
+	ctx := context.Background()
+
+	r := newTestClient(t)
+	opts := pubsub.QueueOptions{MaxAttempts: 3, Backoff: time.Minute, VisibilityTimeout: visibility}
+
+	if err := r.Enqueue(ctx, testQueue, "payload"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	// every consumer dies without acknowledging the job, the next one counts
+	// the earlier deliveries as failed attempts
+	for i, attempts := range []int{0, 1, 2} {
+		q := newTestQueue(t, r, strconv.Itoa(i), opts)
+
+		job, err := q.Receive(ctx)
+		if err != nil {
+			t.Fatalf("Receive() by consumer %d error = %v", i, err)
+		}
+
+		if job.Payload != "payload" || job.Attempts != attempts {
+			t.Errorf("Receive() by consumer %d = %s with %d attempts; want payload with %d", i, job.Payload, job.Attempts, attempts)
+		}
+
+		time.Sleep(visibility + 10*time.Millisecond)
+	}
+
+	// the last delivery used up the attempts, so the job is dead-lettered
+	// instead of being handed out again
+	q := newTestQueue(t, r, "last", opts)
+
+	receiveCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	if job, err := q.Receive(receiveCtx); err == nil {
+		t.Errorf("Receive() = %s with %d attempts; want no job", job.Payload, job.Attempts)
+	}
+
+	if n := len(attemptsOf(t, r, testQueue)); n != 0 {
+		t.Errorf("%d jobs left in the queue; want 0", n)
+	}
+
+	if dead := attemptsOf(t, r, testQueue+":dead"); len(dead) != 1 {
+		t.Errorf("%d dead jobs; want 1", len(dead))
+	}
+}
@@ -0,0 +1,321 @@
+package s3gateway
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signV4Algorithm                 = "AWS4-HMAC-SHA256"
+	unsignedPayload                 = "UNSIGNED-PAYLOAD"
+	streamingPayload                = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	emptySHA256                     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	amzDateFormat = "20060102T150405Z"
+	scopeFormat   = "20060102"
+
+	// maxSkew is how far the time of a request can be from the time of the
+	// server, it limits the replay of captured requests.
+	maxSkew = 15 * time.Minute
+
+	// maxExpires is the longest validity of a presigned URL.
+	maxExpires = 7 * 24 * time.Hour
+)
+
+// signature is the AWS Signature Version 4 of a request, sent in the
+// Authorization header or in the query of a presigned URL.
+type signature struct {
+	accessKeyID   string
+	date          time.Time
+	scope         string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	presigned     bool
+	expires       time.Duration
+}
+
+func parseSignature(r *http.Request) (*signature, error) {
+	var (
+		sig        signature
+		credential string
+		query      = r.URL.Query()
+		header     = r.Header.Get("Authorization")
+	)
+
+	switch {
+	case strings.HasPrefix(header, signV4Algorithm+" "):
+		for _, field := range strings.Split(strings.TrimPrefix(header, signV4Algorithm+" "), ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+			switch name {
+			case "Credential":
+				credential = value
+			case "SignedHeaders":
+				sig.signedHeaders = strings.Split(value, ";")
+			case "Signature":
+				sig.signature = value
+			}
+		}
+
+		date, err := parseRequestDate(r.Header.Get("X-Amz-Date"), r.Header.Get("Date"))
+		if err != nil {
+			return nil, err
+		}
+
+		sig.date = date
+	case query.Get("X-Amz-Algorithm") == signV4Algorithm:
+		credential = query.Get("X-Amz-Credential")
+		sig.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+		sig.signature = query.Get("X-Amz-Signature")
+		sig.presigned = true
+
+		date, err := time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			return nil, errAccessDenied.withMessage("X-Amz-Date must be in the ISO8601 Long Format")
+		}
+
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxExpires {
+			return nil, errAccessDenied.withMessage("X-Amz-Expires must be between 0 and 604800 seconds")
+		}
+
+		sig.date = date
+		sig.expires = time.Duration(expires) * time.Second
+	case header != "":
+		return nil, errAccessDenied.withMessage("Only AWS Signature Version 4 is supported")
+	default:
+		return nil, errAccessDenied.withMessage("Anonymous access is not allowed")
+	}
+
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" || parts[3] != "s3" {
+		return nil, errAccessDenied.withMessage("The credential of the request is malformed")
+	}
+
+	if parts[1] != sig.date.Format(scopeFormat) {
+		return nil, errSignatureDoesNotMatch.withMessage("The date of the credential does not match the date of the request")
+	}
+
+	if sig.signature == "" || len(sig.signedHeaders) == 0 || !contains(sig.signedHeaders, "host") {
+		return nil, errAccessDenied.withMessage("The signature of the request is malformed")
+	}
+
+	sig.accessKeyID = parts[0]
+	sig.region = parts[2]
+	sig.service = parts[3]
+	sig.scope = strings.Join(parts[1:], "/")
+
+	return &sig, nil
+}
+
+func parseRequestDate(amzDate string, date string) (time.Time, error) {
+	if amzDate != "" {
+		t, err := time.Parse(amzDateFormat, amzDate)
+		if err != nil {
+			return time.Time{}, errAccessDenied.withMessage("X-Amz-Date must be in the ISO8601 Long Format")
+		}
+
+		return t, nil
+	}
+
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, errAccessDenied.withMessage("AWS authentication requires a valid Date or x-amz-date header")
+	}
+
+	return t, nil
+}
+
+// checkTime rejects the requests signed too long ago, or presigned URLs that
+// expired.
+func (sig *signature) checkTime(now time.Time) error {
+	if sig.date.After(now.Add(maxSkew)) {
+		return errRequestTimeTooSkewed
+	}
+
+	if sig.presigned {
+		if now.After(sig.date.Add(sig.expires)) {
+			return errRequestExpired
+		}
+
+		return nil
+	}
+
+	if now.Sub(sig.date) > maxSkew {
+		return errRequestTimeTooSkewed
+	}
+
+	return nil
+}
+
+// signingKey derives the key signing the requests of the day of the scope.
+func (sig *signature) signingKey(secret string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), sig.date.Format(scopeFormat))
+	key = hmacSHA256(key, sig.region)
+	key = hmacSHA256(key, sig.service)
+
+	return hmacSHA256(key, "aws4_request")
+}
+
+// verify compares the signature of the request with the one computed with
+// key.
+func (sig *signature) verify(r *http.Request, key []byte, payloadHash string) error {
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		sig.date.Format(amzDateFormat),
+		sig.scope,
+		sha256Hex([]byte(canonicalRequest(r, sig, payloadHash))),
+	}, "\n")
+
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(sig.signature)) {
+		return errSignatureDoesNotMatch
+	}
+
+	return nil
+}
+
+func canonicalRequest(r *http.Request, sig *signature, payloadHash string) string {
+	query := r.URL.Query()
+	if sig.presigned {
+		query.Del("X-Amz-Signature")
+	}
+
+	uri := r.URL.Path
+	if uri == "" {
+		uri = "/"
+	}
+
+	return strings.Join([]string{
+		r.Method,
+		uriEncode(uri, false),
+		canonicalQuery(query),
+		canonicalHeaders(r, sig.signedHeaders),
+		strings.Join(sig.signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders lists the signed headers, each line ends with a newline.
+// The values are trimmed in a copy, the request is left untouched.
+func canonicalHeaders(r *http.Request, names []string) string {
+	var b strings.Builder
+
+	for _, name := range names {
+		var values []string
+
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		case "transfer-encoding":
+			values = r.TransferEncoding
+		default:
+			values = r.Header.Values(name)
+		}
+
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(trimmed, ","))
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// uriEncode escapes s like the S3 clients do, every byte but the unreserved
+// characters is escaped, and the slash too when slash is true.
+func uriEncode(s string, slash bool) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !slash:
+			b.WriteByte(c)
+		default:
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashReader fails with err at the end of r when its hash differs from sum.
+type hashReader struct {
+	r   io.Reader
+	h   hash.Hash
+	sum []byte
+	err error
+}
+
+func newHashReader(r io.Reader, h hash.Hash, sum []byte, err error) *hashReader {
+	return &hashReader{r: r, h: h, sum: sum, err: err}
+}
+
+func (hr *hashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+
+	if err == io.EOF && !bytes.Equal(hr.h.Sum(nil), hr.sum) {
+		return n, hr.err
+	}
+
+	return n, err
+}
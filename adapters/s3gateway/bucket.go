@@ -0,0 +1,287 @@
+package s3gateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+)
+
+const (
+	// maxKeys is the largest page of a listing.
+	maxKeys = 1000
+
+	// pageSize is the number of children listed at once.
+	pageSize = 1000
+)
+
+// entry is a key of a bucket, with the file or directory it maps to.
+type entry struct {
+	key string
+	f   *file.File
+}
+
+// listBuckets lists the root of the user and the directories shared with
+// them.
+func (g *Gateway) listBuckets(c *call) error {
+	ids := []string{c.user.RootID.String()}
+
+	for _, relation := range []string{"editors", "viewers"} {
+		shared, err := g.PermissionService.GetSharedPermissions(c.ctx, c.user.ID.String(), "Directory", relation)
+		if err != nil {
+			return fmt.Errorf("get shared permissions: %w", err)
+		}
+
+		ids = append(ids, shared...)
+	}
+
+	files, err := g.FileStore.ListByIDs(c.ctx, ids)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	result := listAllMyBucketsResult{
+		Xmlns: xmlns,
+		Owner: owner{ID: c.user.ID.String(), DisplayName: c.user.Email},
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if !f.IsDir || inTrash(&f) || seen[f.ID.String()] {
+			continue
+		}
+
+		seen[f.ID.String()] = true
+		result.Buckets = append(result.Buckets, bucket{Name: f.ID.String(), CreationDate: timestamp(f.CreatedAt)})
+	}
+
+	return g.writeXML(c, http.StatusOK, result)
+}
+
+// getBucketLocation reports the default region, the clients sign the
+// requests for any region.
+func (g *Gateway) getBucketLocation(c *call) error {
+	return g.writeXML(c, http.StatusOK, locationConstraint{Xmlns: xmlns})
+}
+
+// listObjects serves both versions of ListObjects, they only differ by how
+// the next page is requested.
+func (g *Gateway) listObjects(c *call, b *file.File) error {
+	var (
+		query     = c.r.URL.Query()
+		v2        = query.Get("list-type") == "2"
+		prefix    = query.Get("prefix")
+		delimiter = query.Get("delimiter")
+		encoding  = query.Get("encoding-type")
+		limit     = maxKeys
+		marker    string
+	)
+
+	if encoding != "" && encoding != "url" {
+		return errInvalidArgument.withMessage("Invalid Encoding Method specified in Request")
+	}
+
+	if s := query.Get("max-keys"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return errInvalidArgument.withMessage("Provided max-keys not an integer or within integer range")
+		}
+
+		limit = min(n, maxKeys)
+	}
+
+	if v2 {
+		marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return errInvalidArgument.withMessage("The continuation token provided is incorrect")
+			}
+
+			marker = string(decoded)
+		}
+	} else {
+		marker = query.Get("marker")
+	}
+
+	encode := func(s string) string {
+		if encoding == "url" {
+			return uriEncode(s, false)
+		}
+
+		return s
+	}
+
+	entries, err := g.listEntries(c, b, prefix, delimiter)
+	if err != nil {
+		return err
+	}
+
+	result := listBucketResult{
+		Xmlns:        xmlns,
+		Name:         b.ID.String(),
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      limit,
+		EncodingType: encoding,
+	}
+
+	var (
+		last  string
+		count int
+	)
+
+	for _, e := range entries {
+		key, isPrefix := e.key, false
+
+		// the keys sharing a common prefix are next to each other
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				key, isPrefix = key[:len(prefix)+i+len(delimiter)], true
+			}
+		}
+
+		if key <= marker || (isPrefix && key == last) {
+			continue
+		}
+
+		if count == limit {
+			result.IsTruncated = true
+			break
+		}
+
+		count++
+		last = key
+
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(key)})
+			continue
+		}
+
+		o := object{
+			Key:          encode(key),
+			LastModified: timestamp(e.f.UpdatedAt),
+			ETag:         etag(e.f),
+			Size:         e.f.Size,
+			StorageClass: "STANDARD",
+		}
+
+		if !v2 || query.Get("fetch-owner") == "true" {
+			o.Owner = &owner{ID: e.f.OwnerID.String()}
+		}
+
+		result.Contents = append(result.Contents, o)
+	}
+
+	if v2 {
+		result.KeyCount = &count
+		result.StartAfter = encode(query.Get("start-after"))
+		result.ContinuationToken = query.Get("continuation-token")
+
+		if result.IsTruncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+		}
+	} else {
+		marker = encode(marker)
+		result.Marker = &marker
+
+		if result.IsTruncated {
+			result.NextMarker = encode(last)
+		}
+	}
+
+	return g.writeXML(c, http.StatusOK, result)
+}
+
+// listEntries returns the keys of b starting with prefix, sorted. With the
+// slash as delimiter, only the directory of the prefix is listed since its
+// subdirectories are common prefixes.
+func (g *Gateway) listEntries(c *call, b *file.File, prefix string, delimiter string) ([]entry, error) {
+	dir := b
+
+	if dirKey := prefix[:strings.LastIndex(prefix, "/")+1]; dirKey != "" {
+		if !validKey(dirKey) || hidden(b, dirKey) {
+			return nil, nil
+		}
+
+		f, err := g.FileStore.GetByFullPath(c.ctx, path.Join(b.FullPath(), dirKey))
+		if err != nil {
+			if errors.Is(err, file.ErrNotFound) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		if !f.IsDir {
+			return nil, nil
+		}
+
+		dir = f
+	}
+
+	var (
+		files []file.File
+		err   error
+	)
+
+	if delimiter == "/" {
+		files, err = g.children(c, dir)
+	} else {
+		files, err = g.FileStore.ListChildren(c.ctx, dir)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("list children: %w", err)
+	}
+
+	entries := make([]entry, 0, len(files))
+	for i := range files {
+		f := &files[i]
+
+		key := strings.TrimPrefix(f.FullPath(), b.FullPath()+"/")
+		if f.IsDir {
+			key += "/"
+		}
+
+		if !strings.HasPrefix(key, prefix) || hidden(b, key) {
+			continue
+		}
+
+		entries = append(entries, entry{key: key, f: f})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	return entries, nil
+}
+
+// children lists the entries of the directory f, the trash excluded.
+func (g *Gateway) children(c *call, f *file.File) ([]file.File, error) {
+	var (
+		files  []file.File
+		cursor = pagination.NewCursor("", pageSize)
+	)
+
+	for {
+		page, err := g.FileStore.ListCursor(c.ctx, f.FullPath(), cursor, file.Filter{})
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, page...)
+
+		if cursor.NextToken() == "" {
+			return files, nil
+		}
+
+		cursor = pagination.NewCursor(cursor.NextToken(), pageSize)
+	}
+}
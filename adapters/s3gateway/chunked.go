@@ -0,0 +1,152 @@
+package s3gateway
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxChunkSize bounds the memory used to verify a chunk, the clients send
+// chunks of 64 KiB to a few MiB.
+const maxChunkSize = 16 << 20
+
+var errMalformedChunk = errIncompleteBody.withMessage("The chunked encoding of the body is malformed.")
+
+// chunkSigner verifies the signature of the chunks of a streaming upload,
+// each signature covers the previous one starting from the signature of the
+// request.
+type chunkSigner struct {
+	key   []byte
+	date  string
+	scope string
+	prev  string
+}
+
+func (s *chunkSigner) verify(signature string, data []byte) bool {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-PAYLOAD",
+		s.date,
+		s.scope,
+		s.prev,
+		emptySHA256,
+		sha256Hex(data),
+	}, "\n")
+
+	expected := hex.EncodeToString(hmacSHA256(s.key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return false
+	}
+
+	s.prev = expected
+
+	return true
+}
+
+// chunkedReader decodes an aws-chunked body of size bytes. The chunks are
+// verified by signer when it is set, the trailers are ignored.
+type chunkedReader struct {
+	r      *bufio.Reader
+	signer *chunkSigner
+	size   int64
+	read   int64
+
+	buf []byte
+	err error
+}
+
+func newChunkedReader(r io.Reader, signer *chunkSigner, size int64) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r), signer: signer, size: size}
+}
+
+func (cr *chunkedReader) Read(p []byte) (int, error) {
+	for len(cr.buf) == 0 {
+		if cr.err != nil {
+			return 0, cr.err
+		}
+
+		cr.err = cr.next()
+	}
+
+	n := copy(p, cr.buf)
+	cr.buf = cr.buf[n:]
+
+	return n, nil
+}
+
+// next reads the next chunk into buf.
+func (cr *chunkedReader) next() error {
+	line, err := cr.readLine()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errIncompleteBody
+		}
+
+		return err
+	}
+
+	sizeHex, params, _ := strings.Cut(line, ";")
+
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeHex), 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize || cr.read+size > cr.size {
+		return errMalformedChunk
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(cr.r, data); err != nil {
+		return errIncompleteBody
+	}
+
+	if cr.signer != nil {
+		signature, ok := strings.CutPrefix(strings.TrimSpace(params), "chunk-signature=")
+		if !ok || !cr.signer.verify(signature, data) {
+			return errSignatureDoesNotMatch
+		}
+	}
+
+	cr.read += size
+
+	if size > 0 {
+		if line, err := cr.readLine(); err != nil || line != "" {
+			return errMalformedChunk
+		}
+
+		cr.buf = data
+
+		return nil
+	}
+
+	// the trailers, if any, end with an empty line
+	for {
+		line, err := cr.readLine()
+		if errors.Is(err, io.EOF) || line == "" {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if cr.read != cr.size {
+		return errIncompleteBody
+	}
+
+	return io.EOF
+}
+
+func (cr *chunkedReader) readLine() (string, error) {
+	line, err := cr.r.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return "", io.EOF
+		}
+
+		return "", errMalformedChunk
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
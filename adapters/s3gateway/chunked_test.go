@@ -0,0 +1,89 @@
+package s3gateway
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// the streaming upload example of the Signature Version 4 documentation of S3
+const (
+	testSeedSignature = "4f232c4386841ef735655705268965c44a0e4690baa4adea153f7db9fa80a0a9"
+	testChunk1        = "ad80c730a21e5b8d04586a2213dd63b9a0e99e0e2307b0ade35a65485a288648"
+	testChunk2        = "0055627c9e194cb4542bae2aa5492e3c1575bbb81b612b7d234b86a503ef5497"
+	testChunkFinal    = "b6c6ea8a5354eaf15b3cb7646744f4275b71ea724fed81ceb9323e279d449df9"
+	testDecodedSize   = 66560
+)
+
+type testChunk struct {
+	size      int
+	signature string
+}
+
+func chunkedBody(chunks ...testChunk) string {
+	var b strings.Builder
+
+	for _, c := range chunks {
+		fmt.Fprintf(&b, "%x", c.size)
+		if c.signature != "" {
+			b.WriteString(";chunk-signature=" + c.signature)
+		}
+
+		b.WriteString("\r\n" + strings.Repeat("a", c.size) + "\r\n")
+	}
+
+	return b.String()
+}
+
+func TestChunkedReader(t *testing.T) {
+	var (
+		signed   = chunkedBody(testChunk{65536, testChunk1}, testChunk{1024, testChunk2}, testChunk{0, testChunkFinal})
+		unsigned = chunkedBody(testChunk{65536, ""}, testChunk{1024, ""}, testChunk{0, ""})
+	)
+
+	tests := []struct {
+		name   string
+		body   string
+		signed bool
+		size   int64
+		err    error
+	}{
+		{"signed", signed, true, testDecodedSize, nil},
+		{"unsigned", unsigned, false, testDecodedSize, nil},
+		{"unsigned with trailers", strings.TrimSuffix(unsigned, "\r\n") + "x-amz-checksum-crc32:AAAAAA==\r\n\r\n", false, testDecodedSize, nil},
+		{"empty body", chunkedBody(testChunk{0, ""}), false, 0, nil},
+		{"truncated data", signed[:1000], true, testDecodedSize, errIncompleteBody},
+		{"truncated before the last chunk", chunkedBody(testChunk{65536, testChunk1}, testChunk{1024, testChunk2}), true, testDecodedSize, errIncompleteBody},
+		{"shorter than declared", unsigned, false, testDecodedSize + 1, errIncompleteBody},
+		{"longer than declared", unsigned, false, testDecodedSize - 1, errMalformedChunk},
+		{"chunk over the limit", fmt.Sprintf("%x\r\n", maxChunkSize+1), false, maxChunkSize + 1, errMalformedChunk},
+		{"negative size", "-1\r\n", false, testDecodedSize, errMalformedChunk},
+		{"invalid size", "zz\r\n", false, testDecodedSize, errMalformedChunk},
+		{"missing line end", strings.Replace(unsigned, "a\r\n400", "a400", 1), false, testDecodedSize, errMalformedChunk},
+		{"wrong signature", strings.Replace(signed, testChunk2, testChunk1, 1), true, testDecodedSize, errSignatureDoesNotMatch},
+		{"missing signature", chunkedBody(testChunk{65536, testChunk1}, testChunk{1024, ""}, testChunk{0, testChunkFinal}), true, testDecodedSize, errSignatureDoesNotMatch},
+		{"reordered chunks", chunkedBody(testChunk{1024, testChunk2}, testChunk{65536, testChunk1}, testChunk{0, testChunkFinal}), true, testDecodedSize, errSignatureDoesNotMatch},
+		{"tampered data", strings.Replace(signed, "aaaa\r\n0;", "aaab\r\n0;", 1), true, testDecodedSize, errSignatureDoesNotMatch},
+	}
+
+	for _, tt := range tests {
+		var signer *chunkSigner
+		if tt.signed {
+			sig := testSignature(nil, testSeedSignature)
+			signer = &chunkSigner{key: sig.signingKey(testSecret), date: testAmzDate, scope: sig.scope, prev: testSeedSignature}
+		}
+
+		got, err := io.ReadAll(newChunkedReader(strings.NewReader(tt.body), signer, tt.size))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: ReadAll() error = %v; want %v", tt.name, err, tt.err)
+			continue
+		}
+
+		if tt.err == nil && !bytes.Equal(got, bytes.Repeat([]byte("a"), int(tt.size))) {
+			t.Errorf("%s: ReadAll() returned %d bytes; want %d", tt.name, len(got), tt.size)
+		}
+	}
+}
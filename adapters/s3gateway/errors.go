@@ -0,0 +1,51 @@
+package s3gateway
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// apiError is an error of the S3 API, Code is the code understood by the
+// clients.
+type apiError struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// withMessage returns a copy of e with a more specific message.
+func (e *apiError) withMessage(message string) *apiError {
+	return &apiError{Code: e.Code, Message: message, Status: e.Status}
+}
+
+var (
+	errAccessDenied          = &apiError{"AccessDenied", "Access Denied", http.StatusForbidden}
+	errBadDigest             = &apiError{"BadDigest", "The Content-MD5 you specified did not match what was received.", http.StatusBadRequest}
+	errContentSHA256Mismatch = &apiError{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	errIncompleteBody        = &apiError{"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", http.StatusBadRequest}
+	errInternalError         = &apiError{"InternalError", "We encountered an internal error, please try again.", http.StatusInternalServerError}
+	errInvalidAccessKeyID    = &apiError{"InvalidAccessKeyId", "The access key ID you provided does not exist in our records.", http.StatusForbidden}
+	errInvalidArgument       = &apiError{"InvalidArgument", "Invalid Argument", http.StatusBadRequest}
+	errInvalidBucketName     = &apiError{"InvalidBucketName", "The specified bucket is not valid.", http.StatusBadRequest}
+	errInvalidDigest         = &apiError{"InvalidDigest", "The Content-MD5 you specified is not valid.", http.StatusBadRequest}
+	errInvalidPart           = &apiError{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	errInvalidPartOrder      = &apiError{"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	errInvalidRequest        = &apiError{"InvalidRequest", "Invalid Request", http.StatusBadRequest}
+	errMalformedXML          = &apiError{"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.", http.StatusBadRequest}
+	errMethodNotAllowed      = &apiError{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	errMissingContentLength  = &apiError{"MissingContentLength", "You must provide the Content-Length HTTP header.", http.StatusLengthRequired}
+	errMissingSecurityHeader = &apiError{"MissingSecurityHeader", "Your request is missing a required header.", http.StatusBadRequest}
+	errNoSuchBucket          = &apiError{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	errNoSuchKey             = &apiError{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	errNoSuchUpload          = &apiError{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	errNotImplemented        = &apiError{"NotImplemented", "A header or query you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	errQuotaExceeded         = &apiError{"QuotaExceeded", "The storage capacity of the owner is exceeded.", http.StatusForbidden}
+	errRequestExpired        = &apiError{"AccessDenied", "Request has expired", http.StatusForbidden}
+	errRequestTimeTooSkewed  = &apiError{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	errSignatureDoesNotMatch = &apiError{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	errEntityTooLarge        = &apiError{"EntityTooLarge", "Your proposed upload exceeds the maximum allowed size.", http.StatusBadRequest}
+)
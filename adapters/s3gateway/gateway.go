@@ -0,0 +1,451 @@
+// Package s3gateway serves the files of the users over a subset of the S3
+// API, so that the S3 clients and SDKs can be used with SeaCloudHub.
+//
+// A bucket is a directory the user can view, named after its ID: the root of
+// the user and the directories shared with them are listed as the buckets.
+// The keys are the paths relative to the bucket, a key ending with a slash
+// is a directory. Only path-style addressing is supported. The requests are
+// signed with an access key of the user and authorized like the file API.
+package s3gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// unsupported lists the subresources of buckets and objects that are not
+// implemented, the request would otherwise be served as a plain one.
+var unsupported = []string{
+	"accelerate", "acl", "analytics", "attributes", "cors", "encryption", "intelligent-tiering", "inventory",
+	"legal-hold", "lifecycle", "logging", "metrics", "notification", "object-lock", "ownershipControls",
+	"policy", "policyStatus", "publicAccessBlock", "replication", "requestPayment", "restore", "retention",
+	"select", "tagging", "torrent", "uploads", "versioning", "versions", "website",
+}
+
+type Gateway struct {
+	FileStore         file.Store
+	UserStore         identity.Store
+	FileService       file.Service
+	PermissionService permission.Service
+	PubSubService     pubsub.Service
+	Logger            *zap.SugaredLogger
+}
+
+// call is an authenticated request.
+type call struct {
+	w   http.ResponseWriter
+	r   *http.Request
+	ctx context.Context
+
+	user *identity.User
+
+	// body is the verified payload of size bytes, or of unknown size when
+	// size is negative.
+	body io.Reader
+	size int64
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := uuid.NewString()
+	w.Header().Set("x-amz-request-id", requestID)
+
+	c, err := g.authenticate(w, r)
+	if err == nil {
+		err = g.route(c)
+	}
+
+	if err != nil {
+		g.error(w, r, requestID, err)
+	}
+}
+
+func (g *Gateway) route(c *call) error {
+	query := c.r.URL.Query()
+	for _, name := range unsupported {
+		// the uploads of a bucket cannot be listed, but an object can be
+		// uploaded in parts
+		if query.Has(name) && !(name == "uploads" && c.r.Method == http.MethodPost) {
+			return errNotImplemented
+		}
+	}
+
+	name, key, _ := strings.Cut(strings.TrimPrefix(c.r.URL.Path, "/"), "/")
+	if name == "" {
+		if c.r.Method != http.MethodGet {
+			return errMethodNotAllowed
+		}
+
+		return g.listBuckets(c)
+	}
+
+	b, err := g.bucket(c, name)
+	if err != nil {
+		return err
+	}
+
+	if key == "" {
+		switch {
+		case c.r.Method == http.MethodGet && query.Has("location"):
+			return g.getBucketLocation(c)
+		case c.r.Method == http.MethodGet:
+			return g.listObjects(c, b)
+		case c.r.Method == http.MethodHead:
+			c.w.WriteHeader(http.StatusOK)
+			return nil
+		case c.r.Method == http.MethodPost && query.Has("delete"):
+			return g.deleteObjects(c, b)
+		case c.r.Method == http.MethodPut, c.r.Method == http.MethodDelete:
+			return errAccessDenied.withMessage("The buckets are the directories of the user, they are managed with the file API")
+		default:
+			return errMethodNotAllowed
+		}
+	}
+
+	if !validKey(key) {
+		return errInvalidArgument.withMessage("The specified key is not valid.")
+	}
+
+	switch {
+	case c.r.Method == http.MethodGet && query.Has("uploadId"):
+		return g.listParts(c, b, key)
+	case c.r.Method == http.MethodGet, c.r.Method == http.MethodHead:
+		return g.getObject(c, b, key)
+	case c.r.Method == http.MethodPut && query.Has("uploadId"):
+		return g.uploadPart(c, b, key)
+	case c.r.Method == http.MethodPut && c.r.Header.Get("X-Amz-Copy-Source") != "":
+		return g.copyObject(c, b, key)
+	case c.r.Method == http.MethodPut:
+		return g.putObject(c, b, key)
+	case c.r.Method == http.MethodPost && query.Has("uploads"):
+		return g.createMultipartUpload(c, b, key)
+	case c.r.Method == http.MethodPost && query.Has("uploadId"):
+		return g.completeMultipartUpload(c, b, key)
+	case c.r.Method == http.MethodDelete && query.Has("uploadId"):
+		return g.abortMultipartUpload(c, b, key)
+	case c.r.Method == http.MethodDelete:
+		return g.deleteObject(c, b, key)
+	default:
+		return errMethodNotAllowed
+	}
+}
+
+// authenticate verifies the signature of the request with the access key it
+// names, and the payload as it is read.
+func (g *Gateway) authenticate(w http.ResponseWriter, r *http.Request) (*call, error) {
+	ctx := r.Context()
+
+	sig, err := parseSignature(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sig.checkTime(time.Now()); err != nil {
+		return nil, err
+	}
+
+	key, err := g.UserStore.GetAccessKey(ctx, sig.accessKeyID)
+	if err != nil {
+		if errors.Is(err, identity.ErrAccessKeyNotFound) {
+			return nil, errInvalidAccessKeyID
+		}
+
+		return nil, err
+	}
+
+	payloadHash := unsignedPayload
+	if !sig.presigned {
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		if payloadHash == "" {
+			return nil, errMissingSecurityHeader.withMessage("Missing required header for this request: x-amz-content-sha256")
+		}
+	}
+
+	signingKey := sig.signingKey(key.SecretKey)
+	if err := sig.verify(r, signingKey, payloadHash); err != nil {
+		return nil, err
+	}
+
+	user, err := g.UserStore.GetByID(ctx, key.UserID.String())
+	if err != nil {
+		if errors.Is(err, identity.ErrIdentityNotFound) {
+			return nil, errInvalidAccessKeyID
+		}
+
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errAccessDenied.withMessage("The account of the access key is disabled")
+	}
+
+	if user.PasswordChangedAt == nil {
+		return nil, errAccessDenied.withMessage("The default password of the account is not changed")
+	}
+
+	if err := g.UserStore.UpdateAccessKeyLastUsedAt(ctx, key.ID); err != nil {
+		g.Logger.Errorw(err.Error(), zap.String("access_key_id", key.AccessKeyID))
+	}
+
+	c := &call{w: w, r: r, ctx: ctx, user: user, body: r.Body, size: r.ContentLength}
+
+	switch payloadHash {
+	case unsignedPayload:
+	case streamingPayload, streamingUnsignedPayloadTrailer:
+		if err := c.decodedSize(); err != nil {
+			return nil, err
+		}
+
+		var signer *chunkSigner
+		if payloadHash == streamingPayload {
+			signer = &chunkSigner{key: signingKey, date: sig.date.Format(amzDateFormat), scope: sig.scope, prev: sig.signature}
+		}
+
+		c.body = newChunkedReader(r.Body, signer, c.size)
+	default:
+		sum, err := hex.DecodeString(payloadHash)
+		if err != nil || len(sum) != 32 {
+			return nil, errNotImplemented.withMessage("The x-amz-content-sha256 of the request is not supported")
+		}
+
+		c.body = newHashReader(r.Body, sha256.New(), sum, errContentSHA256Mismatch)
+	}
+
+	return c, nil
+}
+
+// decodedSize sets the size of an aws-chunked body.
+func (c *call) decodedSize() error {
+	size := c.r.Header.Get("X-Amz-Decoded-Content-Length")
+	if size == "" {
+		return errMissingContentLength
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || n < 0 {
+		return errInvalidArgument.withMessage("The x-amz-decoded-content-length of the request is not valid")
+	}
+
+	c.size = n
+
+	return nil
+}
+
+// bucket returns the directory named name that the user can view.
+func (g *Gateway) bucket(c *call, name string) (*file.File, error) {
+	id, err := uuid.Parse(name)
+	if err != nil {
+		return nil, errNoSuchBucket
+	}
+
+	b, err := g.FileStore.GetByID(c.ctx, id.String())
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, errNoSuchBucket
+		}
+
+		return nil, err
+	}
+
+	if !b.IsDir || inTrash(b) {
+		return nil, errNoSuchBucket
+	}
+
+	ok, err := g.PermissionService.CanViewDirectory(c.ctx, c.user.ID.String(), b.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, errAccessDenied
+	}
+
+	return b, nil
+}
+
+// object returns the file at key in b, key ends with a slash when it is a
+// directory.
+func (g *Gateway) object(c *call, b *file.File, key string) (*file.File, error) {
+	if hidden(b, key) {
+		return nil, errNoSuchKey
+	}
+
+	f, err := g.FileStore.GetByFullPath(c.ctx, path.Join(b.FullPath(), key))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, errNoSuchKey
+		}
+
+		return nil, err
+	}
+
+	if f.IsDir != strings.HasSuffix(key, "/") {
+		return nil, errNoSuchKey
+	}
+
+	return f, nil
+}
+
+func (g *Gateway) canView(c *call, f *file.File) error {
+	var (
+		ok  bool
+		err error
+	)
+
+	if f.IsDir {
+		ok, err = g.PermissionService.CanViewDirectory(c.ctx, c.user.ID.String(), f.ID.String())
+	} else {
+		ok, err = g.PermissionService.CanViewFile(c.ctx, c.user.ID.String(), f.ID.String())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errAccessDenied
+	}
+
+	return nil
+}
+
+func (g *Gateway) canEdit(c *call, dir *file.File) error {
+	ok, err := g.PermissionService.CanEditDirectory(c.ctx, c.user.ID.String(), dir.ID.String())
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errAccessDenied
+	}
+
+	return nil
+}
+
+func (g *Gateway) writeLogs(c *call, logs ...file.Log) {
+	if len(logs) == 0 {
+		return
+	}
+
+	if err := g.FileStore.WriteLogs(c.ctx, logs); err != nil {
+		g.logError(c, err)
+	}
+}
+
+func (g *Gateway) writeChanges(c *call, changes ...file.Change) {
+	if err := services.RecordChanges(c.ctx, g.FileStore, g.PubSubService, changes...); err != nil {
+		g.logError(c, err)
+	}
+}
+
+// applied logs err when the operation was applied but not recorded, which
+// then succeeded.
+func (g *Gateway) applied(c *call, err error) error {
+	if errors.Is(err, services.ErrNotRecorded) {
+		g.logError(c, err)
+		return nil
+	}
+
+	return err
+}
+
+func (g *Gateway) logError(c *call, err error) {
+	g.Logger.Errorw(err.Error(), zap.String("request_id", c.w.Header().Get("x-amz-request-id")))
+}
+
+func (g *Gateway) writeXML(c *call, status int, v interface{}) error {
+	c.w.Header().Set("Content-Type", "application/xml")
+	c.w.WriteHeader(status)
+
+	if _, err := io.WriteString(c.w, xml.Header); err != nil {
+		return nil
+	}
+
+	// the status is sent already, the error cannot be reported
+	_ = xml.NewEncoder(c.w).Encode(v)
+
+	return nil
+}
+
+func (g *Gateway) error(w http.ResponseWriter, r *http.Request, requestID string, err error) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		if errors.Is(err, file.ErrStorageCapacityExceeded) {
+			apiErr = errQuotaExceeded
+		} else {
+			g.Logger.Errorw(err.Error(), zap.String("request_id", requestID))
+			apiErr = errInternalError
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(apiErr.Status)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(errorResponse{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Resource:  r.URL.Path,
+		RequestID: requestID,
+	})
+}
+
+// validKey rejects the keys that would not map to a path of the bucket.
+func validKey(key string) bool {
+	if len(key) > 1024 || strings.ContainsRune(key, 0) {
+		return false
+	}
+
+	segments := strings.Split(strings.TrimSuffix(key, "/"), "/")
+	for _, s := range segments {
+		if s == "" || s == "." || s == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hidden reports whether key is in the trash, which is not part of the root
+// of a user.
+func hidden(b *file.File, key string) bool {
+	return b.Path == "/" && (key == ".trash" || strings.HasPrefix(key, ".trash/"))
+}
+
+func inTrash(f *file.File) bool {
+	return strings.Contains(f.FullPath()+"/", "/.trash/")
+}
+
+// etag returns the quoted MD5 of the content of f, or of its ID when the
+// storage did not report the MD5.
+func etag(f *file.File) string {
+	if f.IsDir {
+		return `"d41d8cd98f00b204e9800998ecf8427e"`
+	}
+
+	if len(f.MD5) == 0 {
+		return `"` + hex.EncodeToString(f.ID[:]) + `"`
+	}
+
+	return `"` + hex.EncodeToString(f.MD5) + `"`
+}
@@ -0,0 +1,374 @@
+package s3gateway
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxParts is the largest part number of a multipart upload.
+	maxParts = 10000
+
+	// maxPartSize is the largest part of a multipart upload.
+	maxPartSize = 5 << 30
+)
+
+func (g *Gateway) createMultipartUpload(c *call, b *file.File, key string) error {
+	if strings.HasSuffix(key, "/") {
+		return errInvalidArgument.withMessage("A key ending with a slash is a directory, it cannot have content")
+	}
+
+	if hidden(b, key) {
+		return errAccessDenied
+	}
+
+	if err := g.canEdit(c, b); err != nil {
+		return err
+	}
+
+	upload := file.NewMultipartUpload(c.user.ID, b.ID, key)
+	if err := g.FileStore.CreateMultipartUpload(c.ctx, upload); err != nil {
+		return fmt.Errorf("create multipart upload: %w", err)
+	}
+
+	return g.writeXML(c, http.StatusOK, initiateMultipartUploadResult{
+		Xmlns:    xmlns,
+		Bucket:   b.ID.String(),
+		Key:      key,
+		UploadID: upload.ID.String(),
+	})
+}
+
+// uploadPart stores a part apart from the files, uploading a part number
+// again replaces it. The size of the part is reserved in the storage of the
+// user first, a replaced part stays reserved until the upload ends.
+func (g *Gateway) uploadPart(c *call, b *file.File, key string) error {
+	if c.r.Header.Get("X-Amz-Copy-Source") != "" {
+		return errNotImplemented
+	}
+
+	number, err := strconv.Atoi(c.r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > maxParts {
+		return errInvalidArgument.withMessage("Part number must be an integer between 1 and 10000, inclusive")
+	}
+
+	if c.size < 0 {
+		return errMissingContentLength
+	}
+
+	if c.size > maxPartSize {
+		return errEntityTooLarge
+	}
+
+	upload, err := g.upload(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	body := c.body
+	if s := c.r.Header.Get("Content-MD5"); s != "" {
+		sum, err := base64.StdEncoding.DecodeString(s)
+		if err != nil || len(sum) != md5.Size {
+			return errInvalidDigest
+		}
+
+		body = newHashReader(body, md5.New(), sum, errBadDigest)
+	}
+
+	if err := g.FileStore.ReserveMultipartStorage(c.ctx, upload.ID, uint64(c.size)); err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return errNoSuchUpload
+		}
+
+		return err
+	}
+
+	h := md5.New()
+
+	size, err := g.FileService.CreateFile(c.ctx, io.TeeReader(body, h), upload.StoragePath(number), "application/octet-stream")
+	if err != nil {
+		if err := g.FileStore.ReleaseMultipartStorage(c.ctx, upload.ID, uint64(c.size)); err != nil {
+			g.logError(c, err)
+		}
+
+		return fmt.Errorf("upload part: %w", err)
+	}
+
+	if size < c.size {
+		if err := g.FileStore.ReleaseMultipartStorage(c.ctx, upload.ID, uint64(c.size-size)); err != nil {
+			g.logError(c, err)
+		}
+	}
+
+	part := &file.UploadPart{
+		UploadID: upload.ID,
+		Number:   number,
+		Size:     uint64(size),
+		MD5:      h.Sum(nil),
+	}
+
+	if err := g.FileStore.UpsertUploadPart(c.ctx, part); err != nil {
+		return fmt.Errorf("upsert upload part: %w", err)
+	}
+
+	c.w.Header().Set("ETag", `"`+hex.EncodeToString(part.MD5)+`"`)
+	c.w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// completeMultipartUpload concatenates the listed parts into the object, the
+// parts are deleted afterwards.
+func (g *Gateway) completeMultipartUpload(c *call, b *file.File, key string) error {
+	upload, err := g.upload(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.body, maxXMLSize))
+	if err != nil {
+		return err
+	}
+
+	var req completeMultipartUpload
+	if err := xml.Unmarshal(data, &req); err != nil || len(req.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	parts, err := g.FileStore.ListUploadParts(c.ctx, upload.ID)
+	if err != nil {
+		return fmt.Errorf("list upload parts: %w", err)
+	}
+
+	byNumber := make(map[int]file.UploadPart, len(parts))
+	for _, p := range parts {
+		byNumber[p.Number] = p
+	}
+
+	var (
+		paths []string
+		size  int64
+	)
+
+	for i, p := range req.Parts {
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			return errInvalidPartOrder
+		}
+
+		part, ok := byNumber[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != hex.EncodeToString(part.MD5) {
+			return errInvalidPart
+		}
+
+		paths = append(paths, upload.StoragePath(p.PartNumber))
+		size += int64(part.Size)
+	}
+
+	r := &partsReader{ctx: c.ctx, fileService: g.FileService, paths: paths}
+	defer r.Close()
+
+	f, err := g.store(c, b, key, r, size, upload.Reserved)
+	if err != nil {
+		return err
+	}
+
+	// the leftovers are collected by the garbage collector
+	if err := g.deleteParts(c, upload, parts); err != nil {
+		g.logError(c, err)
+	}
+
+	return g.writeXML(c, http.StatusOK, completeMultipartUploadResult{
+		Xmlns:    xmlns,
+		Location: "/" + b.ID.String() + "/" + uriEncode(key, false),
+		Bucket:   b.ID.String(),
+		Key:      key,
+		ETag:     etag(f),
+	})
+}
+
+func (g *Gateway) abortMultipartUpload(c *call, b *file.File, key string) error {
+	upload, err := g.upload(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	parts, err := g.FileStore.ListUploadParts(c.ctx, upload.ID)
+	if err != nil {
+		return fmt.Errorf("list upload parts: %w", err)
+	}
+
+	if err := g.deleteParts(c, upload, parts); err != nil {
+		return err
+	}
+
+	c.w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (g *Gateway) listParts(c *call, b *file.File, key string) error {
+	var (
+		query  = c.r.URL.Query()
+		marker int
+		limit  = maxKeys
+	)
+
+	if s := query.Get("part-number-marker"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return errInvalidArgument.withMessage("Provided part-number-marker not an integer or within integer range")
+		}
+
+		marker = n
+	}
+
+	if s := query.Get("max-parts"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return errInvalidArgument.withMessage("Provided max-parts not an integer or within integer range")
+		}
+
+		limit = min(n, maxKeys)
+	}
+
+	upload, err := g.upload(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	parts, err := g.FileStore.ListUploadParts(c.ctx, upload.ID)
+	if err != nil {
+		return fmt.Errorf("list upload parts: %w", err)
+	}
+
+	result := listPartsResult{
+		Xmlns:            xmlns,
+		Bucket:           b.ID.String(),
+		Key:              key,
+		UploadID:         upload.ID.String(),
+		PartNumberMarker: marker,
+		MaxParts:         limit,
+	}
+
+	for _, p := range parts {
+		if p.Number <= marker {
+			continue
+		}
+
+		if len(result.Parts) == limit {
+			result.IsTruncated = true
+			break
+		}
+
+		result.Parts = append(result.Parts, part{
+			PartNumber:   p.Number,
+			LastModified: timestamp(p.UpdatedAt),
+			ETag:         `"` + hex.EncodeToString(p.MD5) + `"`,
+			Size:         p.Size,
+		})
+		result.NextPartNumberMarker = p.Number
+	}
+
+	return g.writeXML(c, http.StatusOK, result)
+}
+
+// upload returns the multipart upload of the query, it must have been
+// started by the user for key of b.
+func (g *Gateway) upload(c *call, b *file.File, key string) (*file.MultipartUpload, error) {
+	id, err := uuid.Parse(c.r.URL.Query().Get("uploadId"))
+	if err != nil {
+		return nil, errNoSuchUpload
+	}
+
+	upload, err := g.FileStore.GetMultipartUpload(c.ctx, id)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, errNoSuchUpload
+		}
+
+		return nil, err
+	}
+
+	if upload.UserID != c.user.ID || upload.BucketID != b.ID || upload.Key != key {
+		return nil, errNoSuchUpload
+	}
+
+	return upload, nil
+}
+
+// deleteParts deletes the parts of upload from the storage, then the upload,
+// which releases the storage reserved for the parts.
+func (g *Gateway) deleteParts(c *call, upload *file.MultipartUpload, parts []file.UploadPart) error {
+	for _, p := range parts {
+		if err := g.FileService.Delete(c.ctx, upload.StoragePath(p.Number)); err != nil {
+			g.logError(c, fmt.Errorf("delete part: %w", err))
+		}
+	}
+
+	if err := g.FileStore.DeleteMultipartUpload(c.ctx, upload.ID); err != nil {
+		return fmt.Errorf("delete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// partsReader reads the parts of an upload one after the other, each part is
+// requested from the storage once the previous one is read.
+type partsReader struct {
+	ctx         context.Context
+	fileService file.Service
+	paths       []string
+
+	r io.ReadCloser
+}
+
+func (pr *partsReader) Read(p []byte) (int, error) {
+	for {
+		if pr.r == nil {
+			if len(pr.paths) == 0 {
+				return 0, io.EOF
+			}
+
+			r, _, err := pr.fileService.DownloadFile(pr.ctx, pr.paths[0])
+			if err != nil {
+				return 0, fmt.Errorf("download part: %w", err)
+			}
+
+			pr.r, pr.paths = r, pr.paths[1:]
+		}
+
+		n, err := pr.r.Read(p)
+		if errors.Is(err, io.EOF) {
+			pr.r.Close()
+			pr.r = nil
+
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (pr *partsReader) Close() error {
+	if pr.r == nil {
+		return nil
+	}
+
+	return pr.r.Close()
+}
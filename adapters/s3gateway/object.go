@@ -0,0 +1,387 @@
+package s3gateway
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxObjects is the largest number of objects deleted at once.
+	maxObjects = 1000
+
+	// maxXMLSize bounds the bodies decoded as XML.
+	maxXMLSize = 2 << 20
+)
+
+// responseHeaders are the headers of GetObject that can be overridden with
+// the response-* parameters of the query.
+var responseHeaders = map[string]string{
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+	"response-content-language":    "Content-Language",
+	"response-content-type":        "Content-Type",
+	"response-expires":             "Expires",
+}
+
+// getObject serves GetObject and HeadObject, with ranges and conditional
+// requests.
+func (g *Gateway) getObject(c *call, b *file.File, key string) error {
+	f, err := g.object(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	if err := g.canView(c, f); err != nil {
+		return err
+	}
+
+	if f.Quarantined() {
+		return errAccessDenied.withMessage("The object is quarantined")
+	}
+
+	contentType := f.MimeType
+	switch {
+	case f.IsDir:
+		contentType = "application/x-directory"
+	case contentType == "":
+		contentType = "application/octet-stream"
+	}
+
+	header := c.w.Header()
+	header.Set("ETag", etag(f))
+	header.Set("Content-Type", contentType)
+
+	query := c.r.URL.Query()
+	for param, name := range responseHeaders {
+		if value := query.Get(param); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	if f.IsDir {
+		header.Set("Content-Length", "0")
+		header.Set("Last-Modified", f.UpdatedAt.UTC().Format(http.TimeFormat))
+		c.w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+
+	r := services.NewFileReader(c.ctx, g.FileService, f)
+	defer r.Close()
+
+	http.ServeContent(c.w, c.r, "", f.UpdatedAt, r)
+
+	return nil
+}
+
+// putObject stores the body at key, a key ending with a slash creates a
+// directory.
+func (g *Gateway) putObject(c *call, b *file.File, key string) error {
+	if c.size < 0 {
+		return errMissingContentLength
+	}
+
+	body := c.body
+	if s := c.r.Header.Get("Content-MD5"); s != "" {
+		sum, err := base64.StdEncoding.DecodeString(s)
+		if err != nil || len(sum) != md5.Size {
+			return errInvalidDigest
+		}
+
+		body = newHashReader(body, md5.New(), sum, errBadDigest)
+	}
+
+	if strings.HasSuffix(key, "/") {
+		if c.size > 0 {
+			return errInvalidRequest.withMessage("A key ending with a slash is a directory, it cannot have content")
+		}
+
+		if hidden(b, key) {
+			return errAccessDenied
+		}
+
+		if _, err := g.mkdirAll(c, b, key); err != nil {
+			return err
+		}
+
+		c.w.Header().Set("ETag", etag(&file.File{IsDir: true}))
+		c.w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+
+	f, err := g.store(c, b, key, body, c.size, 0)
+	if err != nil {
+		return err
+	}
+
+	c.w.Header().Set("ETag", etag(f))
+	c.w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// copyObject copies the object named by the x-amz-copy-source header to key.
+func (g *Gateway) copyObject(c *call, b *file.File, key string) error {
+	source, err := url.PathUnescape(c.r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		return errInvalidArgument.withMessage("Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+	}
+
+	// there is a single version of each object
+	source, _, _ = strings.Cut(source, "?")
+
+	srcName, srcKey, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || !validKey(srcKey) || strings.HasSuffix(srcKey, "/") || strings.HasSuffix(key, "/") {
+		return errInvalidArgument.withMessage("Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+	}
+
+	srcBucket, err := g.bucket(c, srcName)
+	if err != nil {
+		return err
+	}
+
+	src, err := g.object(c, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+
+	if err := g.canView(c, src); err != nil {
+		return err
+	}
+
+	if src.Quarantined() {
+		return errAccessDenied.withMessage("The object is quarantined")
+	}
+
+	if src.FullPath() == path.Join(b.FullPath(), key) {
+		return errInvalidRequest.withMessage("This copy request is illegal because it is trying to copy an object to itself.")
+	}
+
+	r := services.NewFileReader(c.ctx, g.FileService, src)
+	defer r.Close()
+
+	f, err := g.store(c, b, key, r, int64(src.Size), 0)
+	if err != nil {
+		return err
+	}
+
+	return g.writeXML(c, http.StatusOK, copyObjectResult{
+		Xmlns:        xmlns,
+		LastModified: timestamp(time.Now()),
+		ETag:         etag(f),
+	})
+}
+
+// deleteObject succeeds whether the object exists or not.
+func (g *Gateway) deleteObject(c *call, b *file.File, key string) error {
+	if err := g.remove(c, b, key); err != nil && !errors.Is(err, errNoSuchKey) {
+		return err
+	}
+
+	c.w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// deleteObjects removes the listed objects, the errors are reported by key.
+func (g *Gateway) deleteObjects(c *call, b *file.File) error {
+	data, err := io.ReadAll(io.LimitReader(c.body, maxXMLSize))
+	if err != nil {
+		return err
+	}
+
+	var req deleteRequest
+	if err := xml.Unmarshal(data, &req); err != nil || len(req.Objects) == 0 || len(req.Objects) > maxObjects {
+		return errMalformedXML
+	}
+
+	result := deleteResult{Xmlns: xmlns}
+
+	for _, o := range req.Objects {
+		var err error = errInvalidArgument.withMessage("The specified key is not valid.")
+		if validKey(o.Key) {
+			err = g.remove(c, b, o.Key)
+		}
+
+		if err != nil && !errors.Is(err, errNoSuchKey) {
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) {
+				g.logError(c, err)
+				apiErr = errInternalError
+			}
+
+			result.Errors = append(result.Errors, deleteError{Key: o.Key, Code: apiErr.Code, Message: apiErr.Message})
+
+			continue
+		}
+
+		if !req.Quiet {
+			result.Deleted = append(result.Deleted, deletedObject{Key: o.Key})
+		}
+	}
+
+	return g.writeXML(c, http.StatusOK, result)
+}
+
+// store writes the content of r, of size bytes, at key of b like PutFile.
+// The missing directories of the key are created, and the file at key is
+// replaced. reserved is the storage the user reserved for the content already,
+// it is released by the caller once the file is stored.
+func (g *Gateway) store(c *call, b *file.File, key string, r io.Reader, size int64, reserved uint64) (*file.File, error) {
+	if hidden(b, key) {
+		return nil, errAccessDenied
+	}
+
+	parent, err := g.mkdirAll(c, b, path.Dir(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.canEdit(c, parent); err != nil {
+		return nil, err
+	}
+
+	existing, err := g.FileStore.GetByFullPath(c.ctx, path.Join(parent.FullPath(), path.Base(key)))
+	switch {
+	case errors.Is(err, file.ErrNotFound):
+		existing = nil
+	case err != nil:
+		return nil, err
+	case existing.IsDir:
+		return nil, errInvalidRequest.withMessage("A directory exists at the specified key")
+	case existing.OwnerID != c.user.ID:
+		// the replaced file goes to the trash, which only its owner can do
+		return nil, errAccessDenied
+	}
+
+	owner, err := g.UserStore.GetByID(c.ctx, parent.OwnerID.String())
+	if err != nil {
+		return nil, fmt.Errorf("get owner: %w", err)
+	}
+
+	usage := owner.StorageUsage
+	if owner.ID == c.user.ID {
+		usage -= min(reserved, usage)
+	}
+
+	if usage+uint64(size) > owner.StorageCapacity {
+		return nil, errQuotaExceeded
+	}
+
+	f := file.NewFile(path.Base(key)).WithID(uuid.New()).WithOwnerID(c.user.ID)
+	err = services.PutFile(c.ctx, g.FileStore, g.UserStore, g.FileService, g.PermissionService, g.PubSubService, parent, existing, f, r)
+	if err := g.applied(c, err); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// mkdirAll returns the directory at dirKey in b, creating the missing
+// directories like Mkdir.
+func (g *Gateway) mkdirAll(c *call, b *file.File, dirKey string) (*file.File, error) {
+	dir := b
+
+	dirKey = strings.Trim(dirKey, "/")
+	if dirKey == "." || dirKey == "" {
+		return dir, nil
+	}
+
+	for _, name := range strings.Split(dirKey, "/") {
+		f, err := g.FileStore.GetByFullPath(c.ctx, path.Join(dir.FullPath(), name))
+		if err == nil {
+			if !f.IsDir {
+				return nil, errInvalidRequest.withMessage("A parent of the specified key is an object")
+			}
+
+			dir = f
+
+			continue
+		}
+
+		if !errors.Is(err, file.ErrNotFound) {
+			return nil, err
+		}
+
+		if err := g.canEdit(c, dir); err != nil {
+			return nil, err
+		}
+
+		f = file.NewDirectory(name).WithID(uuid.New()).WithOwnerID(c.user.ID)
+		if err := services.CreateDirectory(c.ctx, g.FileStore, g.PermissionService, dir, f); err != nil {
+			return nil, err
+		}
+
+		g.writeLogs(c, file.NewLog(f.ID, c.user.ID, file.LogActionCreate))
+		g.writeChanges(c, file.NewChange(f, file.ChangeCreate))
+
+		dir = f
+	}
+
+	return dir, nil
+}
+
+// remove moves the object at key to the trash of the user like MoveToTrash.
+// A directory is only removed when it is empty, the keys under it would
+// still imply it otherwise.
+func (g *Gateway) remove(c *call, b *file.File, key string) error {
+	f, err := g.object(c, b, key)
+	if err != nil {
+		return err
+	}
+
+	if f.IsDir {
+		children, err := g.FileStore.ListCursor(c.ctx, f.FullPath(), pagination.NewCursor("", 1), file.Filter{})
+		if err != nil {
+			return fmt.Errorf("list children: %w", err)
+		}
+
+		if len(children) > 0 {
+			return nil
+		}
+	}
+
+	parent := b
+	if f.Path != b.FullPath() {
+		if parent, err = g.FileStore.GetByFullPath(c.ctx, f.Path); err != nil {
+			return err
+		}
+	}
+
+	if err := g.canEdit(c, parent); err != nil {
+		return err
+	}
+
+	// only the owner can trash a file
+	files, err := g.FileStore.ListSelectedOwnedChildren(c.ctx, c.user.ID, parent, []string{f.ID.String()})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return errAccessDenied
+	}
+
+	trash, err := g.FileStore.GetTrashByUserID(c.ctx, c.user.ID)
+	if err != nil {
+		return err
+	}
+
+	return g.applied(c, services.MoveFiles(c.ctx, g.FileStore, g.UserStore, g.PermissionService, g.PubSubService, c.user.ID, parent, trash, files, true))
+}
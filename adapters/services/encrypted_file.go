@@ -0,0 +1,699 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+const (
+	// encSegmentSize is the plaintext size of a segment, every segment is
+	// sealed on its own so reads can start anywhere in the file.
+	encSegmentSize = 64 << 10
+	encNonceSize   = 12
+	encOverhead    = encNonceSize + 16
+	// encKeysDir holds the encryption header of every file at the same path.
+	encKeysDir = "/.keys"
+)
+
+var ErrUnknownMasterKey = errors.New("unknown master key")
+
+// MasterKeys wraps the per-file data keys. New data keys are wrapped with the
+// active key, the other keys are only kept to unwrap the data keys that have
+// not been rotated yet.
+type MasterKeys struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// NewMasterKeys parses base64 encoded 256-bit keys by id.
+func NewMasterKeys(active string, keys map[string]string) (*MasterKeys, error) {
+	mk := &MasterKeys{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+
+	for id, encoded := range keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode master key %s: %w", id, err)
+		}
+
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %s must be 32 bytes long", id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %s: %w", id, err)
+		}
+
+		mk.keys[id] = aead
+	}
+
+	if _, ok := mk.keys[active]; !ok {
+		return nil, fmt.Errorf("active master key %s: %w", active, ErrUnknownMasterKey)
+	}
+
+	return mk, nil
+}
+
+// Active returns the id of the master key wrapping the new keys.
+func (mk *MasterKeys) Active() string { return mk.active }
+
+// Wrap encrypts a data key or a secret with the active master key, the
+// wrapped key is bound to the id of what it belongs to.
+func (mk *MasterKeys) Wrap(id string, key []byte) (string, []byte, error) {
+	nonce := make([]byte, encNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return mk.active, mk.keys[mk.active].Seal(nonce, nonce, key, []byte(id)), nil
+}
+
+func (mk *MasterKeys) Unwrap(id string, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := mk.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, keyID)
+	}
+
+	if len(wrapped) < encNonceSize {
+		return nil, errors.New("wrapped key too short")
+	}
+
+	key, err := aead.Open(nil, wrapped[:encNonceSize], wrapped[encNonceSize:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap key: %w", err)
+	}
+
+	return key, nil
+}
+
+// encryptionHeader is stored next to the content, the content is never
+// rewritten when the data key is wrapped again.
+type encryptionHeader struct {
+	KeyID      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Size       int64  `json:"size"`
+	// Runs are the plaintext sizes of the create and the appends, every run
+	// starts a new segment so only its last segment may be short.
+	Runs     []int64 `json:"runs"`
+	MD5      []byte  `json:"md5"`
+	MD5State []byte  `json:"md5_state"` // resumes the hash on append
+}
+
+type segment struct {
+	index       uint64 // position among all the segments, authenticated
+	run         int
+	runEnd      int64
+	plainStart  int64
+	cipherStart int64
+	plainLen    int64
+}
+
+// locate returns the segment holding the plaintext offset.
+func (h *encryptionHeader) locate(offset int64) segment {
+	var seg segment
+
+	for r, n := range h.Runs {
+		if offset < n {
+			i := offset / encSegmentSize
+
+			seg.index += uint64(i)
+			seg.run = r
+			seg.runEnd = seg.plainStart + n
+			seg.plainStart += i * encSegmentSize
+			seg.cipherStart += i * (encSegmentSize + encOverhead)
+			seg.plainLen = min(encSegmentSize, seg.runEnd-seg.plainStart)
+
+			return seg
+		}
+
+		count := (n + encSegmentSize - 1) / encSegmentSize
+
+		seg.index += uint64(count)
+		seg.plainStart += n
+		seg.cipherStart += n + count*encOverhead
+		offset -= n
+	}
+
+	seg.run = len(h.Runs)
+
+	return seg
+}
+
+// next returns the segment following seg, false after the last one.
+func (h *encryptionHeader) next(seg segment) (segment, bool) {
+	seg.index++
+	seg.cipherStart += seg.plainLen + encOverhead
+	seg.plainStart += seg.plainLen
+
+	if seg.plainStart >= seg.runEnd {
+		if seg.run++; seg.run >= len(h.Runs) {
+			return seg, false
+		}
+
+		seg.runEnd += h.Runs[seg.run]
+	}
+
+	seg.plainLen = min(encSegmentSize, seg.runEnd-seg.plainStart)
+
+	return seg, true
+}
+
+// segments is the number of segments written so far.
+func (h *encryptionHeader) segments() uint64 {
+	return h.locate(h.Size).index
+}
+
+// storedSize is the size of the content once encrypted.
+func (h *encryptionHeader) storedSize() int64 {
+	return h.locate(h.Size).cipherStart
+}
+
+// EncryptedFileService encrypts the content stored by another file.Service
+// with AES-GCM. Every file has its own data key, wrapped by a master key and
+// stored in an encryption header under /.keys. Files written before the
+// encryption was enabled have no header and are passed through as is.
+type EncryptedFileService struct {
+	next file.Service
+	keys *MasterKeys
+}
+
+func NewEncryptedFileService(next file.Service, keys *MasterKeys) *EncryptedFileService {
+	return &EncryptedFileService{next: next, keys: keys}
+}
+
+func (s *EncryptedFileService) GetMetadata(ctx context.Context, id string) (*file.Entry, error) {
+	entry, err := s.next.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.IsDir {
+		return entry, nil
+	}
+
+	h, err := s.header(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if h != nil {
+		entry.Size = uint64(h.Size)
+		entry.MD5 = h.MD5
+	}
+
+	return entry, nil
+}
+
+func (s *EncryptedFileService) DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error) {
+	return s.DownloadRange(ctx, id, 0, -1)
+}
+
+func (s *EncryptedFileService) DownloadRange(ctx context.Context, id string, offset int64, length int64) (io.ReadCloser, string, error) {
+	h, err := s.header(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if h == nil {
+		return s.next.DownloadRange(ctx, id, offset, length)
+	}
+
+	if length < 0 || offset+length > h.Size {
+		length = max(h.Size-offset, 0)
+	}
+
+	if length == 0 {
+		entry, err := s.next.GetMetadata(ctx, id)
+		if err != nil {
+			return nil, "", fmt.Errorf("get metadata: %w", err)
+		}
+
+		return io.NopCloser(strings.NewReader("")), entry.MimeType, nil
+	}
+
+	aead, err := s.dataKey(id, h)
+	if err != nil {
+		return nil, "", err
+	}
+
+	first, last := h.locate(offset), h.locate(offset+length-1)
+	end := last.cipherStart + last.plainLen + encOverhead
+
+	rc, mime, err := s.next.DownloadRange(ctx, id, first.cipherStart, end-first.cipherStart)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &openReader{
+		src:       rc,
+		aead:      aead,
+		id:        filepath.Join("/", id),
+		header:    h,
+		seg:       first,
+		skip:      offset - first.plainStart,
+		remaining: length,
+		buf:       make([]byte, encSegmentSize+encOverhead),
+	}, mime, nil
+}
+
+func (s *EncryptedFileService) CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return 0, fmt.Errorf("generate data key: %w", err)
+	}
+
+	keyID, wrapped, err := s.keys.Wrap(filepath.Join("/", id), key)
+	if err != nil {
+		return 0, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// the content goes first so a failed upload leaves the previous content
+	// and header untouched, until the header is saved the new content cannot
+	// be opened with the previous data key
+	h := &encryptionHeader{KeyID: keyID, WrappedKey: wrapped}
+	r := newSealReader(content, aead, filepath.Join("/", id), 0, md5.New())
+	if _, err := s.next.CreateFile(ctx, r, id, contentType); err != nil {
+		return 0, err
+	}
+
+	if err := s.commit(ctx, id, h, r); err != nil {
+		// content without a header would be served as is
+		if err := s.next.Delete(ctx, id); err != nil && !errors.Is(err, file.ErrNotFound) {
+			return 0, fmt.Errorf("delete content without header: %w", err)
+		}
+
+		return 0, err
+	}
+
+	return r.n, nil
+}
+
+func (s *EncryptedFileService) AppendFile(ctx context.Context, content io.Reader, id string) (int64, error) {
+	h, err := s.header(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if h == nil {
+		_, err := s.next.GetMetadata(ctx, id)
+		if err == nil {
+			// a plaintext file stays plaintext, it cannot be encrypted halfway
+			return s.next.AppendFile(ctx, content, id)
+		}
+
+		if !errors.Is(err, file.ErrNotFound) {
+			return 0, fmt.Errorf("get metadata: %w", err)
+		}
+
+		return s.CreateFile(ctx, content, id, "application/octet-stream")
+	}
+
+	// the segments are located from the header, content appended after a
+	// failed header update would shift every following segment
+	entry, err := s.next.GetMetadata(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("get metadata: %w", err)
+	}
+
+	if int64(entry.Size) != h.storedSize() {
+		return 0, fmt.Errorf("encrypted file %s: stored size %d does not match the header", id, entry.Size)
+	}
+
+	aead, err := s.dataKey(id, h)
+	if err != nil {
+		return 0, err
+	}
+
+	digest := md5.New()
+	if len(h.MD5State) > 0 {
+		if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(h.MD5State); err != nil {
+			return 0, fmt.Errorf("restore md5: %w", err)
+		}
+	}
+
+	r := newSealReader(content, aead, filepath.Join("/", id), h.segments(), digest)
+	if _, err := s.next.AppendFile(ctx, r, id); err != nil {
+		return 0, err
+	}
+
+	if err := s.commit(ctx, id, h, r); err != nil {
+		return 0, err
+	}
+
+	return r.n, nil
+}
+
+func (s *EncryptedFileService) Delete(ctx context.Context, id string) error {
+	if err := s.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// also removes the headers of a whole directory
+	if err := s.next.Delete(ctx, keyPath(id)); err != nil && !errors.Is(err, file.ErrNotFound) {
+		return fmt.Errorf("delete encryption header: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries returns the stored sizes, reading every header would make the
+// listing much slower.
+func (s *EncryptedFileService) ListEntries(ctx context.Context, dirpath string, lastFileName string, limit int) ([]file.Entry, error) {
+	entries, err := s.next.ListEntries(ctx, dirpath, lastFileName, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Join("/", dirpath) != "/" {
+		return entries, nil
+	}
+
+	visible := entries[:0]
+	for _, e := range entries {
+		if e.FullPath != encKeysDir {
+			visible = append(visible, e)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *EncryptedFileService) DirStatus(ctx context.Context) (map[string]interface{}, error) {
+	status, err := s.next.DirStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status["EncryptionKeyID"] = s.keys.active
+
+	return status, nil
+}
+
+func (s *EncryptedFileService) VolStatus(ctx context.Context) (map[string]interface{}, error) {
+	return s.next.VolStatus(ctx)
+}
+
+// RotateKeys wraps every data key that is not wrapped by the active master
+// key again, the content is left untouched. It returns the number of keys
+// wrapped again.
+func (s *EncryptedFileService) RotateKeys(ctx context.Context) (int, error) {
+	return s.rotateDir(ctx, encKeysDir)
+}
+
+func (s *EncryptedFileService) rotateDir(ctx context.Context, dir string) (int, error) {
+	var (
+		rotated int
+		last    string
+	)
+
+	for {
+		entries, err := s.next.ListEntries(ctx, dir, last, 500)
+		if err != nil {
+			if errors.Is(err, file.ErrNotFound) {
+				return rotated, nil
+			}
+
+			return rotated, fmt.Errorf("list entries: %w", err)
+		}
+
+		for _, e := range entries {
+			if e.IsDir {
+				n, err := s.rotateDir(ctx, e.FullPath)
+				rotated += n
+				if err != nil {
+					return rotated, err
+				}
+
+				continue
+			}
+
+			ok, err := s.rotate(ctx, strings.TrimPrefix(e.FullPath, encKeysDir))
+			if err != nil {
+				return rotated, fmt.Errorf("rotate %s: %w", e.FullPath, err)
+			}
+
+			if ok {
+				rotated++
+			}
+		}
+
+		if len(entries) < 500 {
+			return rotated, nil
+		}
+
+		last = entries[len(entries)-1].Name
+	}
+}
+
+func (s *EncryptedFileService) rotate(ctx context.Context, id string) (bool, error) {
+	h, err := s.header(ctx, id)
+	if err != nil || h == nil || h.KeyID == s.keys.active {
+		return false, err
+	}
+
+	key, err := s.keys.Unwrap(filepath.Join("/", id), h.KeyID, h.WrappedKey)
+	if err != nil {
+		return false, err
+	}
+
+	if h.KeyID, h.WrappedKey, err = s.keys.Wrap(filepath.Join("/", id), key); err != nil {
+		return false, err
+	}
+
+	if err := s.saveHeader(ctx, id, h); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *EncryptedFileService) dataKey(id string, h *encryptionHeader) (cipher.AEAD, error) {
+	key, err := s.keys.Unwrap(filepath.Join("/", id), h.KeyID, h.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAEAD(key)
+}
+
+// header returns nil for the files stored without encryption.
+func (s *EncryptedFileService) header(ctx context.Context, id string) (*encryptionHeader, error) {
+	rc, _, err := s.next.DownloadFile(ctx, keyPath(id))
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("download encryption header: %w", err)
+	}
+	defer rc.Close()
+
+	var h encryptionHeader
+	if err := json.NewDecoder(rc).Decode(&h); err != nil {
+		return nil, fmt.Errorf("decode encryption header: %w", err)
+	}
+
+	return &h, nil
+}
+
+func (s *EncryptedFileService) saveHeader(ctx context.Context, id string, h *encryptionHeader) error {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("encode encryption header: %w", err)
+	}
+
+	if _, err := s.next.CreateFile(ctx, bytes.NewReader(b), keyPath(id), "application/json"); err != nil {
+		return fmt.Errorf("save encryption header: %w", err)
+	}
+
+	return nil
+}
+
+// commit records the content written by r in the header.
+func (s *EncryptedFileService) commit(ctx context.Context, id string, h *encryptionHeader, r *sealReader) error {
+	state, err := r.digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("save md5: %w", err)
+	}
+
+	if r.n > 0 {
+		h.Runs = append(h.Runs, r.n)
+		h.Size += r.n
+	}
+
+	h.MD5 = r.digest.Sum(nil)
+	h.MD5State = state
+
+	return s.saveHeader(ctx, id, h)
+}
+
+func keyPath(id string) string {
+	return filepath.Join(encKeysDir, filepath.Join("/", id))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// segmentAAD binds a segment to its file and position, segments cannot be
+// swapped or moved to another file.
+func segmentAAD(id string, index uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte(id), index)
+}
+
+// sealReader encrypts src one segment at a time: a random nonce followed by
+// the sealed plaintext.
+type sealReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	id     string
+	index  uint64
+	digest hash.Hash
+	plain  []byte
+	out    []byte
+	sealed []byte // the unread part of out
+	n      int64  // plaintext bytes read
+	done   bool
+}
+
+func newSealReader(src io.Reader, aead cipher.AEAD, id string, index uint64, digest hash.Hash) *sealReader {
+	return &sealReader{
+		src:    src,
+		aead:   aead,
+		id:     id,
+		index:  index,
+		digest: digest,
+		plain:  make([]byte, encSegmentSize),
+		out:    make([]byte, encSegmentSize+encOverhead),
+	}
+}
+
+func (r *sealReader) Read(p []byte) (int, error) {
+	for len(r.sealed) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.sealed)
+	r.sealed = r.sealed[n:]
+
+	return n, nil
+}
+
+func (r *sealReader) seal() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		r.done = true
+	} else if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	r.digest.Write(r.plain[:n])
+	r.n += int64(n)
+
+	nonce := r.out[:encNonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	r.sealed = r.aead.Seal(nonce, nonce, r.plain[:n], segmentAAD(r.id, r.index))
+	r.index++
+
+	return nil
+}
+
+// openReader decrypts the segments from seg on and returns remaining bytes
+// from skip.
+type openReader struct {
+	src       io.ReadCloser
+	aead      cipher.AEAD
+	id        string
+	header    *encryptionHeader
+	seg       segment
+	skip      int64
+	remaining int64
+	buf       []byte
+	plain     []byte
+}
+
+func (r *openReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.remaining == 0 {
+			return 0, io.EOF
+		}
+
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+func (r *openReader) open() error {
+	sealed := r.buf[:r.seg.plainLen+encOverhead]
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return fmt.Errorf("read segment %d: %w", r.seg.index, err)
+	}
+
+	plain, err := r.aead.Open(sealed[encNonceSize:encNonceSize], sealed[:encNonceSize], sealed[encNonceSize:], segmentAAD(r.id, r.seg.index))
+	if err != nil {
+		return fmt.Errorf("open segment %d: %w", r.seg.index, err)
+	}
+
+	plain = plain[r.skip:]
+	plain = plain[:min(int64(len(plain)), r.remaining)]
+
+	r.skip = 0
+	r.remaining -= int64(len(plain))
+	r.plain = plain
+	r.seg, _ = r.header.next(r.seg)
+
+	return nil
+}
+
+func (r *openReader) Close() error {
+	return r.src.Close()
+}
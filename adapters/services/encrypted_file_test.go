@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+const testSegment = encSegmentSize
+
+func TestEncryptionHeaderLocate(t *testing.T) {
+	tests := []struct {
+		runs   []int64
+		offset int64
+		want   segment
+	}{
+		{[]int64{testSegment + 1}, 0, segment{index: 0, run: 0, runEnd: testSegment + 1, plainStart: 0, cipherStart: 0, plainLen: testSegment}},
+		{[]int64{testSegment + 1}, testSegment - 1, segment{index: 0, run: 0, runEnd: testSegment + 1, plainStart: 0, cipherStart: 0, plainLen: testSegment}},
+		{[]int64{testSegment + 1}, testSegment, segment{index: 1, run: 0, runEnd: testSegment + 1, plainStart: testSegment, cipherStart: testSegment + encOverhead, plainLen: 1}},
+		{[]int64{testSegment + 1}, testSegment + 1, segment{index: 2, run: 1, plainStart: testSegment + 1, cipherStart: testSegment + 1 + 2*encOverhead}},
+		{[]int64{testSegment}, testSegment, segment{index: 1, run: 1, plainStart: testSegment, cipherStart: testSegment + encOverhead}},
+		{[]int64{10, testSegment + 1}, 9, segment{index: 0, run: 0, runEnd: 10, plainStart: 0, cipherStart: 0, plainLen: 10}},
+		{[]int64{10, testSegment + 1}, 10, segment{index: 1, run: 1, runEnd: testSegment + 11, plainStart: 10, cipherStart: 10 + encOverhead, plainLen: testSegment}},
+		{[]int64{10, testSegment + 1}, testSegment + 10, segment{index: 2, run: 1, runEnd: testSegment + 11, plainStart: testSegment + 10, cipherStart: testSegment + 10 + 2*encOverhead, plainLen: 1}},
+		{nil, 0, segment{}},
+	}
+
+	for _, tt := range tests {
+		h := &encryptionHeader{Runs: tt.runs}
+		if got := h.locate(tt.offset); got != tt.want {
+			t.Errorf("locate(%d) with runs %v = %+v; want %+v", tt.offset, tt.runs, got, tt.want)
+		}
+	}
+}
+
+func TestEncryptionHeaderNext(t *testing.T) {
+	tests := []struct {
+		runs []int64
+		want [][2]int64 // plain start and length of every segment
+	}{
+		{[]int64{1}, [][2]int64{{0, 1}}},
+		{[]int64{testSegment}, [][2]int64{{0, testSegment}}},
+		{[]int64{testSegment + 1}, [][2]int64{{0, testSegment}, {testSegment, 1}}},
+		{[]int64{testSegment, 1, testSegment + 1}, [][2]int64{{0, testSegment}, {testSegment, 1}, {testSegment + 1, testSegment}, {2*testSegment + 1, 1}}},
+	}
+
+	for _, tt := range tests {
+		h := &encryptionHeader{Runs: tt.runs}
+
+		var got [][2]int64
+		for seg, ok := h.locate(0), true; ok; seg, ok = h.next(seg) {
+			got = append(got, [2]int64{seg.plainStart, seg.plainLen})
+
+			if want := h.locate(seg.plainStart); seg != want {
+				t.Errorf("next() with runs %v = %+v; want %+v", tt.runs, seg, want)
+			}
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("segments with runs %v = %v; want %v", tt.runs, got, tt.want)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("segments with runs %v = %v; want %v", tt.runs, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestEncryptedFileRoundTrip(t *testing.T) {
+	tests := []int{0, 1, testSegment - 1, testSegment, testSegment + 1, 3*testSegment + 5}
+
+	s := newTestEncryptedFileService(t, "k1", "k1")
+
+	for _, size := range tests {
+		content := testContent(size)
+
+		n, err := s.CreateFile(context.Background(), bytes.NewReader(content), "/round-trip", "application/octet-stream")
+		if err != nil {
+			t.Fatalf("CreateFile(%d bytes) error = %v", size, err)
+		}
+
+		if n != int64(size) {
+			t.Errorf("CreateFile(%d bytes) = %d", size, n)
+		}
+
+		assertContent(t, s, "/round-trip", content)
+	}
+}
+
+func TestEncryptedFileDownloadRange(t *testing.T) {
+	content := testContent(3*testSegment + 5)
+
+	tests := []struct {
+		offset int64
+		length int64
+	}{
+		{0, 1},
+		{0, -1},
+		{testSegment - 1, 1},
+		{testSegment - 1, 2},
+		{testSegment, testSegment},
+		{testSegment + 1, -1},
+		{testSegment / 2, 2 * testSegment},
+		{3*testSegment + 4, 1},
+		{3*testSegment + 4, 100},
+		{3*testSegment + 5, -1},
+	}
+
+	s := newTestEncryptedFileService(t, "k1", "k1")
+	if _, err := s.CreateFile(context.Background(), bytes.NewReader(content), "/range", "application/octet-stream"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		end := int64(len(content))
+		if tt.length >= 0 {
+			end = min(tt.offset+tt.length, end)
+		}
+
+		got, err := readRange(s, "/range", tt.offset, tt.length)
+		if err != nil {
+			t.Errorf("DownloadRange(%d, %d) error = %v", tt.offset, tt.length, err)
+			continue
+		}
+
+		if !bytes.Equal(got, content[tt.offset:end]) {
+			t.Errorf("DownloadRange(%d, %d) returned %d bytes; want %d", tt.offset, tt.length, len(got), end-tt.offset)
+		}
+	}
+}
+
+func TestEncryptedFileAppend(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []int
+	}{
+		{"short runs", []int{1, 2, 3}},
+		{"segment runs", []int{testSegment, testSegment}},
+		{"uneven runs", []int{testSegment + 1, 10, testSegment - 1, 2*testSegment + 3}},
+		{"empty append", []int{10, 0, 10}},
+	}
+
+	s := newTestEncryptedFileService(t, "k1", "k1")
+
+	for _, tt := range tests {
+		var content []byte
+
+		for i, size := range tt.chunks {
+			chunk := testContent(size + i)[i:]
+			content = append(content, chunk...)
+
+			if _, err := s.AppendFile(context.Background(), bytes.NewReader(chunk), "/"+tt.name); err != nil {
+				t.Fatalf("%s: AppendFile() error = %v", tt.name, err)
+			}
+		}
+
+		assertContent(t, s, "/"+tt.name, content)
+
+		// every range crossing a run boundary
+		var offset int64
+		for _, size := range tt.chunks[:len(tt.chunks)-1] {
+			offset += int64(size)
+			start := max(offset-2, 0)
+
+			got, err := readRange(s, "/"+tt.name, start, 4)
+			if err != nil {
+				t.Errorf("%s: DownloadRange(%d, 4) error = %v", tt.name, start, err)
+				continue
+			}
+
+			if want := content[start:min(start+4, int64(len(content)))]; !bytes.Equal(got, want) {
+				t.Errorf("%s: DownloadRange(%d, 4) = %v; want %v", tt.name, start, got, want)
+			}
+		}
+	}
+}
+
+func TestEncryptedFileRotateKeys(t *testing.T) {
+	next, err := NewLocalFileService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileService() error = %v", err)
+	}
+
+	content := testContent(testSegment + 1)
+
+	old := NewEncryptedFileService(next, newTestMasterKeys(t, "k1", "k1"))
+	for _, id := range []string{"/a", "/dir/b"} {
+		if _, err := old.CreateFile(context.Background(), bytes.NewReader(content), id, "application/octet-stream"); err != nil {
+			t.Fatalf("CreateFile(%s) error = %v", id, err)
+		}
+	}
+
+	s := NewEncryptedFileService(next, newTestMasterKeys(t, "k2", "k1", "k2"))
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"first rotation", 2},
+		{"second rotation", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := s.RotateKeys(context.Background())
+		if err != nil {
+			t.Fatalf("%s: RotateKeys() error = %v", tt.name, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: RotateKeys() = %d; want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// the old master key is not needed anymore
+	rotated := NewEncryptedFileService(next, newTestMasterKeys(t, "k2", "k2"))
+	for _, id := range []string{"/a", "/dir/b"} {
+		assertContent(t, rotated, id, content)
+	}
+}
+
+func TestEncryptedFileTampered(t *testing.T) {
+	content := testContent(2*testSegment + 1)
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, s *EncryptedFileService)
+	}{
+		{"flipped content byte", func(t *testing.T, s *EncryptedFileService) {
+			b := readStored(t, s, "/victim")
+			b[testSegment+encOverhead+encNonceSize+1] ^= 1
+			writeStored(t, s, "/victim", b)
+		}},
+		{"swapped segments", func(t *testing.T, s *EncryptedFileService) {
+			b := readStored(t, s, "/victim")
+			n := testSegment + encOverhead
+			swapped := append(append(append([]byte{}, b[n:2*n]...), b[:n]...), b[2*n:]...)
+			writeStored(t, s, "/victim", swapped)
+		}},
+		{"truncated content", func(t *testing.T, s *EncryptedFileService) {
+			b := readStored(t, s, "/victim")
+			writeStored(t, s, "/victim", b[:len(b)-1])
+		}},
+		{"content of another file", func(t *testing.T, s *EncryptedFileService) {
+			writeStored(t, s, "/victim", readStored(t, s, "/other"))
+		}},
+		{"header of another file", func(t *testing.T, s *EncryptedFileService) {
+			writeStored(t, s, keyPath("/victim"), readStored(t, s, keyPath("/other")))
+			writeStored(t, s, "/victim", readStored(t, s, "/other"))
+		}},
+		{"flipped wrapped key", func(t *testing.T, s *EncryptedFileService) {
+			tamperHeader(t, s, "/victim", func(h *encryptionHeader) { h.WrappedKey[len(h.WrappedKey)-1] ^= 1 })
+		}},
+		{"unknown key id", func(t *testing.T, s *EncryptedFileService) {
+			tamperHeader(t, s, "/victim", func(h *encryptionHeader) { h.KeyID = "unknown" })
+		}},
+		{"moved run boundary", func(t *testing.T, s *EncryptedFileService) {
+			tamperHeader(t, s, "/victim", func(h *encryptionHeader) { h.Runs = []int64{testSegment - 1, testSegment + 2} })
+		}},
+		{"grown size", func(t *testing.T, s *EncryptedFileService) {
+			tamperHeader(t, s, "/victim", func(h *encryptionHeader) { h.Size++; h.Runs[0]++ })
+		}},
+	}
+
+	for _, tt := range tests {
+		s := newTestEncryptedFileService(t, "k1", "k1")
+		for _, id := range []string{"/victim", "/other"} {
+			if _, err := s.CreateFile(context.Background(), bytes.NewReader(content), id, "application/octet-stream"); err != nil {
+				t.Fatalf("%s: CreateFile(%s) error = %v", tt.name, id, err)
+			}
+		}
+
+		tt.tamper(t, s)
+
+		if got, err := readRange(s, "/victim", 0, -1); err == nil {
+			t.Errorf("%s: DownloadFile() returned %d bytes; want an error", tt.name, len(got))
+		}
+	}
+}
+
+func TestEncryptedFileFailedOverwrite(t *testing.T) {
+	s := newTestEncryptedFileService(t, "k1", "k1")
+	content := testContent(testSegment + 1)
+
+	if _, err := s.CreateFile(context.Background(), bytes.NewReader(content), "/overwrite", "application/octet-stream"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	failing := io.MultiReader(bytes.NewReader(testContent(testSegment)), iotest.ErrReader(errors.New("connection reset")))
+	if _, err := s.CreateFile(context.Background(), failing, "/overwrite", "application/octet-stream"); err == nil {
+		t.Fatal("CreateFile() with a failing reader succeeded")
+	}
+
+	assertContent(t, s, "/overwrite", content)
+}
+
+func newTestMasterKeys(t *testing.T, active string, ids ...string) *MasterKeys {
+	t.Helper()
+
+	keys := make(map[string]string, len(ids))
+	for _, id := range ids {
+		keys[id] = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte(id[len(id)-1:]), 32))
+	}
+
+	mk, err := NewMasterKeys(active, keys)
+	if err != nil {
+		t.Fatalf("NewMasterKeys() error = %v", err)
+	}
+
+	return mk
+}
+
+func newTestEncryptedFileService(t *testing.T, active string, ids ...string) *EncryptedFileService {
+	t.Helper()
+
+	next, err := NewLocalFileService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileService() error = %v", err)
+	}
+
+	return NewEncryptedFileService(next, newTestMasterKeys(t, active, ids...))
+}
+
+func testContent(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i*7 + i/testSegment)
+	}
+
+	return b
+}
+
+func readRange(s *EncryptedFileService, id string, offset int64, length int64) ([]byte, error) {
+	rc, _, err := s.DownloadRange(context.Background(), id, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func assertContent(t *testing.T, s *EncryptedFileService, id string, want []byte) {
+	t.Helper()
+
+	got, err := readRange(s, id, 0, -1)
+	if err != nil {
+		t.Fatalf("DownloadFile(%s) error = %v", id, err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("DownloadFile(%s) returned %d bytes; want %d", id, len(got), len(want))
+	}
+
+	entry, err := s.GetMetadata(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetMetadata(%s) error = %v", id, err)
+	}
+
+	if sum := md5.Sum(want); entry.Size != uint64(len(want)) || !bytes.Equal(entry.MD5, sum[:]) {
+		t.Errorf("GetMetadata(%s) = %d bytes, md5 %x; want %d bytes, md5 %x", id, entry.Size, entry.MD5, len(want), sum)
+	}
+}
+
+// readStored and writeStored bypass the encryption.
+func readStored(t *testing.T, s *EncryptedFileService, id string) []byte {
+	t.Helper()
+
+	rc, _, err := s.next.DownloadFile(context.Background(), id)
+	if err != nil {
+		t.Fatalf("DownloadFile(%s) error = %v", id, err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read %s: %v", id, err)
+	}
+
+	return b
+}
+
+func writeStored(t *testing.T, s *EncryptedFileService, id string, b []byte) {
+	t.Helper()
+
+	if _, err := s.next.CreateFile(context.Background(), bytes.NewReader(b), id, "application/octet-stream"); err != nil {
+		t.Fatalf("CreateFile(%s) error = %v", id, err)
+	}
+}
+
+func tamperHeader(t *testing.T, s *EncryptedFileService, id string, tamper func(h *encryptionHeader)) {
+	t.Helper()
+
+	var h encryptionHeader
+	if err := json.Unmarshal(readStored(t, s, keyPath(id)), &h); err != nil {
+		t.Fatalf("decode header of %s: %v", id, err)
+	}
+
+	tamper(&h)
+
+	b, err := json.Marshal(&h)
+	if err != nil {
+		t.Fatalf("encode header of %s: %v", id, err)
+	}
+
+	writeStored(t, s, keyPath(id), b)
+}
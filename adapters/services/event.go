@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+)
+
+// eventsHistory is about how many events are kept for the clients that
+// reconnect.
+const eventsHistory = 10000
+
+// ErrNotRecorded tells that an operation was applied but its logs or changes
+// could not be written.
+var ErrNotRecorded = errors.New("changes not recorded")
+
+// PublishEvents sends events to the connected clients. They are added to the
+// history first, the ID it gives them tells the clients where to resume from.
+func PublishEvents(ctx context.Context, pubSubService pubsub.Service, events ...file.Event) error {
+	for _, e := range events {
+		e.CreatedAt = time.Now()
+
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal event: %w", err)
+		}
+
+		e.ID, err = pubSubService.Append(ctx, file.EventsChannel, string(payload), eventsHistory)
+		if err != nil {
+			return fmt.Errorf("append event: %w", err)
+		}
+
+		payload, err = json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal event: %w", err)
+		}
+
+		if err := pubSubService.Publish(ctx, file.EventsChannel, string(payload)); err != nil {
+			return fmt.Errorf("publish event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RecordChanges writes changes to the journals of their users, announces
+// them to the connected clients and queues them for the webhooks. It runs once
+// the operation was applied, so the callers log a failure rather than fail the
+// request.
+func RecordChanges(ctx context.Context, fileStore file.Store, pubSubService pubsub.Service, changes ...file.Change) error {
+	if err := fileStore.WriteChanges(ctx, changes); err != nil {
+		return fmt.Errorf("write changes: %w", err)
+	}
+
+	events := make([]file.Event, len(changes))
+	for i, change := range changes {
+		events[i] = file.NewChangeEvent(change)
+	}
+
+	if err := PublishEvents(ctx, pubSubService, events...); err != nil {
+		return fmt.Errorf("publish events: %w", err)
+	}
+
+	if err := enqueueWebhookEvents(ctx, pubSubService, changes); err != nil {
+		return fmt.Errorf("enqueue webhook events: %w", err)
+	}
+
+	return nil
+}
+
+// notRecorded wraps the failures to record an applied operation in
+// ErrNotRecorded.
+func notRecorded(errs ...error) error {
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotRecorded, err)
+	}
+
+	return nil
+}
+
+// enqueueWebhookEvents queues the changes for the webhook worker, which finds
+// the webhooks they are sent to. The copies of the changes in the journals of
+// the other users are left out, they tell about the same files.
+func enqueueWebhookEvents(ctx context.Context, pubSubService pubsub.Service, changes []file.Change) error {
+	var webhookChanges []file.Change
+	for _, change := range changes {
+		if change.IsCopy() || file.WebhookEventType(change.Type) == "" {
+			continue
+		}
+
+		change.CreatedAt = time.Now()
+		webhookChanges = append(webhookChanges, change)
+	}
+
+	if len(webhookChanges) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(webhookChanges)
+	if err != nil {
+		return fmt.Errorf("marshal changes: %w", err)
+	}
+
+	return pubSubService.Enqueue(ctx, file.WebhookEventsQueue, string(payload))
+}
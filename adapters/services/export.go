@@ -0,0 +1,259 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+const (
+	// exportPageSize is the number of stars or logs read at once.
+	exportPageSize = 500
+	// exportShareBatchSize is the number of files whose shares are read at once.
+	exportShareBatchSize = 1000
+)
+
+// Export writes the tree of user, without the trash, to a zip archive in the
+// storage together with a manifest.json of the metadata, shares, stars and
+// activity logs. The export is saved as finished, or failed, before
+// returning, a finished archive can be downloaded until ttl has passed.
+func Export(ctx context.Context, fileStore file.Store, fileService file.Service, user *identity.User, e *file.Export, ttl time.Duration) error {
+	err := export(ctx, fileStore, fileService, user, e)
+	if err != nil {
+		// a failed upload may leave a partial archive behind
+		_ = fileService.Delete(context.WithoutCancel(ctx), e.StoragePath())
+	}
+
+	e.Finish(err, ttl)
+
+	if err := fileStore.UpdateExport(context.WithoutCancel(ctx), e); err != nil {
+		return fmt.Errorf("update export: %w", err)
+	}
+
+	return err
+}
+
+func export(ctx context.Context, fileStore file.Store, fileService file.Service, user *identity.User, e *file.Export) error {
+	root, err := fileStore.GetByID(ctx, user.RootID.String())
+	if err != nil {
+		return fmt.Errorf("get root directory: %w", err)
+	}
+
+	manifest, files, err := newManifest(ctx, fileStore, user, root)
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		err := writeArchive(ctx, fileService, pw, manifest, files)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	size, err := fileService.CreateFile(ctx, pr, e.StoragePath(), "application/zip")
+
+	// unblocks the archive writer when the upload stopped first
+	pr.CloseWithError(errors.New("upload stopped"))
+
+	if werr := <-done; werr != nil {
+		return fmt.Errorf("write archive: %w", werr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("upload archive: %w", err)
+	}
+
+	e.Size = uint64(size)
+	for _, f := range manifest.Files {
+		if !f.IsDir && !f.Missing {
+			e.Files++
+		}
+	}
+
+	return nil
+}
+
+// newManifest collects the metadata of the archive, the files are returned
+// in the order of the manifest.
+func newManifest(ctx context.Context, fileStore file.Store, user *identity.User, root *file.File) (*file.Manifest, []file.File, error) {
+	children, err := fileStore.ListChildren(ctx, root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list files: %w", err)
+	}
+
+	var (
+		prefix = root.FullPath() + "/"
+		files  []file.File
+		ids    []uuid.UUID
+	)
+
+	for _, f := range children {
+		rel := strings.TrimPrefix(f.FullPath(), prefix)
+		if rel == ".trash" || strings.HasPrefix(rel, ".trash/") {
+			continue
+		}
+
+		files = append(files, f)
+		ids = append(ids, f.ID)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].FullPath() < files[j].FullPath()
+	})
+
+	shares := make(map[uuid.UUID][]file.Share)
+	for i := 0; i < len(ids); i += exportShareBatchSize {
+		batch, err := fileStore.ListSharesByFileIDs(ctx, ids[i:min(i+exportShareBatchSize, len(ids))])
+		if err != nil {
+			return nil, nil, fmt.Errorf("list shares: %w", err)
+		}
+
+		for _, share := range batch {
+			shares[share.FileID] = append(shares[share.FileID], share)
+		}
+	}
+
+	manifest := &file.Manifest{
+		Version:   1,
+		User:      file.NewManifestUser(user),
+		Files:     make([]file.ManifestFile, len(files)),
+		Stars:     []file.ManifestStar{},
+		Logs:      []file.ManifestLog{},
+		CreatedAt: time.Now(),
+	}
+
+	for i, f := range files {
+		manifest.Files[i] = file.ManifestFile{
+			ID:            f.ID,
+			Path:          strings.TrimPrefix(f.FullPath(), prefix),
+			IsDir:         f.IsDir,
+			Size:          f.Size,
+			MimeType:      f.MimeType,
+			MD5:           hex.EncodeToString(f.MD5),
+			GeneralAccess: f.GeneralAccess,
+			Metadata:      f.Metadata,
+			Shares:        shares[f.ID],
+			CreatedAt:     f.CreatedAt,
+			UpdatedAt:     f.UpdatedAt,
+		}
+	}
+
+	cursor := pagination.NewCursor("", exportPageSize)
+	for {
+		starred, err := fileStore.ListStarred(ctx, user.ID, cursor, file.Filter{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("list starred: %w", err)
+		}
+
+		for _, f := range starred {
+			manifest.Stars = append(manifest.Stars, file.ManifestStar{FileID: f.ID, Name: f.Name})
+		}
+
+		next := cursor.NextToken()
+		if next == "" {
+			break
+		}
+
+		cursor = pagination.NewCursor(next, exportPageSize)
+	}
+
+	cursor = pagination.NewCursor("", exportPageSize)
+	for {
+		logs, err := fileStore.ReadLogs(ctx, user.ID.String(), cursor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read logs: %w", err)
+		}
+
+		for _, log := range logs {
+			manifest.Logs = append(manifest.Logs, file.ManifestLog{
+				FileID:    log.FileID,
+				Action:    log.Action,
+				CreatedAt: log.CreatedAt,
+			})
+		}
+
+		next := cursor.NextToken()
+		if next == "" {
+			break
+		}
+
+		cursor = pagination.NewCursor(next, exportPageSize)
+	}
+
+	return manifest, files, nil
+}
+
+// writeArchive writes the files under files/ and the manifest last, once it
+// knows which contents are missing.
+func writeArchive(ctx context.Context, fileService file.Service, w io.Writer, manifest *file.Manifest, files []file.File) error {
+	zw := zip.NewWriter(w)
+
+	for i, f := range files {
+		mf := &manifest.Files[i]
+		name := path.Join("files", mf.Path)
+
+		if f.IsDir {
+			if _, err := zw.CreateHeader(&zip.FileHeader{Name: name + "/", Modified: f.UpdatedAt}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if f.Quarantined() {
+			mf.Missing = true
+			continue
+		}
+
+		r, _, err := fileService.DownloadFile(ctx, f.ID.String())
+		if err != nil {
+			if errors.Is(err, file.ErrNotFound) {
+				mf.Missing = true
+				continue
+			}
+
+			return fmt.Errorf("download %s: %w", f.ID, err)
+		}
+
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: f.UpdatedAt})
+		if err != nil {
+			r.Close()
+			return err
+		}
+
+		_, err = io.Copy(fw, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", f.ID, err)
+		}
+	}
+
+	fw, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	return zw.Close()
+}
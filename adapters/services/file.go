@@ -6,19 +6,61 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 
 	"github.com/SeaCloudHub/backend/domain/file"
 	"github.com/SeaCloudHub/backend/pkg/config"
 	"github.com/SeaCloudHub/backend/pkg/seaweedfs"
 )
 
-type FileService struct {
+type SeaweedFileService struct {
 	sw    *seaweedfs.Seaweed
-	filer *seaweedfs.Filer
+	filer *seaweedfs.FilerPool
 }
 
-func NewFileService(cfg *config.Config) *FileService {
-	swcfg := seaweedfs.NewConfigWithFilerURL(cfg.SeaweedFS.MasterServer, cfg.SeaweedFS.FilerServer)
+// NewFileService returns the file.Service selected by STORAGE_BACKEND, the
+// content is encrypted when ENCRYPTION_KEY_ID is set.
+func NewFileService(cfg *config.Config) file.Service {
+	var (
+		svc file.Service
+		err error
+	)
+
+	switch cfg.Storage.Backend {
+	case config.StorageBackendLocal:
+		svc, err = NewLocalFileService(cfg.Storage.LocalRoot)
+	case config.StorageBackendS3:
+		svc, err = NewS3FileService(cfg)
+	default:
+		svc = NewSeaweedFileService(cfg)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	if cfg.Encryption.KeyID == "" {
+		return svc
+	}
+
+	keys, err := NewMasterKeys(cfg.Encryption.KeyID, cfg.Encryption.Keys)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewEncryptedFileService(svc, keys)
+}
+
+func NewSeaweedFileService(cfg *config.Config) *SeaweedFileService {
+	swcfg := seaweedfs.NewConfig(cfg.SeaweedFS.MasterServer).
+		WithSelection(seaweedfs.Selection(cfg.SeaweedFS.FilerSelection)).
+		WithTimeout(cfg.SeaweedFS.Timeout).
+		WithRetries(cfg.SeaweedFS.MaxRetries, cfg.SeaweedFS.RetryBackoff).
+		WithHealthCheckInterval(cfg.SeaweedFS.HealthCheckInterval)
+
+	for _, filerURL := range cfg.SeaweedFS.FilerServers {
+		swcfg = swcfg.AddFilerURL(filerURL)
+	}
 
 	if cfg.Debug {
 		// swcfg = swcfg.Debug()
@@ -29,13 +71,13 @@ func NewFileService(cfg *config.Config) *FileService {
 		panic(err)
 	}
 
-	return &FileService{
+	return &SeaweedFileService{
 		sw:    sw,
-		filer: sw.Filers()[0],
+		filer: sw.Filer(),
 	}
 }
 
-func (s *FileService) GetMetadata(ctx context.Context, id string) (*file.Entry, error) {
+func (s *SeaweedFileService) GetMetadata(ctx context.Context, id string) (*file.Entry, error) {
 	resp, err := s.filer.GetMetadata(ctx, &seaweedfs.GetMetadataRequest{FullPath: filepath.Join("/", id)})
 	if err != nil {
 		if errors.Is(err, seaweedfs.ErrNotFound) {
@@ -50,7 +92,7 @@ func (s *FileService) GetMetadata(ctx context.Context, id string) (*file.Entry,
 	return &entry, nil
 }
 
-func (s *FileService) DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error) {
+func (s *SeaweedFileService) DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error) {
 	entry, err := s.GetMetadata(ctx, id)
 	if err != nil {
 		return nil, "", fmt.Errorf("get metadata: %w", err)
@@ -68,7 +110,34 @@ func (s *FileService) DownloadFile(ctx context.Context, id string) (io.ReadClose
 	return rc, entry.MimeType, nil
 }
 
-func (s *FileService) CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error) {
+func (s *SeaweedFileService) DownloadRange(ctx context.Context, id string, offset int64, length int64) (io.ReadCloser, string, error) {
+	entry, err := s.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("get metadata: %w", err)
+	}
+
+	if entry.IsDir {
+		return nil, "", file.ErrNotFound
+	}
+
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), entry.MimeType, nil
+	}
+
+	rng := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		rng += fmt.Sprint(offset + length - 1)
+	}
+
+	rc, err := s.filer.DownloadFile(ctx, &seaweedfs.DownloadFileRequest{FullPath: filepath.Join("/", id), Range: rng})
+	if err != nil {
+		return nil, "", fmt.Errorf("download file: %w", err)
+	}
+
+	return rc, entry.MimeType, nil
+}
+
+func (s *SeaweedFileService) CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error) {
 	result, err := s.filer.UploadFile(ctx, &seaweedfs.UploadFileRequest{
 		Content:      content,
 		FullFileName: filepath.Join("/", id),
@@ -81,7 +150,7 @@ func (s *FileService) CreateFile(ctx context.Context, content io.Reader, id stri
 	return result.Size, nil
 }
 
-func (s *FileService) AppendFile(ctx context.Context, content io.Reader, id string) (int64, error) {
+func (s *SeaweedFileService) AppendFile(ctx context.Context, content io.Reader, id string) (int64, error) {
 	result, err := s.filer.AppendFile(ctx, &seaweedfs.AppendFileRequest{
 		Content:      content,
 		FullFileName: filepath.Join("/", id),
@@ -93,7 +162,7 @@ func (s *FileService) AppendFile(ctx context.Context, content io.Reader, id stri
 	return result.Size, nil
 }
 
-func (s *FileService) Delete(ctx context.Context, id string) error {
+func (s *SeaweedFileService) Delete(ctx context.Context, id string) error {
 	err := s.filer.Delete(ctx, &seaweedfs.DeleteRequest{FullPath: filepath.Join("/", id)})
 	if err != nil {
 		return fmt.Errorf("delete: %w", err)
@@ -102,11 +171,34 @@ func (s *FileService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *FileService) DirStatus(ctx context.Context) (map[string]interface{}, error) {
+// ListEntries lists a directory in name order, the next page starts after lastFileName.
+func (s *SeaweedFileService) ListEntries(ctx context.Context, dirpath string, lastFileName string, limit int) ([]file.Entry, error) {
+	resp, err := s.filer.ListEntries(ctx, &seaweedfs.ListEntriesRequest{
+		DirPath:      filepath.Join("/", dirpath) + "/",
+		Limit:        limit,
+		LastFileName: lastFileName,
+	})
+	if err != nil {
+		if errors.Is(err, seaweedfs.ErrNotFound) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+
+	entries := make([]file.Entry, len(resp.Entries))
+	for i := range resp.Entries {
+		entries[i] = mapToEntry(&resp.Entries[i])
+	}
+
+	return entries, nil
+}
+
+func (s *SeaweedFileService) DirStatus(ctx context.Context) (map[string]interface{}, error) {
 	return s.sw.Master().DirStatus(ctx)
 }
 
-func (s *FileService) VolStatus(ctx context.Context) (map[string]interface{}, error) {
+func (s *SeaweedFileService) VolStatus(ctx context.Context) (map[string]interface{}, error) {
 	return s.sw.Master().VolStatus(ctx)
 }
 
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+// LocalFileService stores the files on the local disk, for development setups
+// and tests that should not depend on a SeaweedFS cluster. The content lives
+// under <root>/files and a JSON sidecar with the metadata the file system
+// cannot hold lives at the same path under <root>/meta.
+type LocalFileService struct {
+	root string
+	// mu serializes the writes, appends update the content and the sidecar
+	// together.
+	mu sync.Mutex
+}
+
+type localMetadata struct {
+	MimeType  string    `json:"mime_type"`
+	MD5       []byte    `json:"md5"`
+	MD5State  []byte    `json:"md5_state"` // resumes the hash on append
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewLocalFileService(root string) (*LocalFileService, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve root: %w", err)
+	}
+
+	for _, dir := range []string{"files", "meta"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return nil, fmt.Errorf("create root: %w", err)
+		}
+	}
+
+	return &LocalFileService{root: root}, nil
+}
+
+func (s *LocalFileService) GetMetadata(ctx context.Context, id string) (*file.Entry, error) {
+	fullPath := filepath.Join("/", id)
+
+	info, err := os.Stat(s.path(fullPath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("get metadata: %w", err)
+	}
+
+	return s.entry(fullPath, info)
+}
+
+func (s *LocalFileService) DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error) {
+	entry, err := s.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("get metadata: %w", err)
+	}
+
+	if entry.IsDir {
+		return nil, "", file.ErrNotFound
+	}
+
+	f, err := os.Open(s.path(entry.FullPath))
+	if err != nil {
+		return nil, "", fmt.Errorf("download file: %w", err)
+	}
+
+	return f, entry.MimeType, nil
+}
+
+func (s *LocalFileService) DownloadRange(ctx context.Context, id string, offset int64, length int64) (io.ReadCloser, string, error) {
+	rc, mime, err := s.DownloadFile(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f := rc.(*os.File)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, "", fmt.Errorf("seek file: %w", err)
+	}
+
+	if length < 0 {
+		return f, mime, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}, mime, nil
+}
+
+func (s *LocalFileService) CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error) {
+	fullPath := filepath.Join("/", id)
+	path := s.path(fullPath)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("create directory: %w", err)
+	}
+
+	// the content is written next to the destination and renamed once
+	// complete, readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+
+	size, err := io.Copy(io.MultiWriter(tmp, hash), content)
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("write file: %w", err)
+	}
+
+	// CreateTemp only grants access to the owner
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("chmod file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("write file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("rename file: %w", err)
+	}
+
+	meta := &localMetadata{MimeType: contentType, CreatedAt: time.Now()}
+	if err := s.writeMetadata(fullPath, meta, hash); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (s *LocalFileService) AppendFile(ctx context.Context, content io.Reader, id string) (int64, error) {
+	fullPath := filepath.Join("/", id)
+	path := s.path(fullPath)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("create directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, err := s.readMetadata(fullPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if meta == nil {
+		meta = &localMetadata{CreatedAt: time.Now()}
+	}
+
+	hash := md5.New()
+	if len(meta.MD5State) > 0 {
+		if err := hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(meta.MD5State); err != nil {
+			return 0, fmt.Errorf("restore md5: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("open file: %w", err)
+	}
+
+	size, err := io.Copy(io.MultiWriter(f, hash), content)
+	if err != nil {
+		f.Close()
+		return 0, fmt.Errorf("append file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("append file: %w", err)
+	}
+
+	if err := s.writeMetadata(fullPath, meta, hash); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (s *LocalFileService) Delete(ctx context.Context, id string) error {
+	fullPath := filepath.Join("/", id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Lstat(s.path(fullPath)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("delete: %w", file.ErrNotFound)
+		}
+
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if err := os.RemoveAll(s.path(fullPath)); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if err := os.RemoveAll(s.metaPath(fullPath)); err != nil {
+		return fmt.Errorf("delete metadata: %w", err)
+	}
+
+	// the sidecars of the files of a directory
+	if err := os.RemoveAll(filepath.Join(s.root, "meta", fullPath)); err != nil {
+		return fmt.Errorf("delete metadata: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries lists a directory in name order, the next page starts after lastFileName.
+func (s *LocalFileService) ListEntries(ctx context.Context, dirpath string, lastFileName string, limit int) ([]file.Entry, error) {
+	dirpath = filepath.Join("/", dirpath)
+
+	dirEntries, err := os.ReadDir(s.path(dirpath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, file.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+
+	// ReadDir already sorts by name, the temporary upload files are hidden
+	i := sort.Search(len(dirEntries), func(i int) bool {
+		return dirEntries[i].Name() > lastFileName
+	})
+
+	entries := make([]file.Entry, 0, min(limit, len(dirEntries)-i))
+	for _, d := range dirEntries[i:] {
+		if len(entries) == limit {
+			break
+		}
+
+		if isUploadTemp(d.Name()) {
+			continue
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("stat entry: %w", err)
+		}
+
+		entry, err := s.entry(filepath.Join(dirpath, d.Name()), info)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
+
+func (s *LocalFileService) DirStatus(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"Backend": "local",
+		"Root":    s.root,
+	}, nil
+}
+
+// VolStatus walks the whole tree, it is meant for development setups.
+func (s *LocalFileService) VolStatus(ctx context.Context) (map[string]interface{}, error) {
+	var files, size int64
+
+	err := filepath.WalkDir(filepath.Join(s.root, "files"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files++
+		size += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk files: %w", err)
+	}
+
+	return map[string]interface{}{
+		"Files": files,
+		"Size":  size,
+	}, nil
+}
+
+func (s *LocalFileService) path(fullPath string) string {
+	return filepath.Join(s.root, "files", fullPath)
+}
+
+func (s *LocalFileService) metaPath(fullPath string) string {
+	return filepath.Join(s.root, "meta", fullPath) + ".json"
+}
+
+func (s *LocalFileService) entry(fullPath string, info fs.FileInfo) (*file.Entry, error) {
+	entry := &file.Entry{
+		Name:      filepath.Base(fullPath),
+		FullPath:  fullPath,
+		Mode:      info.Mode(),
+		IsDir:     info.IsDir(),
+		CreatedAt: info.ModTime(),
+		UpdatedAt: info.ModTime(),
+	}
+
+	if info.IsDir() {
+		return entry, nil
+	}
+
+	entry.Size = uint64(info.Size())
+
+	meta, err := s.readMetadata(fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta != nil {
+		entry.MimeType = meta.MimeType
+		entry.MD5 = meta.MD5
+		entry.CreatedAt = meta.CreatedAt
+	}
+
+	return entry, nil
+}
+
+// readMetadata returns nil when the file has no sidecar yet.
+func (s *LocalFileService) readMetadata(fullPath string) (*localMetadata, error) {
+	b, err := os.ReadFile(s.metaPath(fullPath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read metadata: %w", err)
+	}
+
+	var meta localMetadata
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("decode metadata: %w", err)
+	}
+
+	return &meta, nil
+}
+
+func (s *LocalFileService) writeMetadata(fullPath string, meta *localMetadata, h hash.Hash) error {
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("save md5: %w", err)
+	}
+
+	meta.MD5 = h.Sum(nil)
+	meta.MD5State = state
+
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+
+	path := s.metaPath(fullPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+
+	return nil
+}
+
+func isUploadTemp(name string) bool {
+	return strings.HasPrefix(name, ".upload-")
+}
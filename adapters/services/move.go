@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/google/uuid"
+)
+
+// MoveFiles moves files, children of src listed with their descendants, to
+// dest like the Move handler, or to the trash dest like MoveToTrash. The size
+// of the files is charged to the owner of dest when it changes. ErrNotRecorded
+// is returned when the files were moved but the move could not be recorded.
+func MoveFiles(ctx context.Context, fileStore file.Store, userStore identity.Store, permissionService permission.Service,
+	pubSubService pubsub.Service, userID uuid.UUID, src *file.File, dest *file.File, files []file.File, trash bool) error {
+	var totalSize uint64
+	for _, e := range files {
+		totalSize += e.Size
+	}
+
+	chargeable := dest.OwnerID != src.OwnerID && dest.Owner != nil && src.Owner != nil
+	if chargeable && totalSize+dest.Owner.StorageUsage > dest.Owner.StorageCapacity {
+		return file.ErrStorageCapacityExceeded
+	}
+
+	var (
+		logs    []file.Log
+		changes []file.Change
+	)
+
+	changeType := file.ChangeMove
+	if trash {
+		changeType = file.ChangeTrash
+	}
+
+	for _, e := range files {
+		dstPath := strings.Replace(e.Path, src.FullPath(), dest.FullPath(), 1)
+
+		if e.Path == src.FullPath() {
+			// update parent relationship
+			var err error
+			if e.IsDir {
+				err = permissionService.UpdateDirectoryParent(ctx, e.ID.String(), dest.ID.String(), src.ID.String())
+			} else {
+				err = permissionService.UpdateFileParent(ctx, e.ID.String(), dest.ID.String(), src.ID.String())
+			}
+
+			if err != nil {
+				return fmt.Errorf("update parent: %w", err)
+			}
+
+			logs = append(logs, file.NewLog(e.ID, userID, file.LogActionMove))
+
+			moved := e
+			moved.Path = dstPath
+			changes = append(changes, file.NewMoveChanges(src, &moved, changeType)...)
+		}
+
+		var err error
+		if trash {
+			err = fileStore.MoveToTrash(ctx, e.ID, dstPath)
+		} else {
+			err = fileStore.UpdatePath(ctx, e.ID, dstPath)
+		}
+
+		if err != nil {
+			return fmt.Errorf("move file: %w", err)
+		}
+	}
+
+	if chargeable {
+		// update user storage usage
+		if err := userStore.UpdateStorageUsage(ctx, dest.OwnerID, dest.Owner.StorageUsage+totalSize); err != nil {
+			return fmt.Errorf("update storage usage: %w", err)
+		}
+
+		if err := userStore.UpdateStorageUsage(ctx, src.OwnerID, src.Owner.StorageUsage-totalSize); err != nil {
+			return fmt.Errorf("update storage usage: %w", err)
+		}
+	}
+
+	var errs []error
+	if err := fileStore.WriteLogs(ctx, logs); err != nil {
+		errs = append(errs, fmt.Errorf("write logs: %w", err))
+	}
+
+	if err := RecordChanges(ctx, fileStore, pubSubService, changes...); err != nil {
+		errs = append(errs, err)
+	}
+
+	return notRecorded(errs...)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+var (
+	errInvalidWhence  = errors.New("invalid whence")
+	errNegativeOffset = errors.New("negative offset")
+)
+
+// FileReader reads the content of a file from the storage. Seeking is free,
+// the content is requested from the current offset on the next read.
+type FileReader struct {
+	ctx         context.Context
+	fileService file.Service
+	f           *file.File
+
+	r      io.ReadCloser
+	offset int64
+}
+
+var _ io.ReadSeekCloser = (*FileReader)(nil)
+
+func NewFileReader(ctx context.Context, fileService file.Service, f *file.File) *FileReader {
+	return &FileReader{
+		ctx:         ctx,
+		fileService: fileService,
+		f:           f,
+	}
+}
+
+func (fr *FileReader) Read(p []byte) (int, error) {
+	if fr.offset >= int64(fr.f.Size) {
+		return 0, io.EOF
+	}
+
+	if fr.r == nil {
+		r, _, err := fr.fileService.DownloadRange(fr.ctx, fr.f.ID.String(), fr.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+
+		fr.r = r
+	}
+
+	n, err := fr.r.Read(p)
+	fr.offset += int64(n)
+
+	return n, err
+}
+
+func (fr *FileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += fr.offset
+	case io.SeekEnd:
+		offset += int64(fr.f.Size)
+	default:
+		return 0, errInvalidWhence
+	}
+
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+
+	if offset != fr.offset && fr.r != nil {
+		fr.r.Close()
+		fr.r = nil
+	}
+
+	fr.offset = offset
+
+	return offset, nil
+}
+
+func (fr *FileReader) Close() error {
+	if fr.r == nil {
+		return nil
+	}
+
+	return fr.r.Close()
+}
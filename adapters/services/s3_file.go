@@ -0,0 +1,548 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// s3MinPartSize is the smallest part S3 accepts, except for the last one.
+	s3MinPartSize = 5 << 20
+	// s3MaxCopyPartSize is the largest part S3 copies on the server side.
+	s3MaxCopyPartSize = 5 << 30
+	// s3PartSize is the size of the parts uploaded by AppendFile.
+	s3PartSize = 16 << 20
+	// s3MD5Key and s3MD5StateKey hold the MD5 of the content and the state
+	// resuming it on append in the metadata of the object, since the ETag of
+	// a multipart upload is not an MD5.
+	s3MD5Key      = "Md5"
+	s3MD5StateKey = "Md5-State"
+)
+
+// S3FileService stores the files as objects of an S3 compatible bucket, the
+// object key is the file path without the leading slash. Directories only
+// exist as key prefixes.
+type S3FileService struct {
+	client *minio.Core
+	bucket string
+}
+
+func NewS3FileService(cfg *config.Config) (*S3FileService, error) {
+	client, err := minio.NewCore(cfg.S3.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3.AccessKey, cfg.S3.SecretKey, ""),
+		Secure: cfg.S3.UseSSL,
+		Region: cfg.S3.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+
+	ctx := context.Background()
+
+	exists, err := client.BucketExists(ctx, cfg.S3.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket: %w", err)
+	}
+
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.S3.Bucket, minio.MakeBucketOptions{Region: cfg.S3.Region}); err != nil {
+			return nil, fmt.Errorf("create bucket: %w", err)
+		}
+	}
+
+	return &S3FileService{
+		client: client,
+		bucket: cfg.S3.Bucket,
+	}, nil
+}
+
+func (s *S3FileService) GetMetadata(ctx context.Context, id string) (*file.Entry, error) {
+	key := s3Key(id)
+
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		entry := mapObjectToEntry(info)
+		return &entry, nil
+	}
+
+	if !isS3NotFound(err) {
+		return nil, fmt.Errorf("get metadata: %w", err)
+	}
+
+	// a directory is any prefix with objects under it
+	isDir, err := s.hasPrefix(ctx, key+"/")
+	if err != nil {
+		return nil, fmt.Errorf("get metadata: %w", err)
+	}
+
+	if !isDir {
+		return nil, file.ErrNotFound
+	}
+
+	return &file.Entry{
+		Name:     path.Base(key),
+		FullPath: "/" + key,
+		IsDir:    true,
+	}, nil
+}
+
+func (s *S3FileService) DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error) {
+	entry, err := s.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("get metadata: %w", err)
+	}
+
+	if entry.IsDir {
+		return nil, "", file.ErrNotFound
+	}
+
+	rc, _, _, err := s.client.GetObject(ctx, s.bucket, s3Key(id), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("download file: %w", err)
+	}
+
+	return rc, entry.MimeType, nil
+}
+
+func (s *S3FileService) DownloadRange(ctx context.Context, id string, offset int64, length int64) (io.ReadCloser, string, error) {
+	entry, err := s.GetMetadata(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("get metadata: %w", err)
+	}
+
+	if entry.IsDir {
+		return nil, "", file.ErrNotFound
+	}
+
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), entry.MimeType, nil
+	}
+
+	// a zero end means up to the end of the object, except from offset zero
+	// where there is no range at all
+	var end int64
+	if length > 0 {
+		end = offset + length - 1
+	}
+
+	opts := minio.GetObjectOptions{}
+	if offset > 0 || length > 0 {
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, "", fmt.Errorf("set range: %w", err)
+		}
+	}
+
+	rc, _, _, err := s.client.GetObject(ctx, s.bucket, s3Key(id), opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("download file: %w", err)
+	}
+
+	return rc, entry.MimeType, nil
+}
+
+// CreateFile spools the content to a temporary file first, its MD5 is only
+// known once it is read and the metadata of an object cannot change later.
+func (s *S3FileService) CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error) {
+	digest := md5.New()
+
+	f, size, err := spool(content, digest)
+	if err != nil {
+		return 0, err
+	}
+	defer removeSpool(f)
+
+	metadata, err := md5Metadata(digest)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := s.client.Client.PutObject(ctx, s.bucket, s3Key(id), f, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: metadata,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("put object: %w", err)
+	}
+
+	return info.Size, nil
+}
+
+// AppendFile rewrites the object with a multipart upload since S3 objects are
+// immutable. Objects large enough to be a part are copied on the server side,
+// smaller ones are downloaded and uploaded again in front of the content. The
+// MD5 resumes from the state kept with the object.
+func (s *S3FileService) AppendFile(ctx context.Context, content io.Reader, id string) (int64, error) {
+	key := s3Key(id)
+
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if !isS3NotFound(err) {
+			return 0, fmt.Errorf("stat object: %w", err)
+		}
+
+		return s.CreateFile(ctx, content, id, "application/octet-stream")
+	}
+
+	digest, err := s.resumeMD5(ctx, key, info)
+	if err != nil {
+		return 0, err
+	}
+
+	f, size, err := spool(content, digest)
+	if err != nil {
+		return 0, err
+	}
+	defer removeSpool(f)
+
+	metadata, err := md5Metadata(digest)
+	if err != nil {
+		return 0, err
+	}
+
+	uploadID, err := s.client.NewMultipartUpload(ctx, s.bucket, key, minio.PutObjectOptions{
+		ContentType:  info.ContentType,
+		UserMetadata: metadata,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("new multipart upload: %w", err)
+	}
+
+	parts, err := s.appendParts(ctx, key, uploadID, info, f)
+	if err != nil {
+		if err := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), s.bucket, key, uploadID); err != nil {
+			return 0, fmt.Errorf("abort multipart upload: %w", err)
+		}
+
+		return 0, err
+	}
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, s.bucket, key, uploadID, parts, minio.PutObjectOptions{}); err != nil {
+		return 0, fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	return size, nil
+}
+
+// appendParts copies the object as it was stated, the upload fails when it
+// changed in the meantime.
+func (s *S3FileService) appendParts(ctx context.Context, key string, uploadID string, info minio.ObjectInfo, content io.Reader) ([]minio.CompletePart, error) {
+	var parts []minio.CompletePart
+
+	if info.Size >= s3MinPartSize {
+		match := map[string]string{"x-amz-copy-source-if-match": `"` + info.ETag + `"`}
+
+		for _, r := range s3CopyRanges(info.Size) {
+			part, err := s.client.CopyObjectPart(ctx, s.bucket, key, s.bucket, key, uploadID, len(parts)+1, r[0], r[1], match)
+			if err != nil {
+				return nil, fmt.Errorf("copy object part: %w", err)
+			}
+
+			parts = append(parts, part)
+		}
+	} else if info.Size > 0 {
+		opts := minio.GetObjectOptions{}
+		if err := opts.SetMatchETag(info.ETag); err != nil {
+			return nil, fmt.Errorf("set match etag: %w", err)
+		}
+
+		rc, _, _, err := s.client.GetObject(ctx, s.bucket, key, opts)
+		if err != nil {
+			return nil, fmt.Errorf("get object: %w", err)
+		}
+		defer rc.Close()
+
+		content = io.MultiReader(rc, content)
+	}
+
+	buf := make([]byte, s3PartSize)
+	for {
+		n, err := io.ReadFull(content, buf)
+		if errors.Is(err, io.EOF) && len(parts) > 0 {
+			break
+		}
+
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("read content: %w", err)
+		}
+
+		part, perr := s.client.PutObjectPart(ctx, s.bucket, key, uploadID, len(parts)+1, bytes.NewReader(buf[:n]), int64(n), minio.PutObjectPartOptions{})
+		if perr != nil {
+			return nil, fmt.Errorf("put object part: %w", perr)
+		}
+
+		parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+
+		if err != nil {
+			break
+		}
+	}
+
+	return parts, nil
+}
+
+// resumeMD5 restores the MD5 state kept with the object, the objects stored
+// without one are hashed again.
+func (s *S3FileService) resumeMD5(ctx context.Context, key string, info minio.ObjectInfo) (hash.Hash, error) {
+	if digest, ok := restoreMD5(info.UserMetadata); ok {
+		return digest, nil
+	}
+
+	digest := md5.New()
+	if info.Size == 0 {
+		return digest, nil
+	}
+
+	rc, _, _, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+	defer rc.Close()
+
+	if _, err := io.Copy(digest, rc); err != nil {
+		return nil, fmt.Errorf("hash object: %w", err)
+	}
+
+	return digest, nil
+}
+
+// Delete removes an object, or every object under a directory.
+func (s *S3FileService) Delete(ctx context.Context, id string) error {
+	key := s3Key(id)
+
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		return nil
+	}
+
+	if !isS3NotFound(err) {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	isDir, err := s.hasPrefix(ctx, key+"/")
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if !isDir {
+		return fmt.Errorf("delete: %w", file.ErrNotFound)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := s.client.Client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: key + "/", Recursive: true})
+	for rerr := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		return fmt.Errorf("delete %s: %w", rerr.ObjectName, rerr.Err)
+	}
+
+	return nil
+}
+
+// ListEntries lists a directory in name order, the next page starts after lastFileName.
+func (s *S3FileService) ListEntries(ctx context.Context, dirpath string, lastFileName string, limit int) ([]file.Entry, error) {
+	prefix := s3Key(dirpath)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	// stop the listing once the page is full
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var startAfter string
+	if lastFileName != "" {
+		startAfter = prefix + lastFileName
+	}
+
+	entries := make([]file.Entry, 0, limit)
+	for info := range s.client.Client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, StartAfter: startAfter}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("list entries: %w", info.Err)
+		}
+
+		if len(entries) == limit {
+			break
+		}
+
+		entry := mapObjectToEntry(info)
+
+		// a directory sorts by its prefix, "name/" comes after "name" and
+		// may be listed again after it
+		if lastFileName != "" && entry.Name <= lastFileName {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 && prefix != "" && lastFileName == "" {
+		return nil, file.ErrNotFound
+	}
+
+	return entries, nil
+}
+
+func (s *S3FileService) DirStatus(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"Backend":  config.StorageBackendS3,
+		"Endpoint": s.client.EndpointURL().String(),
+		"Bucket":   s.bucket,
+	}, nil
+}
+
+// VolStatus counts the objects of the bucket and their size.
+func (s *S3FileService) VolStatus(ctx context.Context) (map[string]interface{}, error) {
+	var objects, size int64
+
+	for info := range s.client.Client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("list objects: %w", info.Err)
+		}
+
+		objects++
+		size += info.Size
+	}
+
+	return map[string]interface{}{
+		"Objects": objects,
+		"Size":    size,
+	}, nil
+}
+
+func (s *S3FileService) hasPrefix(ctx context.Context, prefix string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for info := range s.client.Client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true, MaxKeys: 1}) {
+		if info.Err != nil {
+			return false, info.Err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func s3Key(id string) string {
+	return strings.TrimPrefix(filepath.Join("/", id), "/")
+}
+
+func isS3NotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+
+	return resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound
+}
+
+func mapObjectToEntry(info minio.ObjectInfo) file.Entry {
+	e := file.Entry{
+		Name:      path.Base(info.Key),
+		FullPath:  "/" + strings.TrimSuffix(info.Key, "/"),
+		Size:      uint64(info.Size),
+		MimeType:  info.ContentType,
+		IsDir:     strings.HasSuffix(info.Key, "/"),
+		CreatedAt: info.LastModified,
+		UpdatedAt: info.LastModified,
+	}
+
+	// the md5 is kept in the metadata, otherwise the etag of a single part
+	// upload is the md5 of the content while multipart etags end with the
+	// number of parts
+	if md5, err := hex.DecodeString(info.UserMetadata[s3MD5Key]); err == nil && len(md5) == 16 {
+		e.MD5 = md5
+	} else if md5, err := hex.DecodeString(strings.Trim(info.ETag, `"`)); err == nil && len(md5) == 16 {
+		e.MD5 = md5
+	}
+
+	return e
+}
+
+// s3CopyRanges splits an object into the offsets and lengths of the parts
+// copying it. The parts are split evenly, a short one in the middle of the
+// upload would be under the minimum part size.
+func s3CopyRanges(size int64) [][2]int64 {
+	count := (size + s3MaxCopyPartSize - 1) / s3MaxCopyPartSize
+	partSize := (size + count - 1) / count
+
+	ranges := make([][2]int64, 0, count)
+	for offset := int64(0); offset < size; offset += partSize {
+		ranges = append(ranges, [2]int64{offset, min(partSize, size-offset)})
+	}
+
+	return ranges
+}
+
+func md5Metadata(digest hash.Hash) (map[string]string, error) {
+	state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("save md5: %w", err)
+	}
+
+	return map[string]string{
+		s3MD5Key:      hex.EncodeToString(digest.Sum(nil)),
+		s3MD5StateKey: base64.StdEncoding.EncodeToString(state),
+	}, nil
+}
+
+func restoreMD5(metadata map[string]string) (hash.Hash, bool) {
+	state, err := base64.StdEncoding.DecodeString(metadata[s3MD5StateKey])
+	if err != nil || len(state) == 0 {
+		return nil, false
+	}
+
+	digest := md5.New()
+	if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, false
+	}
+
+	return digest, true
+}
+
+// spool copies content to a temporary file hashed by digest, the file is
+// rewound to be uploaded.
+func spool(content io.Reader, digest hash.Hash) (*os.File, int64, error) {
+	f, err := os.CreateTemp("", "s3-upload-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("create temp file: %w", err)
+	}
+
+	size, err := io.Copy(f, io.TeeReader(content, digest))
+	if err != nil {
+		removeSpool(f)
+		return nil, 0, fmt.Errorf("read content: %w", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		removeSpool(f)
+		return nil, 0, fmt.Errorf("rewind temp file: %w", err)
+	}
+
+	return f, size, nil
+}
+
+func removeSpool(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"crypto/md5"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestS3CopyRanges(t *testing.T) {
+	tests := []struct {
+		size  int64
+		parts int
+	}{
+		{s3MinPartSize, 1},
+		{s3MaxCopyPartSize, 1},
+		{s3MaxCopyPartSize + 1, 2},
+		{2*s3MaxCopyPartSize + s3MinPartSize - 1, 3},
+		{12 << 30, 3},
+		{5 << 40, 1024},
+	}
+
+	for _, tt := range tests {
+		ranges := s3CopyRanges(tt.size)
+		if len(ranges) != tt.parts {
+			t.Errorf("s3CopyRanges(%d) = %d parts; want %d", tt.size, len(ranges), tt.parts)
+		}
+
+		var offset int64
+		for _, r := range ranges {
+			if r[0] != offset {
+				t.Errorf("s3CopyRanges(%d) part at %d; want %d", tt.size, r[0], offset)
+			}
+
+			if r[1] > s3MaxCopyPartSize || r[1] < s3MinPartSize {
+				t.Errorf("s3CopyRanges(%d) part of %d bytes", tt.size, r[1])
+			}
+
+			offset += r[1]
+		}
+
+		if offset != tt.size {
+			t.Errorf("s3CopyRanges(%d) covers %d bytes", tt.size, offset)
+		}
+	}
+}
+
+func TestS3MD5Metadata(t *testing.T) {
+	runs := []string{"", "hello", strings.Repeat("a", 1000), "world"}
+
+	var content string
+
+	metadata := map[string]string{}
+	for _, run := range runs {
+		digest, ok := restoreMD5(metadata)
+		if !ok {
+			digest = md5.New()
+		}
+
+		if _, err := io.WriteString(digest, run); err != nil {
+			t.Fatalf("WriteString() error = %v", err)
+		}
+
+		var err error
+		if metadata, err = md5Metadata(digest); err != nil {
+			t.Fatalf("md5Metadata() error = %v", err)
+		}
+
+		content += run
+
+		// the etag of the rebuilt object is the one of a multipart upload
+		info := minio.ObjectInfo{Key: "a.txt", ETag: "d41d8cd98f00b204e9800998ecf8427e-2", UserMetadata: metadata}
+
+		want := md5.Sum([]byte(content))
+		if entry := mapObjectToEntry(info); !bytes.Equal(entry.MD5, want[:]) {
+			t.Errorf("after %d bytes md5 = %x; want %x", len(content), entry.MD5, want)
+		}
+	}
+
+	if _, ok := restoreMD5(map[string]string{s3MD5StateKey: "bm90IGEgc3RhdGU="}); ok {
+		t.Error("restoreMD5() restored an invalid state")
+	}
+}
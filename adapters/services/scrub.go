@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// scrubBatchSize is the number of rows or storage entries checked at once,
+// the progress of the scrub is saved after each batch.
+const scrubBatchSize = 500
+
+// Scrub compares every uploaded file with its content in the storage, then
+// looks for the contents of the storage root that have no row. Up to
+// concurrency files are checked at the same time, the contents younger than
+// grace are skipped since their row may not be created yet. The scrub is
+// saved as finished, or failed, before returning.
+func Scrub(ctx context.Context, fileStore file.Store, fileService file.Service, sc *file.Scrub, concurrency int, grace time.Duration) error {
+	err := scrub(ctx, fileStore, fileService, sc, concurrency, grace)
+
+	sc.Finish(err)
+
+	// the scrub is saved even when it was interrupted
+	if err := fileStore.UpdateScrub(context.WithoutCancel(ctx), sc); err != nil {
+		return fmt.Errorf("update scrub: %w", err)
+	}
+
+	return err
+}
+
+func scrub(ctx context.Context, fileStore file.Store, fileService file.Service, sc *file.Scrub, concurrency int, grace time.Duration) error {
+	cursor := pagination.NewCursor("", scrubBatchSize)
+
+	for {
+		files, err := fileStore.ListScrubFiles(ctx, cursor)
+		if err != nil {
+			return fmt.Errorf("list files: %w", err)
+		}
+
+		issues := make([]*file.ScrubIssue, len(files))
+
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(max(concurrency, 1))
+
+		for i := range files {
+			i := i
+			g.Go(func() (err error) {
+				issues[i], err = checkFile(gctx, fileStore, fileService, sc.Options, &files[i])
+				return err
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		sc.Checked += len(files)
+		if err := saveIssues(ctx, fileStore, sc, issues); err != nil {
+			return err
+		}
+
+		next := cursor.NextToken()
+		if next == "" {
+			break
+		}
+
+		cursor = pagination.NewCursor(next, scrubBatchSize)
+	}
+
+	return findOrphans(ctx, fileStore, fileService, sc, grace)
+}
+
+// checkFile returns the issue of f, nil when the row and the storage agree.
+// The action of the options is applied right away. A storage error only fails
+// the check of f, the other files are still checked.
+func checkFile(ctx context.Context, fileStore file.Store, fileService file.Service, options file.ScrubOptions, f *file.File) (*file.ScrubIssue, error) {
+	issue := &file.ScrubIssue{
+		FileID:   &f.ID,
+		Path:     f.FullPath(),
+		Expected: strconv.FormatUint(f.Size, 10),
+	}
+
+	entry, err := fileService.GetMetadata(ctx, f.ID.String())
+	switch {
+	case errors.Is(err, file.ErrNotFound):
+		issue.Kind = file.IssueMissing
+	case err != nil && ctx.Err() != nil:
+		return nil, ctx.Err()
+	case err != nil:
+		issue.Kind = file.IssueUnchecked
+		issue.Actual = err.Error()
+
+		return issue, nil
+	case entry.IsDir:
+		issue.Kind = file.IssueMissing
+		issue.Actual = "directory"
+	case entry.Size != f.Size:
+		issue.Kind = file.IssueSizeMismatch
+		issue.Actual = strconv.FormatUint(entry.Size, 10)
+	case len(f.MD5) > 0 && len(entry.MD5) > 0 && !bytes.Equal(f.MD5, entry.MD5):
+		// the storage does not always know the MD5, e.g. after an append
+		issue.Kind = file.IssueMD5Mismatch
+		issue.Expected = hex.EncodeToString(f.MD5)
+		issue.Actual = hex.EncodeToString(entry.MD5)
+	default:
+		return nil, nil
+	}
+
+	switch {
+	case options.Repair && issue.Kind != file.IssueMissing:
+		if err := fileStore.RepairFile(ctx, f.ID, entry.Size, entry.MD5); err != nil {
+			return nil, fmt.Errorf("repair file: %w", err)
+		}
+
+		issue.Action = file.ActionRepaired
+	case options.Quarantine && !f.Quarantined():
+		if err := fileStore.QuarantineFile(ctx, f.ID); err != nil {
+			return nil, fmt.Errorf("quarantine file: %w", err)
+		}
+
+		issue.Action = file.ActionQuarantined
+	}
+
+	return issue, nil
+}
+
+// findOrphans reports the contents of the storage root that have no row.
+// Only the names that are file ids are considered, the root also holds the
+// assets and the backend own data.
+func findOrphans(ctx context.Context, fileStore file.Store, fileService file.Service, sc *file.Scrub, grace time.Duration) error {
+	var (
+		now  = time.Now()
+		last string
+	)
+
+	for {
+		entries, err := fileService.ListEntries(ctx, "/", last, scrubBatchSize)
+		if err != nil {
+			return fmt.Errorf("list entries: %w", err)
+		}
+
+		var (
+			candidates = make(map[uuid.UUID]file.Entry)
+			ids        []uuid.UUID
+		)
+
+		for _, e := range entries {
+			id, err := uuid.Parse(e.Name)
+			if err != nil || e.IsDir || now.Sub(latest(e.CreatedAt, e.UpdatedAt)) < grace {
+				continue
+			}
+
+			candidates[id] = e
+			ids = append(ids, id)
+		}
+
+		if len(ids) > 0 {
+			existing, err := fileStore.ListExistingIDs(ctx, ids)
+			if err != nil {
+				return fmt.Errorf("list files: %w", err)
+			}
+
+			for _, id := range existing {
+				delete(candidates, id)
+			}
+
+			issues := make([]*file.ScrubIssue, 0, len(candidates))
+			for _, id := range ids {
+				e, ok := candidates[id]
+				if !ok {
+					continue
+				}
+
+				issues = append(issues, &file.ScrubIssue{
+					Path:   e.FullPath,
+					Kind:   file.IssueOrphaned,
+					Actual: strconv.FormatUint(e.Size, 10),
+				})
+			}
+
+			if err := saveIssues(ctx, fileStore, sc, issues); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < scrubBatchSize {
+			return nil
+		}
+
+		last = entries[len(entries)-1].Name
+	}
+}
+
+// saveIssues records the issues of a batch and the progress of the scrub.
+func saveIssues(ctx context.Context, fileStore file.Store, sc *file.Scrub, issues []*file.ScrubIssue) error {
+	var found []file.ScrubIssue
+	for _, issue := range issues {
+		if issue == nil {
+			continue
+		}
+
+		issue.ScrubID = sc.ID
+		sc.Count(issue)
+		found = append(found, *issue)
+	}
+
+	if err := fileStore.CreateScrubIssues(ctx, found); err != nil {
+		return fmt.Errorf("create issues: %w", err)
+	}
+
+	if err := fileStore.UpdateScrub(ctx, sc); err != nil {
+		return fmt.Errorf("update scrub: %w", err)
+	}
+
+	return nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+)
+
+// backfillBatchSize is the number of files sent to the thumbnail worker in one job.
+const backfillBatchSize = 100
+
+// EnqueueBackfill sends every file matching the backfill filter to the
+// thumbnail queue and records the total once all of them are enqueued.
+func EnqueueBackfill(ctx context.Context, fileStore file.Store, pubsubService pubsub.Service, b *file.Backfill) error {
+	var (
+		cursor = pagination.NewCursor("", backfillBatchSize)
+		total  int
+	)
+
+	for {
+		files, err := fileStore.ListBackfillFiles(ctx, b.Filter, cursor)
+		if err != nil {
+			return fmt.Errorf("list files: %w", err)
+		}
+
+		if len(files) > 0 {
+			payload := make([]map[string]string, len(files))
+			for i, f := range files {
+				payload[i] = map[string]string{"id": f.ID.String(), "mime": f.MimeType, "backfill": b.ID.String()}
+			}
+
+			message, err := json.Marshal(payload)
+			if err != nil {
+				return fmt.Errorf("marshal payload: %w", err)
+			}
+
+			if err := pubsubService.Enqueue(ctx, "thumbnails", string(message)); err != nil {
+				return fmt.Errorf("enqueue: %w", err)
+			}
+
+			total += len(files)
+		}
+
+		next := cursor.NextToken()
+		if next == "" {
+			break
+		}
+
+		cursor = pagination.NewCursor(next, backfillBatchSize)
+	}
+
+	if err := fileStore.FinishBackfillEnqueue(ctx, b.ID, total); err != nil {
+		return fmt.Errorf("finish enqueue: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/google/uuid"
+)
+
+// CreateFile stores the content of f under parent, then creates its row and
+// the permissions of its owner. The ID, name and owner of f are set by the
+// caller, the size, MIME type and MD5 are taken from the storage.
+func CreateFile(ctx context.Context, fileStore file.Store, fileService file.Service, permissionService permission.Service,
+	parent *file.File, f *file.File, reader io.Reader) error {
+	contentType, src, err := app.DetectContentType(reader)
+	if err != nil {
+		return fmt.Errorf("detect content type: %w", err)
+	}
+
+	_, err = fileService.CreateFile(ctx, src, f.ID.String(), contentType)
+	if err != nil {
+		return fmt.Errorf("upload file: %w", err)
+	}
+
+	entry, err := fileService.GetMetadata(ctx, f.ID.String())
+	if err != nil {
+		return fmt.Errorf("get metadata: %w", err)
+	}
+
+	f.Size = entry.Size
+	f.Mode = entry.Mode
+	f.MimeType = entry.MimeType
+	f.MD5 = entry.MD5
+	f.IsDir = false
+	f.Path = parent.FullPath()
+
+	if err := fileStore.Create(ctx, f); err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+
+	// create file permissions
+	if err := permissionService.CreateFilePermissions(ctx, f.OwnerID.String(), f.ID.String(), parent.ID.String()); err != nil {
+		return fmt.Errorf("create file permissions: %w", err)
+	}
+
+	return nil
+}
+
+// CopyFile stores a copy of the content of src as f under parent like
+// CreateFile. The copy keeps the thumbnails and the metadata of src, and the
+// tags the owner of f put on it.
+func CopyFile(ctx context.Context, fileStore file.Store, fileService file.Service, permissionService permission.Service,
+	parent *file.File, src *file.File, f *file.File) error {
+	f.Thumbnail = src.Thumbnail
+	f.Thumbnails = slices.Clone(src.Thumbnails)
+	f.Metadata = maps.Clone(src.Metadata)
+
+	reader, _, err := fileService.DownloadFile(ctx, src.ID.String())
+	if err != nil {
+		return fmt.Errorf("download file: %w", err)
+	}
+	defer reader.Close()
+
+	if err := CreateFile(ctx, fileStore, fileService, permissionService, parent, f, reader); err != nil {
+		return err
+	}
+
+	tags, err := fileStore.ListFileTags(ctx, src.ID, f.OwnerID)
+	if err != nil {
+		return fmt.Errorf("list tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		// the shared tags of another owner stay on the files of that owner
+		if tag.UserID != f.OwnerID {
+			continue
+		}
+
+		// a tag deleted in the meantime is skipped
+		if err := fileStore.TagFiles(ctx, tag.ID, []uuid.UUID{f.ID}); err != nil && !errors.Is(err, file.ErrNotFound) {
+			return fmt.Errorf("tag file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateDirectory creates the row of the directory f under parent and the
+// permissions of its owner.
+func CreateDirectory(ctx context.Context, fileStore file.Store, permissionService permission.Service, parent *file.File, f *file.File) error {
+	f.Path = parent.FullPath()
+
+	if err := fileStore.Create(ctx, f); err != nil {
+		return err
+	}
+
+	if err := permissionService.CreateDirectoryPermissions(ctx, f.OwnerID.String(), f.ID.String(), parent.ID.String()); err != nil {
+		return fmt.Errorf("create directory permissions: %w", err)
+	}
+
+	return nil
+}
+
+// PutFile stores f under parent like CreateFile, charges its size to the
+// owner of parent and requests its thumbnails. existing, the file previously
+// at the same path, is moved to the trash of the owner of f. ErrNotRecorded
+// is returned when f was stored but it could not be recorded.
+func PutFile(ctx context.Context, fileStore file.Store, userStore identity.Store, fileService file.Service, permissionService permission.Service,
+	pubSubService pubsub.Service, parent *file.File, existing *file.File, f *file.File, reader io.Reader) error {
+	if err := CreateFile(ctx, fileStore, fileService, permissionService, parent, f, reader); err != nil {
+		return err
+	}
+
+	return putFile(ctx, fileStore, userStore, permissionService, pubSubService, parent, existing, f)
+}
+
+// PutCopy stores a copy of src as f under parent like CopyFile, then charges
+// and records it like PutFile.
+func PutCopy(ctx context.Context, fileStore file.Store, userStore identity.Store, fileService file.Service, permissionService permission.Service,
+	pubSubService pubsub.Service, parent *file.File, existing *file.File, src *file.File, f *file.File) error {
+	if err := CopyFile(ctx, fileStore, fileService, permissionService, parent, src, f); err != nil {
+		return err
+	}
+
+	return putFile(ctx, fileStore, userStore, permissionService, pubSubService, parent, existing, f)
+}
+
+// putFile charges f, created under parent, to the owner of parent and
+// requests its thumbnails unless it has some already.
+func putFile(ctx context.Context, fileStore file.Store, userStore identity.Store, permissionService permission.Service,
+	pubSubService pubsub.Service, parent *file.File, existing *file.File, f *file.File) error {
+	owner, err := userStore.GetByID(ctx, parent.OwnerID.String())
+	if err != nil {
+		return fmt.Errorf("get owner: %w", err)
+	}
+
+	// update user storage usage
+	owner.StorageUsage += f.Size
+	if err := userStore.UpdateStorageUsage(ctx, owner.ID, owner.StorageUsage); err != nil {
+		return fmt.Errorf("update storage usage: %w", err)
+	}
+
+	var errs []error
+	if f.Thumbnail == nil {
+		if err := enqueueThumbnails(ctx, pubSubService, f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := fileStore.WriteLogs(ctx, []file.Log{file.NewLog(f.ID, f.OwnerID, file.LogActionCreate)}); err != nil {
+		errs = append(errs, fmt.Errorf("write logs: %w", err))
+	}
+
+	if err := RecordChanges(ctx, fileStore, pubSubService, file.NewChange(f, file.ChangeCreate)); err != nil {
+		errs = append(errs, err)
+	}
+
+	if existing == nil {
+		return notRecorded(errs...)
+	}
+
+	trash, err := fileStore.GetTrashByUserID(ctx, f.OwnerID)
+	if err != nil {
+		return fmt.Errorf("get trash: %w", err)
+	}
+
+	src := *parent
+	src.Owner = owner
+
+	err = MoveFiles(ctx, fileStore, userStore, permissionService, pubSubService, f.OwnerID, &src, trash, []file.File{*existing}, true)
+	if err != nil && !errors.Is(err, ErrNotRecorded) {
+		return err
+	}
+
+	return notRecorded(append(errs, err)...)
+}
+
+// enqueueThumbnails requests the thumbnails of f.
+func enqueueThumbnails(ctx context.Context, pubSubService pubsub.Service, f *file.File) error {
+	message, err := json.Marshal([]map[string]string{{"id": f.ID.String(), "mime": f.MimeType}})
+	if err != nil {
+		return fmt.Errorf("marshal thumbnail message: %w", err)
+	}
+
+	if err := pubSubService.Enqueue(ctx, "thumbnails", string(message)); err != nil {
+		return fmt.Errorf("enqueue thumbnails: %w", err)
+	}
+
+	return nil
+}
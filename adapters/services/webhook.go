@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+// nonPublicPrefixes are the ranges the webhooks cannot reach besides the
+// private, loopback and link-local addresses: "this network", shared address
+// space (used by some cloud metadata services), benchmarking and reserved.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// CheckWebhookURL checks that rawURL is an https URL, or http when allowHTTP
+// is set, whose host only resolves to public addresses.
+func CheckWebhookURL(ctx context.Context, rawURL string, allowHTTP bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", file.ErrWebhookURLNotAllowed, err)
+	}
+
+	if u.Scheme != "https" && !(allowHTTP && u.Scheme == "http") {
+		return fmt.Errorf("%w: scheme %s", file.ErrWebhookURLNotAllowed, u.Scheme)
+	}
+
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in url", file.ErrWebhookURLNotAllowed)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", file.ErrWebhookURLNotAllowed, u.Hostname())
+	}
+
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return fmt.Errorf("%w: %s is not a public address", file.ErrWebhookURLNotAllowed, u.Hostname())
+		}
+	}
+
+	return nil
+}
+
+// NewWebhookClient returns the client sending the deliveries. The address is
+// checked again when connecting, the host may resolve to another address than
+// when the webhook was created. Redirects are not followed.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return file.ErrWebhookURLNotAllowed
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,183 @@
+package sftpfs
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sync"
+
+	"golang.org/x/net/webdav"
+)
+
+const (
+	// window is how far behind the data read last a read can start, the
+	// request server reads a file with several workers so the reads arrive
+	// slightly out of order.
+	window = 4 << 20
+
+	// maxPending bounds the size of the writes kept until the writes before
+	// them arrive.
+	maxPending = 64 << 20
+)
+
+var (
+	ErrRewrite         = errors.New("the content of a file cannot be rewritten")
+	ErrTooManyPending  = errors.New("too many writes out of order")
+	ErrIncompleteWrite = errors.New("the writes of the file left gaps")
+)
+
+// readerAt reads a file sequentially, it only seeks when a read is too far
+// from the data read last.
+type readerAt struct {
+	mu  sync.Mutex
+	f   webdav.File
+	buf []byte
+	off int64 // offset of buf in the file
+}
+
+func newReaderAt(f webdav.File) *readerAt {
+	return &readerAt{f: f}
+}
+
+func (ra *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	end := ra.off + int64(len(ra.buf))
+	if off < ra.off || off > end+window {
+		if _, err := ra.f.Seek(off, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		ra.buf, ra.off, end = ra.buf[:0], off, off
+	}
+
+	var err error
+	for err == nil && end < off+int64(len(p)) {
+		ra.buf = slices.Grow(ra.buf, int(off+int64(len(p))-end))
+
+		var n int
+		n, err = ra.f.Read(ra.buf[len(ra.buf):cap(ra.buf)])
+		ra.buf = ra.buf[:len(ra.buf)+n]
+		end += int64(n)
+	}
+
+	n := 0
+	if off < end {
+		n = copy(p, ra.buf[off-ra.off:])
+	}
+
+	// only the last window of data is kept, it is trimmed once in a while
+	if extra := len(ra.buf) - window; extra > window {
+		ra.buf = append(ra.buf[:0], ra.buf[extra:]...)
+		ra.off += int64(extra)
+	}
+
+	if n < len(p) {
+		if err == nil {
+			err = io.EOF
+		}
+
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (ra *readerAt) Close() error {
+	return ra.f.Close()
+}
+
+// aborter is implemented by the files of the WebDAV file system opened for
+// writing.
+type aborter interface {
+	Abort(err error)
+}
+
+// writerAt writes a file sequentially, the writes that arrive before the
+// writes preceding them are kept until these arrive.
+type writerAt struct {
+	mu      sync.Mutex
+	f       webdav.File
+	written int64
+	pending map[int64][]byte
+	size    int // of the pending writes
+	err     error
+}
+
+func newWriterAt(f webdav.File) *writerAt {
+	return &writerAt{f: f, pending: make(map[int64][]byte)}
+}
+
+func (wa *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+
+	if wa.err != nil {
+		return 0, wa.err
+	}
+
+	switch {
+	case off < wa.written:
+		return 0, ErrRewrite
+	case off > wa.written:
+		if wa.size+len(p) > maxPending {
+			return 0, ErrTooManyPending
+		}
+
+		wa.pending[off] = slices.Clone(p)
+		wa.size += len(p)
+
+		return len(p), nil
+	}
+
+	if err := wa.write(p); err != nil {
+		return 0, err
+	}
+
+	for {
+		next, ok := wa.pending[wa.written]
+		if !ok {
+			break
+		}
+
+		delete(wa.pending, wa.written)
+		wa.size -= len(next)
+
+		if err := wa.write(next); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (wa *writerAt) write(p []byte) error {
+	n, err := wa.f.Write(p)
+	wa.written += int64(n)
+
+	if err != nil {
+		wa.err = err
+	}
+
+	return err
+}
+
+// TransferError discards the file when the connection is lost.
+func (wa *writerAt) TransferError(err error) {
+	wa.abort(err)
+}
+
+func (wa *writerAt) Close() error {
+	if len(wa.pending) > 0 {
+		wa.abort(ErrIncompleteWrite)
+	}
+
+	return wa.f.Close()
+}
+
+func (wa *writerAt) abort(err error) {
+	if a, ok := wa.f.(aborter); ok {
+		a.Abort(err)
+	}
+}
@@ -0,0 +1,207 @@
+package sftpfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// memFile is an in-memory webdav.File, it counts the seeks and records the
+// abort of a write.
+type memFile struct {
+	r        *bytes.Reader
+	w        bytes.Buffer
+	seeks    int
+	writeErr error
+	aborted  error
+}
+
+func (f *memFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.seeks++
+
+	return f.r.Seek(offset, whence)
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.writeErr != nil {
+		return 0, f.writeErr
+	}
+
+	return f.w.Write(p)
+}
+
+func (f *memFile) Close() error                             { return nil }
+func (f *memFile) Readdir(count int) ([]fs.FileInfo, error) { return nil, nil }
+func (f *memFile) Stat() (fs.FileInfo, error)               { return nil, os.ErrInvalid }
+func (f *memFile) Abort(err error)                          { f.aborted = err }
+
+func testContent(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i * 31 / 7)
+	}
+
+	return b
+}
+
+func TestReaderAt(t *testing.T) {
+	const (
+		chunk = 32 << 10
+		size  = 3 * window
+	)
+
+	type read struct {
+		off int64
+		len int
+	}
+
+	tests := []struct {
+		name  string
+		reads []read
+		seeks int
+	}{
+		{"sequential", []read{{0, chunk}, {chunk, chunk}, {2 * chunk, chunk}}, 0},
+		{"reordered", []read{{chunk, chunk}, {0, chunk}, {3 * chunk, chunk}, {2 * chunk, chunk}}, 0},
+		{"small gap", []read{{0, chunk}, {window, chunk}, {chunk, chunk}}, 0},
+		{"gap past the window", []read{{0, chunk}, {2*window + chunk, chunk}}, 1},
+		{"back before the buffer", []read{{0, chunk}, {2*window + chunk, chunk}, {chunk, chunk}}, 2},
+		{"long sequential read", []read{{0, window}, {window, window}, {2 * window, window}, {window / 2, chunk}}, 1},
+		{"last bytes", []read{{size - chunk, chunk}}, 1},
+	}
+
+	content := testContent(size)
+
+	for _, tt := range tests {
+		f := &memFile{r: bytes.NewReader(content)}
+		ra := newReaderAt(f)
+
+		for _, r := range tt.reads {
+			p := make([]byte, r.len)
+
+			n, err := ra.ReadAt(p, r.off)
+			if err != nil || n != r.len {
+				t.Errorf("%s: ReadAt(%d, %d) = %d, %v; want %d, nil", tt.name, r.len, r.off, n, err, r.len)
+				continue
+			}
+
+			if !bytes.Equal(p, content[r.off:r.off+int64(r.len)]) {
+				t.Errorf("%s: ReadAt(%d, %d) returned other data", tt.name, r.len, r.off)
+			}
+		}
+
+		if f.seeks != tt.seeks {
+			t.Errorf("%s: %d seeks; want %d", tt.name, f.seeks, tt.seeks)
+		}
+	}
+}
+
+func TestReaderAtEOF(t *testing.T) {
+	content := testContent(100)
+
+	tests := []struct {
+		off  int64
+		len  int
+		want int
+	}{
+		{90, 20, 10},
+		{100, 10, 0},
+		{150, 10, 0},
+	}
+
+	for _, tt := range tests {
+		ra := newReaderAt(&memFile{r: bytes.NewReader(content)})
+
+		p := make([]byte, tt.len)
+
+		n, err := ra.ReadAt(p, tt.off)
+		if n != tt.want || !errors.Is(err, io.EOF) {
+			t.Errorf("ReadAt(%d, %d) = %d, %v; want %d, EOF", tt.len, tt.off, n, err, tt.want)
+		}
+
+		if !bytes.Equal(p[:n], content[min(tt.off, 100):min(tt.off, 100)+int64(n)]) {
+			t.Errorf("ReadAt(%d, %d) returned other data", tt.len, tt.off)
+		}
+	}
+}
+
+func TestWriterAt(t *testing.T) {
+	type write struct {
+		off int64
+		len int
+		err error
+	}
+
+	tests := []struct {
+		name    string
+		writes  []write
+		size    int
+		aborted error
+	}{
+		{"sequential", []write{{0, 10, nil}, {10, 10, nil}, {20, 5, nil}}, 25, nil},
+		{"reversed", []write{{20, 5, nil}, {10, 10, nil}, {0, 10, nil}}, 25, nil},
+		{"reordered", []write{{10, 10, nil}, {0, 10, nil}, {30, 10, nil}, {20, 10, nil}}, 40, nil},
+		{"gap left", []write{{0, 10, nil}, {20, 10, nil}}, 10, ErrIncompleteWrite},
+		{"rewrite", []write{{0, 10, nil}, {5, 10, ErrRewrite}, {10, 10, nil}}, 20, nil},
+		{"too many pending", []write{{1, maxPending, nil}, {maxPending + 1, 1, ErrTooManyPending}}, 0, ErrIncompleteWrite},
+	}
+
+	for _, tt := range tests {
+		f := &memFile{}
+		wa := newWriterAt(f)
+
+		for _, w := range tt.writes {
+			n, err := wa.WriteAt(testContent(int(w.off) + w.len)[w.off:], w.off)
+			if !errors.Is(err, w.err) || (err == nil && n != w.len) {
+				t.Errorf("%s: WriteAt(%d, %d) = %d, %v; want %d, %v", tt.name, w.len, w.off, n, err, w.len, w.err)
+			}
+		}
+
+		if err := wa.Close(); err != nil {
+			t.Errorf("%s: Close() error = %v", tt.name, err)
+		}
+
+		if !errors.Is(f.aborted, tt.aborted) {
+			t.Errorf("%s: aborted with %v; want %v", tt.name, f.aborted, tt.aborted)
+		}
+
+		if !bytes.Equal(f.w.Bytes(), testContent(tt.size)) {
+			t.Errorf("%s: wrote %d bytes; want %d", tt.name, f.w.Len(), tt.size)
+		}
+	}
+}
+
+func TestWriterAtError(t *testing.T) {
+	errDisk := errors.New("disk full")
+
+	f := &memFile{}
+	wa := newWriterAt(f)
+
+	if _, err := wa.WriteAt(testContent(10), 0); err != nil {
+		t.Fatalf("WriteAt() error = %v", err)
+	}
+
+	f.writeErr = errDisk
+
+	// the pending writes fail once the write before them fails
+	if _, err := wa.WriteAt(testContent(20)[15:], 15); err != nil {
+		t.Fatalf("WriteAt() of a pending write error = %v", err)
+	}
+
+	if _, err := wa.WriteAt(testContent(15)[10:], 10); !errors.Is(err, errDisk) {
+		t.Errorf("WriteAt() error = %v; want %v", err, errDisk)
+	}
+
+	if _, err := wa.WriteAt(testContent(25)[20:], 20); !errors.Is(err, errDisk) {
+		t.Errorf("WriteAt() after a failure error = %v; want %v", err, errDisk)
+	}
+
+	wa.TransferError(io.ErrUnexpectedEOF)
+	if !errors.Is(f.aborted, io.ErrUnexpectedEOF) {
+		t.Errorf("TransferError() aborted with %v; want %v", f.aborted, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,176 @@
+// Package sftpfs serves the tree of a user over SFTP. The requests go through
+// the WebDAV file system of the user, so that they are subject to the same
+// permission checks, storage usage accounting and trash.
+package sftpfs
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/net/webdav"
+)
+
+// NewHandlers returns the handlers of a request server serving fs.
+func NewHandlers(fs webdav.FileSystem) sftp.Handlers {
+	h := &handlers{fs: fs}
+
+	return sftp.Handlers{FileGet: h, FilePut: h, FileCmd: h, FileList: h}
+}
+
+type handlers struct {
+	fs webdav.FileSystem
+}
+
+func (h *handlers) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	f, err := h.fs.OpenFile(r.Context(), r.Filepath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		f.Close()
+		return nil, &os.PathError{Op: "open", Path: r.Filepath, Err: os.ErrInvalid}
+	}
+
+	return newReaderAt(f), nil
+}
+
+func (h *handlers) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	pflags := r.Pflags()
+
+	// the content of a file is replaced as a whole
+	if pflags.Append {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+
+	flag := os.O_WRONLY | os.O_TRUNC
+	if pflags.Creat {
+		flag |= os.O_CREATE
+	}
+
+	if pflags.Excl {
+		flag |= os.O_EXCL
+	}
+
+	f, err := h.fs.OpenFile(r.Context(), r.Filepath, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWriterAt(f), nil
+}
+
+func (h *handlers) Filecmd(r *sftp.Request) error {
+	ctx := r.Context()
+
+	switch r.Method {
+	case "Setstat":
+		// the modes and times are not kept, but a file cannot be truncated
+		if r.AttrFlags().Size {
+			return sftp.ErrSSHFxOpUnsupported
+		}
+
+		return nil
+	case "Rename", "PosixRename":
+		return h.fs.Rename(ctx, r.Filepath, r.Target)
+	case "Rmdir":
+		return h.rmdir(ctx, r.Filepath)
+	case "Remove":
+		info, err := h.fs.Stat(ctx, r.Filepath)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return &os.PathError{Op: "remove", Path: r.Filepath, Err: os.ErrInvalid}
+		}
+
+		return h.fs.RemoveAll(ctx, r.Filepath)
+	case "Mkdir":
+		return h.fs.Mkdir(ctx, r.Filepath, 0o755)
+	default:
+		return sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+// rmdir moves the directory at name to the trash, as long as it is empty.
+func (h *handlers) rmdir(ctx context.Context, name string) error {
+	f, err := h.fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return &os.PathError{Op: "rmdir", Path: name, Err: os.ErrInvalid}
+	}
+
+	children, err := f.Readdir(1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	if len(children) > 0 {
+		return &os.PathError{Op: "rmdir", Path: name, Err: os.ErrExist}
+	}
+
+	return h.fs.RemoveAll(ctx, name)
+}
+
+func (h *handlers) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case "List":
+		f, err := h.fs.OpenFile(ctx, r.Filepath, os.O_RDONLY, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		infos, err := f.Readdir(0)
+		if err != nil {
+			return nil, err
+		}
+
+		return listerAt(infos), nil
+	case "Stat", "Lstat":
+		info, err := h.fs.Stat(ctx, r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+
+		return listerAt{info}, nil
+	default:
+		// there are no links in the tree
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
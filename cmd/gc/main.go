@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pageSize is the number of entries listed and checked at once.
+const pageSize = 500
+
+// directories holds the generated assets, they are referenced by the files
+// and users tables but never deleted with them. The export archives are kept
+// until they expire, the parts of the multipart uploads until the uploads are
+// completed or aborted.
+var directories = []string{
+	"/assets/images",
+	"/assets/renditions",
+	"/assets/streams",
+	file.ExportsDir,
+	file.MultipartDir,
+}
+
+type collector struct {
+	applog      *zap.SugaredLogger
+	userStore   identity.Store
+	fileStore   file.Store
+	fileService file.Service
+	grace       time.Duration
+	uploadTTL   time.Duration
+	dryRun      bool
+	now         time.Time
+}
+
+type report struct {
+	Scanned    int
+	Referenced int
+	Recent     int
+	Orphaned   int
+	Bytes      uint64
+}
+
+func main() {
+	var (
+		grace     = flag.Duration("grace", 24*time.Hour, "keep unreferenced assets younger than this")
+		uploadTTL = flag.Duration("upload-ttl", 7*24*time.Hour, "abort the multipart uploads started before this")
+		dryRun    = flag.Bool("dry-run", false, "only report the assets that would be deleted")
+	)
+
+	flag.Parse()
+
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	c := &collector{
+		applog:      applog,
+		userStore:   postgrestore.NewUserStore(db),
+		fileStore:   postgrestore.NewFileStore(db),
+		fileService: services.NewFileService(cfg),
+		grace:       *grace,
+		uploadTTL:   *uploadTTL,
+		dryRun:      *dryRun,
+		now:         time.Now(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	action := "deleted"
+	if c.dryRun {
+		action = "to delete"
+	}
+
+	// the parts of the aborted uploads are collected with the other assets
+	n, err := c.abortStaleUploads(ctx)
+	if err != nil {
+		applog.Fatalf("cannot abort stale uploads: %v\n", err)
+	}
+
+	fmt.Printf("%s: %d stale uploads aborted\n", file.MultipartDir, n)
+
+	for _, dir := range directories {
+		r, err := c.collect(ctx, dir)
+		if err != nil {
+			applog.Fatalf("cannot collect %s: %v\n", dir, err)
+		}
+
+		fmt.Printf("%s: %d scanned, %d referenced, %d within grace period, %d orphaned %s (%d bytes)\n",
+			dir, r.Scanned, r.Referenced, r.Recent, r.Orphaned, action, r.Bytes)
+	}
+}
+
+// collect deletes the unreferenced entries of dir that are older than the
+// grace period. Entries are checked one page at a time.
+func (c *collector) collect(ctx context.Context, dir string) (*report, error) {
+	var (
+		r    = &report{}
+		last string
+	)
+
+	for {
+		entries, err := c.fileService.ListEntries(ctx, dir, last, pageSize)
+		if err != nil {
+			if errors.Is(err, file.ErrNotFound) {
+				return r, nil
+			}
+
+			return nil, fmt.Errorf("list entries: %v", err)
+		}
+
+		referenced, err := c.referenced(ctx, dir, entries)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			r.Scanned++
+
+			switch {
+			case referenced[e.Name]:
+				r.Referenced++
+			case c.now.Sub(latest(e.CreatedAt, e.UpdatedAt)) < c.grace:
+				r.Recent++
+			default:
+				size, err := c.remove(ctx, e)
+				if err != nil {
+					return nil, err
+				}
+
+				r.Orphaned++
+				r.Bytes += size
+			}
+		}
+
+		if len(entries) < pageSize {
+			return r, nil
+		}
+
+		last = entries[len(entries)-1].Name
+	}
+}
+
+// referenced returns the names of the entries that are still in use.
+func (c *collector) referenced(ctx context.Context, dir string, entries []file.Entry) (map[string]bool, error) {
+	if dir == file.ExportsDir {
+		return c.referencedExports(ctx, entries)
+	}
+
+	if dir == file.MultipartDir {
+		return c.referencedUploads(ctx, entries)
+	}
+
+	var (
+		referenced = make(map[string]bool)
+		ids        []string
+		names      = make([]string, len(entries))
+		urls       = make([]string, len(entries))
+	)
+
+	for i, e := range entries {
+		if id, ok := file.AssetFileID(e.Name); ok {
+			ids = append(ids, id.String())
+		}
+
+		names[i] = e.Name
+		urls[i] = filepath.Join("/api", dir, e.Name)
+	}
+
+	if len(ids) > 0 {
+		files, err := c.fileStore.ListByIDs(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("list files: %v", err)
+		}
+
+		for _, f := range files {
+			for _, name := range assetNames(dir, &f) {
+				referenced[name] = true
+			}
+		}
+	}
+
+	// uploaded images have random names, they are looked up by url
+	if dir == "/assets/images" {
+		thumbnails, err := c.fileStore.ListThumbnailsByURLs(ctx, urls)
+		if err != nil {
+			return nil, fmt.Errorf("list thumbnails: %v", err)
+		}
+
+		avatars, err := c.userStore.ListAvatarsByNames(ctx, names)
+		if err != nil {
+			return nil, fmt.Errorf("list avatars: %v", err)
+		}
+
+		for _, url := range append(thumbnails, avatars...) {
+			referenced[filepath.Base(url)] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// referencedExports returns the names of the archives that are still being
+// written or can still be downloaded.
+func (c *collector) referencedExports(ctx context.Context, entries []file.Entry) (map[string]bool, error) {
+	var (
+		referenced = make(map[string]bool)
+		ids        []uuid.UUID
+	)
+
+	for _, e := range entries {
+		if id, err := uuid.Parse(strings.TrimSuffix(e.Name, ".zip")); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return referenced, nil
+	}
+
+	exports, err := c.fileStore.ListExportsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list exports: %v", err)
+	}
+
+	for _, e := range exports {
+		if e.Pending(c.now) || e.Available(c.now) {
+			referenced[e.ID.String()+".zip"] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// abortStaleUploads deletes the multipart uploads older than the upload TTL,
+// which releases the storage reserved for their parts. Nothing is deleted in
+// dry-run mode.
+func (c *collector) abortStaleUploads(ctx context.Context) (int, error) {
+	var n int
+
+	for {
+		uploads, err := c.fileStore.ListStaleMultipartUploads(ctx, c.now.Add(-c.uploadTTL), pageSize)
+		if err != nil {
+			return 0, fmt.Errorf("list stale uploads: %v", err)
+		}
+
+		for _, u := range uploads {
+			n++
+
+			if c.dryRun {
+				fmt.Printf("would abort upload %s of %s (%d bytes reserved)\n", u.ID, u.UserID, u.Reserved)
+				continue
+			}
+
+			if err := c.fileStore.DeleteMultipartUpload(ctx, u.ID); err != nil {
+				return 0, fmt.Errorf("delete upload %s: %v", u.ID, err)
+			}
+
+			c.applog.Infof("aborted upload %s of %s (%d bytes reserved)\n", u.ID, u.UserID, u.Reserved)
+		}
+
+		// the same page is listed again in dry-run mode
+		if c.dryRun || len(uploads) < pageSize {
+			return n, nil
+		}
+	}
+}
+
+// referencedUploads returns the names of the parts of the multipart uploads
+// that are still in progress.
+func (c *collector) referencedUploads(ctx context.Context, entries []file.Entry) (map[string]bool, error) {
+	var (
+		referenced = make(map[string]bool)
+		ids        []uuid.UUID
+	)
+
+	for _, e := range entries {
+		name, _, _ := strings.Cut(e.Name, ".")
+		if id, err := uuid.Parse(name); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return referenced, nil
+	}
+
+	uploads, err := c.fileStore.ListMultipartUploadsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list multipart uploads: %v", err)
+	}
+
+	inProgress := make(map[string]bool, len(uploads))
+	for _, u := range uploads {
+		inProgress[u.ID.String()] = true
+	}
+
+	for _, e := range entries {
+		if name, _, _ := strings.Cut(e.Name, "."); inProgress[name] {
+			referenced[e.Name] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// assetNames returns the names of the assets of f stored in dir.
+func assetNames(dir string, f *file.File) []string {
+	var names []string
+
+	switch dir {
+	case "/assets/images":
+		if f.Thumbnail != nil {
+			names = append(names, filepath.Base(*f.Thumbnail))
+		}
+
+		for _, t := range f.Thumbnails {
+			names = append(names, filepath.Base(t.PNG))
+			if t.WebP != "" {
+				names = append(names, filepath.Base(t.WebP))
+			}
+		}
+	case "/assets/renditions":
+		if f.Rendition != nil {
+			names = append(names, f.ID.String()+".pdf")
+		}
+	case "/assets/streams":
+		if f.Stream != nil {
+			names = append(names, f.ID.String())
+		}
+	}
+
+	return names
+}
+
+// remove deletes an entry, directories with everything inside, and returns
+// the number of bytes freed. Nothing is deleted in dry-run mode.
+func (c *collector) remove(ctx context.Context, e file.Entry) (uint64, error) {
+	var size uint64
+
+	if e.IsDir {
+		var last string
+		for {
+			children, err := c.fileService.ListEntries(ctx, e.FullPath, last, pageSize)
+			if err != nil {
+				return 0, fmt.Errorf("list entries: %v", err)
+			}
+
+			for _, child := range children {
+				n, err := c.remove(ctx, child)
+				if err != nil {
+					return 0, err
+				}
+
+				size += n
+			}
+
+			// pages start after the last name, deleted children do not shift them
+			if len(children) < pageSize {
+				break
+			}
+
+			last = children[len(children)-1].Name
+		}
+	} else {
+		size = e.Size
+	}
+
+	if c.dryRun {
+		if !e.IsDir {
+			fmt.Printf("would delete %s (%d bytes)\n", e.FullPath, e.Size)
+		}
+
+		return size, nil
+	}
+
+	if err := c.fileService.Delete(ctx, e.FullPath); err != nil {
+		return 0, fmt.Errorf("delete %s: %v", e.FullPath, err)
+	}
+
+	c.applog.Infof("deleted %s (%d bytes)\n", e.FullPath, size)
+
+	return size, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
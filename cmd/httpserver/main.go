@@ -8,6 +8,7 @@ import (
 
 	"github.com/SeaCloudHub/backend/adapters/event"
 	"github.com/SeaCloudHub/backend/adapters/redisstore"
+	"github.com/SeaCloudHub/backend/adapters/s3gateway"
 
 	"github.com/SeaCloudHub/backend/adapters/httpserver"
 	"github.com/SeaCloudHub/backend/adapters/postgrestore"
@@ -67,7 +68,17 @@ func main() {
 	server.EventDispatcher = event.NewEventDispatcher()
 
 	// store adapters
-	server.UserStore = postgrestore.NewUserStore(db)
+	userStore := postgrestore.NewUserStore(db)
+	if cfg.Encryption.KeyID != "" {
+		keys, err := services.NewMasterKeys(cfg.Encryption.KeyID, cfg.Encryption.Keys)
+		if err != nil {
+			applog.Fatal(err)
+		}
+
+		userStore = userStore.WithSecretKeys(keys)
+	}
+
+	server.UserStore = userStore
 	server.FileStore = postgrestore.NewFileStore(db)
 
 	// redis store
@@ -85,6 +96,22 @@ func main() {
 		applog.Fatal(err)
 	}
 
+	if cfg.S3Gateway.Port != 0 {
+		gateway := &s3gateway.Gateway{
+			FileStore:         server.FileStore,
+			UserStore:         server.UserStore,
+			FileService:       server.FileService,
+			PermissionService: server.PermissionService,
+			PubSubService:     server.PubSubService,
+			Logger:            applog,
+		}
+
+		go func() {
+			applog.Info("s3 gateway started!")
+			applog.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.S3Gateway.Port), gateway))
+		}()
+	}
+
 	addr := fmt.Sprintf(":%d", cfg.Port)
 	applog.Info("server started!")
 	applog.Fatal(http.ListenAndServe(addr, server))
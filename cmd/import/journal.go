@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	opStart = "start"
+	opBegin = "begin"
+	opDone  = "done"
+)
+
+// record is a line of the journal. A begin record is written with the id of
+// an entry before it is created and a done record once its row, permissions
+// and storage usage are saved, an entry with only a begin record is completed
+// with the same id on resume.
+type record struct {
+	Op   string    `json:"op"`
+	Path string    `json:"path"`
+	ID   uuid.UUID `json:"id"`
+}
+
+type journal struct {
+	f     *os.File
+	begun map[string]uuid.UUID
+	done  map[string]uuid.UUID
+}
+
+// openJournal loads the journal at name, it must have been started for the
+// same source directory and user. Nothing is written when readOnly is set.
+func openJournal(name string, src string, userID uuid.UUID, readOnly bool) (*journal, error) {
+	j := &journal{
+		begun: make(map[string]uuid.UUID),
+		done:  make(map[string]uuid.UUID),
+	}
+
+	var started bool
+
+	f, err := os.Open(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("open journal: %v", err)
+	default:
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r record
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				// the last line may be cut by an interruption
+				continue
+			}
+
+			switch r.Op {
+			case opStart:
+				if r.Path != src || r.ID != userID {
+					return nil, fmt.Errorf("journal %s belongs to the import of %s for user %s", name, r.Path, r.ID)
+				}
+
+				started = true
+			case opBegin:
+				j.begun[r.Path] = r.ID
+			case opDone:
+				j.done[r.Path] = r.ID
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read journal: %v", err)
+		}
+	}
+
+	if readOnly {
+		return j, nil
+	}
+
+	j.f, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %v", err)
+	}
+
+	if !started {
+		if err := j.write(opStart, src, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// id returns the id of an entry whose creation was interrupted, or a new one.
+func (j *journal) id(path string) uuid.UUID {
+	if id, ok := j.begun[path]; ok {
+		return id
+	}
+
+	return uuid.New()
+}
+
+func (j *journal) isDone(path string) bool {
+	_, ok := j.done[path]
+	return ok
+}
+
+// interrupted reports whether id was being created by a previous run.
+func (j *journal) interrupted(path string, id uuid.UUID) bool {
+	begun, ok := j.begun[path]
+	return ok && begun == id && !j.isDone(path)
+}
+
+func (j *journal) begin(path string, id uuid.UUID) error {
+	j.begun[path] = id
+	return j.write(opBegin, path, id)
+}
+
+func (j *journal) finish(path string, id uuid.UUID) error {
+	j.done[path] = id
+	return j.write(opDone, path, id)
+}
+
+func (j *journal) write(op string, path string, id uuid.UUID) error {
+	if j.f == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(record{Op: op, Path: path, ID: id})
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write journal: %v", err)
+	}
+
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("sync journal: %v", err)
+	}
+
+	return nil
+}
+
+func (j *journal) Close() error {
+	if j.f == nil {
+		return nil
+	}
+
+	return j.f.Close()
+}
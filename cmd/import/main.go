@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var errCapacityExceeded = errors.New("storage capacity exceeded")
+
+type importer struct {
+	applog            *zap.SugaredLogger
+	userStore         identity.Store
+	fileStore         file.Store
+	fileService       file.Service
+	permissionService permission.Service
+	user              *identity.User
+	journal           *journal
+	dryRun            bool
+
+	// dirs maps the paths relative to the user root to their rows, the
+	// directories that would be created in dry-run mode are not saved
+	dirs map[string]*file.File
+}
+
+type report struct {
+	Dirs        int
+	Files       int
+	Bytes       uint64
+	Resumed     int
+	Existing    int
+	Conflicts   int
+	Unsupported int
+}
+
+// main recreates a local directory under the root of a user, through the
+// same logic as the uploads: rows, permissions, storage usage and activity
+// logs. The modification times become the creation and update times. The
+// progress is written to a journal, running the command again with the same
+// journal resumes an interrupted import. Thumbnails are not generated, run
+// the thumbnail backfill with -missing afterwards.
+func main() {
+	var (
+		userFlag = flag.String("user", "", "email or id of the user who owns the imported files")
+		src      = flag.String("src", "", "local directory to import")
+		dest     = flag.String("dest", "", "directory under the user root to import into, created if missing")
+		state    = flag.String("journal", "import.journal", "file recording the progress, to resume an interrupted import")
+		dryRun   = flag.Bool("dry-run", false, "only report what would be imported")
+	)
+
+	flag.Parse()
+
+	if *userFlag == "" || *src == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	im := &importer{
+		applog:            applog,
+		userStore:         postgrestore.NewUserStore(db),
+		fileStore:         postgrestore.NewFileStore(db),
+		fileService:       services.NewFileService(cfg),
+		permissionService: services.NewPermissionService(cfg),
+		dryRun:            *dryRun,
+		dirs:              make(map[string]*file.File),
+	}
+
+	if _, err := uuid.Parse(*userFlag); err == nil {
+		im.user, err = im.userStore.GetByID(ctx, *userFlag)
+		if err != nil {
+			applog.Fatalf("cannot get user: %v\n", err)
+		}
+	} else {
+		im.user, err = im.userStore.GetByEmail(ctx, *userFlag)
+		if err != nil {
+			applog.Fatalf("cannot get user: %v\n", err)
+		}
+	}
+
+	root, err := im.fileStore.GetByID(ctx, im.user.RootID.String())
+	if err != nil {
+		applog.Fatalf("cannot get root directory: %v\n", err)
+	}
+
+	im.dirs["."] = root
+
+	srcDir, err := filepath.Abs(*src)
+	if err != nil {
+		applog.Fatalf("cannot resolve source: %v\n", err)
+	}
+
+	im.journal, err = openJournal(*state, srcDir, im.user.ID, im.dryRun)
+	if err != nil {
+		applog.Fatal(err)
+	}
+	defer im.journal.Close()
+
+	r, err := im.run(ctx, srcDir, path.Clean("/" + *dest)[1:])
+
+	action := "imported"
+	if im.dryRun {
+		action = "to import"
+	}
+
+	fmt.Printf("%d directories and %d files %s (%d bytes), %d resumed, %d already existing, %d conflicts, %d unsupported\n",
+		r.Dirs, r.Files, action, r.Bytes, r.Resumed, r.Existing, r.Conflicts, r.Unsupported)
+
+	if err != nil {
+		applog.Fatalf("import stopped: %v\n", err)
+	}
+}
+
+// run walks src in lexical order, dest is relative to the user root.
+func (im *importer) run(ctx context.Context, src string, dest string) (*report, error) {
+	r := &report{}
+
+	// the destination directories come first, without a local counterpart
+	if dest != "" {
+		var rel string
+		for _, name := range strings.Split(dest, "/") {
+			rel = path.Join(rel, name)
+			if err := im.importDir(ctx, r, rel, nil); errors.Is(err, fs.SkipDir) {
+				return r, fmt.Errorf("destination %s is not a directory", rel)
+			} else if err != nil {
+				return r, err
+			}
+		}
+	}
+
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		local, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		rel := path.Join(dest, filepath.ToSlash(local))
+		if rel == "" {
+			rel = "."
+		}
+
+		switch {
+		case d.IsDir():
+			if rel == "." || (local == "." && dest != "") {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			err = im.importDir(ctx, r, rel, info)
+			if errors.Is(err, fs.SkipDir) {
+				r.Conflicts++
+				im.applog.Warnf("%s is not a directory, skipped\n", rel)
+			}
+
+			return err
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			return im.importFile(ctx, r, rel, p, info)
+		default:
+			// symbolic links, sockets, devices...
+			r.Unsupported++
+			im.applog.Warnf("%s is not a regular file, skipped\n", local)
+
+			return nil
+		}
+	})
+
+	return r, err
+}
+
+// importDir creates the directory rel unless it exists, info is nil for the
+// destination directories. It returns fs.SkipDir when rel is a file.
+func (im *importer) importDir(ctx context.Context, r *report, rel string, info fs.FileInfo) error {
+	parent := im.dirs[path.Dir(rel)]
+	name := path.Base(rel)
+
+	existing, err := im.fileStore.GetByFullPath(ctx, path.Join(parent.FullPath(), name))
+	switch {
+	case errors.Is(err, file.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("get %s: %v", rel, err)
+	case !existing.IsDir:
+		return fs.SkipDir
+	case im.journal.interrupted(rel, existing.ID):
+		// the row was created, the permissions may not be
+		if err := im.permissionService.CreateDirectoryPermissions(ctx, im.user.ID.String(), existing.ID.String(), parent.ID.String()); err != nil {
+			return fmt.Errorf("create directory permissions of %s: %v", rel, err)
+		}
+
+		im.dirs[rel] = existing
+		r.Resumed++
+
+		return im.journal.finish(rel, existing.ID)
+	default:
+		im.dirs[rel] = existing
+		if im.journal.isDone(rel) {
+			r.Resumed++
+		} else {
+			r.Existing++
+		}
+
+		return nil
+	}
+
+	f := file.NewDirectory(name).WithID(im.journal.id(rel)).WithOwnerID(im.user.ID)
+	if info != nil {
+		f.CreatedAt = info.ModTime()
+		f.UpdatedAt = info.ModTime()
+	}
+
+	r.Dirs++
+
+	if im.dryRun {
+		im.dirs[rel] = f.WithPath(parent.FullPath())
+		return nil
+	}
+
+	if err := im.journal.begin(rel, f.ID); err != nil {
+		return err
+	}
+
+	if err := services.CreateDirectory(ctx, im.fileStore, im.permissionService, parent, f); err != nil {
+		return fmt.Errorf("create directory %s: %v", rel, err)
+	}
+
+	im.dirs[rel] = f
+	im.writeLog(ctx, f)
+
+	return im.journal.finish(rel, f.ID)
+}
+
+// importFile uploads the file at local to rel unless a file with the same
+// name exists already.
+func (im *importer) importFile(ctx context.Context, r *report, rel string, local string, info fs.FileInfo) error {
+	parent := im.dirs[path.Dir(rel)]
+	name := path.Base(rel)
+
+	if im.journal.isDone(rel) {
+		r.Resumed++
+		return nil
+	}
+
+	existing, err := im.fileStore.GetByFullPath(ctx, path.Join(parent.FullPath(), name))
+	switch {
+	case errors.Is(err, file.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("get %s: %v", rel, err)
+	case im.journal.interrupted(rel, existing.ID):
+		// the row was created, the permissions and storage usage may not be
+		if err := im.permissionService.CreateFilePermissions(ctx, im.user.ID.String(), existing.ID.String(), parent.ID.String()); err != nil {
+			return fmt.Errorf("create file permissions of %s: %v", rel, err)
+		}
+
+		if err := im.addUsage(ctx, existing.Size); err != nil {
+			return err
+		}
+
+		r.Resumed++
+
+		return im.journal.finish(rel, existing.ID)
+	default:
+		r.Conflicts++
+		im.applog.Warnf("%s already exists, skipped\n", rel)
+
+		return nil
+	}
+
+	size := uint64(info.Size())
+	if im.user.StorageUsage+size > im.user.StorageCapacity {
+		return fmt.Errorf("%w by %s", errCapacityExceeded, rel)
+	}
+
+	r.Files++
+	r.Bytes += size
+
+	if im.dryRun {
+		im.user.StorageUsage += size
+		return nil
+	}
+
+	src, err := os.Open(local)
+	if err != nil {
+		return fmt.Errorf("open %s: %v", local, err)
+	}
+	defer src.Close()
+
+	f := file.NewFile(name).WithID(im.journal.id(rel)).WithOwnerID(im.user.ID)
+	f.CreatedAt = info.ModTime()
+	f.UpdatedAt = info.ModTime()
+
+	if err := im.journal.begin(rel, f.ID); err != nil {
+		return err
+	}
+
+	if err := services.CreateFile(ctx, im.fileStore, im.fileService, im.permissionService, parent, f, src); err != nil {
+		return fmt.Errorf("create file %s: %v", rel, err)
+	}
+
+	if err := im.addUsage(ctx, f.Size); err != nil {
+		return err
+	}
+
+	im.writeLog(ctx, f)
+
+	return im.journal.finish(rel, f.ID)
+}
+
+func (im *importer) addUsage(ctx context.Context, size uint64) error {
+	if err := im.userStore.UpdateStorageUsage(ctx, im.user.ID, im.user.StorageUsage+size); err != nil {
+		return fmt.Errorf("update storage usage: %v", err)
+	}
+
+	im.user.StorageUsage += size
+
+	return nil
+}
+
+func (im *importer) writeLog(ctx context.Context, f *file.File) {
+	if err := im.fileStore.WriteLogs(ctx, []file.Log{file.NewLog(f.ID, im.user.ID, file.LogActionCreate)}); err != nil {
+		im.applog.Errorf("cannot write log of %s: %v\n", f.ID, err)
+	}
+
+	if err := im.fileStore.WriteChanges(ctx, []file.Change{file.NewChange(f, file.ChangeCreate)}); err != nil {
+		im.applog.Errorf("cannot write change of %s: %v\n", f.ID, err)
+	}
+}
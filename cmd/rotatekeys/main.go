@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+)
+
+// rotatekeys wraps every data key and access key secret with the active
+// master key again. Once it is done, the previous master keys can be removed
+// from ENCRYPTION_KEYS.
+func main() {
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	fileService, ok := services.NewFileService(cfg).(*services.EncryptedFileService)
+	if !ok {
+		applog.Fatal("encryption is disabled, set ENCRYPTION_KEY_ID and ENCRYPTION_KEYS")
+	}
+
+	keys, err := services.NewMasterKeys(cfg.Encryption.KeyID, cfg.Encryption.Keys)
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rotated, err := fileService.RotateKeys(ctx)
+	if err != nil {
+		applog.Fatalf("cannot rotate keys after %d files: %v\n", rotated, err)
+	}
+
+	fmt.Printf("%d data keys wrapped with %s\n", rotated, cfg.Encryption.KeyID)
+
+	rotated, err = postgrestore.NewUserStore(db).WithSecretKeys(keys).RotateAccessKeys(ctx)
+	if err != nil {
+		applog.Fatalf("cannot rotate keys after %d access keys: %v\n", rotated, err)
+	}
+
+	fmt.Printf("%d access key secrets wrapped with %s\n", rotated, cfg.Encryption.KeyID)
+}
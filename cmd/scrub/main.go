@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+	"github.com/SeaCloudHub/backend/pkg/pagination"
+)
+
+// pageSize is the number of issues read at once to write the report.
+const pageSize = 100
+
+// scrub checks that the files table and the storage agree and writes the
+// missing, mismatched and orphaned files to a CSV report. The scrub is saved
+// like the ones started from the admin API.
+func main() {
+	var (
+		repair      = flag.Bool("repair", false, "copy the size and MD5 of the storage to the mismatched files")
+		quarantine  = flag.Bool("quarantine", false, "block the download of the missing files, and of the mismatched ones unless repaired")
+		concurrency = flag.Int("concurrency", 8, "number of files checked at the same time")
+		grace       = flag.Duration("grace", time.Hour, "ignore stored contents younger than this when looking for orphans")
+		out         = flag.String("out", "", "write the report to this file instead of stdout")
+	)
+
+	flag.Parse()
+
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	fileStore := postgrestore.NewFileStore(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sc := file.NewScrub(file.ScrubOptions{Repair: *repair, Quarantine: *quarantine}, nil)
+	if err := fileStore.CreateScrub(ctx, sc); err != nil {
+		applog.Fatalf("cannot create scrub: %v\n", err)
+	}
+
+	if err := services.Scrub(ctx, fileStore, services.NewFileService(cfg), sc, *concurrency, *grace); err != nil {
+		applog.Errorf("scrub %s stopped after %d files: %v\n", sc.ID, sc.Checked, err)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			applog.Fatalf("cannot create report: %v\n", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	// the report is written even for an interrupted scrub, it holds what was
+	// found until then
+	if err := writeReport(context.WithoutCancel(ctx), fileStore, sc, w); err != nil {
+		applog.Fatalf("cannot write report: %v\n", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "scrub %s %s: %d checked, %d missing, %d mismatched, %d orphaned, %d unchecked, %d repaired, %d quarantined\n",
+		sc.ID, sc.Status, sc.Checked, sc.Missing, sc.Mismatched, sc.Orphaned, sc.Unchecked, sc.Repaired, sc.Quarantined)
+}
+
+func writeReport(ctx context.Context, fileStore file.Store, sc *file.Scrub, w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"kind", "file_id", "path", "expected", "actual", "action"}); err != nil {
+		return err
+	}
+
+	cursor := pagination.NewCursor("", pageSize)
+	for {
+		issues, err := fileStore.ListScrubIssues(ctx, sc.ID, "", cursor)
+		if err != nil {
+			return fmt.Errorf("list issues: %v", err)
+		}
+
+		for _, issue := range issues {
+			var fileID string
+			if issue.FileID != nil {
+				fileID = issue.FileID.String()
+			}
+
+			if err := cw.Write([]string{issue.Kind, fileID, issue.Path, issue.Expected, issue.Actual, issue.Action}); err != nil {
+				return err
+			}
+		}
+
+		next := cursor.NextToken()
+		if next == "" {
+			break
+		}
+
+		cursor = pagination.NewCursor(next, pageSize)
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
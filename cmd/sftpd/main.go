@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/SeaCloudHub/backend/adapters/davfs"
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/redisstore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/adapters/sftpfs"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// extensionUserID passes the ID of the authenticated user to the connection.
+const extensionUserID = "user-id"
+
+var errAuthFailed = errors.New("authentication failed")
+
+type server struct {
+	applog            *zap.SugaredLogger
+	userStore         identity.Store
+	fileStore         file.Store
+	fileService       file.Service
+	identityService   identity.Service
+	permissionService permission.Service
+	pubSubService     pubsub.Service
+}
+
+// main serves the tree of each user over SFTP. The users sign in with their
+// email and either their password or one of their SSH keys, the requests go
+// through the same permission checks, storage usage accounting and trash as
+// the WebDAV endpoint.
+func main() {
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+	defer logger.Sync(applog)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	redis, err := redisstore.NewConnection(redisstore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to redis: %v\n", err)
+	}
+
+	srv := &server{
+		applog:            applog,
+		userStore:         postgrestore.NewUserStore(db),
+		fileStore:         postgrestore.NewFileStore(db),
+		fileService:       services.NewFileService(cfg),
+		identityService:   services.NewIdentityService(cfg),
+		permissionService: services.NewPermissionService(cfg),
+		pubSubService:     redisstore.NewRedisClient(redis),
+	}
+
+	hostKey, err := loadHostKey(cfg.SFTP.HostKey)
+	if err != nil {
+		applog.Fatalf("cannot load host key: %v\n", err)
+	}
+
+	sshConfig := &ssh.ServerConfig{
+		PasswordCallback:  srv.passwordCallback,
+		PublicKeyCallback: srv.publicKeyCallback,
+	}
+	sshConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.SFTP.Port))
+	if err != nil {
+		applog.Fatalf("cannot listen: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	applog.Info("sftp server started!")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			applog.Errorf("cannot accept connection: %v\n", err)
+			continue
+		}
+
+		go srv.serve(ctx, conn, sshConfig)
+	}
+}
+
+// passwordCallback checks the password of the user through the identity
+// service, the session it opens is closed right away.
+func (s *server) passwordCallback(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	ctx := context.Background()
+
+	session, err := s.identityService.Login(ctx, meta.User(), string(password))
+	if err != nil {
+		if !errors.Is(err, identity.ErrInvalidCredentials) && !errors.Is(err, identity.ErrIdentityWasDisabled) {
+			s.applog.Errorf("cannot login %s: %v\n", meta.User(), err)
+		}
+
+		return nil, errAuthFailed
+	}
+
+	if err := s.identityService.Logout(ctx, *session.Token); err != nil {
+		s.applog.Errorf("cannot logout %s: %v\n", meta.User(), err)
+	}
+
+	return s.permissions(ctx, session.Identity.ID)
+}
+
+// publicKeyCallback looks up the key among the SSH keys of the user.
+func (s *server) publicKeyCallback(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	ctx := context.Background()
+
+	sshKey, err := s.userStore.GetSSHKeyByFingerprint(ctx, ssh.FingerprintSHA256(key))
+	if err != nil {
+		if !errors.Is(err, identity.ErrSSHKeyNotFound) {
+			s.applog.Errorf("cannot get ssh key: %v\n", err)
+		}
+
+		return nil, errAuthFailed
+	}
+
+	user, err := s.userStore.GetByID(ctx, sshKey.UserID.String())
+	if err != nil {
+		s.applog.Errorf("cannot get user %s: %v\n", sshKey.UserID, err)
+		return nil, errAuthFailed
+	}
+
+	if !strings.EqualFold(user.Email, meta.User()) {
+		return nil, errAuthFailed
+	}
+
+	if err := s.userStore.UpdateSSHKeyLastUsedAt(ctx, sshKey.ID); err != nil {
+		s.applog.Errorf("cannot update ssh key %s: %v\n", sshKey.ID, err)
+	}
+
+	return s.permissions(ctx, user.ID.String())
+}
+
+// permissions checks that the user can sign in. Users still on the default
+// password are rejected, as they are by the HTTP API.
+func (s *server) permissions(ctx context.Context, userID string) (*ssh.Permissions, error) {
+	user, err := s.userStore.GetByID(ctx, userID)
+	if err != nil {
+		s.applog.Errorf("cannot get user %s: %v\n", userID, err)
+		return nil, errAuthFailed
+	}
+
+	if !user.IsActive || user.PasswordChangedAt == nil {
+		return nil, errAuthFailed
+	}
+
+	return &ssh.Permissions{Extensions: map[string]string{extensionUserID: user.ID.String()}}, nil
+}
+
+// serve runs the SSH handshake of conn and serves its sessions.
+func (s *server) serve(ctx context.Context, nConn net.Conn, config *ssh.ServerConfig) {
+	defer nConn.Close()
+
+	conn, chans, reqs, err := ssh.NewServerConn(nConn, config)
+	if err != nil {
+		s.applog.Debugf("handshake with %s failed: %v\n", nConn.RemoteAddr(), err)
+		return
+	}
+	defer conn.Close()
+
+	go ssh.DiscardRequests(reqs)
+
+	userID := conn.Permissions.Extensions[extensionUserID]
+
+	user, err := s.userStore.GetByID(ctx, userID)
+	if err != nil {
+		s.applog.Errorf("cannot get user %s: %v\n", userID, err)
+		return
+	}
+
+	root, err := s.fileStore.GetByID(ctx, user.RootID.String())
+	if err != nil {
+		s.applog.Errorf("cannot get root directory of %s: %v\n", userID, err)
+		return
+	}
+
+	fs := &davfs.FileSystem{
+		FileStore:         s.fileStore,
+		UserStore:         s.userStore,
+		FileService:       s.fileService,
+		PermissionService: s.permissionService,
+		PubSubService:     s.pubSubService,
+		Logger:            s.applog,
+		User:              user,
+		Root:              root,
+	}
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			s.applog.Errorf("cannot accept channel: %v\n", err)
+			continue
+		}
+
+		go s.session(channel, requests, fs)
+	}
+}
+
+// session serves the sftp subsystem, the other requests are rejected.
+func (s *server) session(channel ssh.Channel, requests <-chan *ssh.Request, fs *davfs.FileSystem) {
+	defer channel.Close()
+
+	for req := range requests {
+		var payload struct{ Name string }
+
+		ok := req.Type == "subsystem" && ssh.Unmarshal(req.Payload, &payload) == nil && payload.Name == "sftp"
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+
+		if !ok {
+			continue
+		}
+
+		server := sftp.NewRequestServer(channel, sftpfs.NewHandlers(fs))
+		if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+			s.applog.Errorf("sftp session of %s failed: %v\n", fs.User.ID, err)
+		}
+
+		server.Close()
+
+		return
+	}
+}
+
+// loadHostKey reads the private key of the server, a new ed25519 key is
+// written at path the first time.
+func loadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := ssh.MarshalPrivateKey(key, "")
+		if err != nil {
+			return nil, err
+		}
+
+		data = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ssh.ParsePrivateKey(data)
+}
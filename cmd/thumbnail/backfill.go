@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+// backfill enqueues the files matching the flags and prints the progress of
+// the workers until every file has been processed.
+func (s *service) backfill(ctx context.Context, args []string) error {
+	var (
+		fs      = flag.NewFlagSet("backfill", flag.ContinueOnError)
+		_type   = fs.String("type", "", "only files of this type, e.g. image, video, pdf, document")
+		after   = fs.String("after", "", "only files created on or after this date (YYYY-MM-DD)")
+		before  = fs.String("before", "", "only files created before this date (YYYY-MM-DD)")
+		missing = fs.Bool("missing", false, "only files without a thumbnail")
+		noWait  = fs.Bool("no-wait", false, "exit once the files are enqueued")
+		filter  file.BackfillFilter
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter.Type = *_type
+	filter.Missing = *missing
+
+	for _, d := range []struct {
+		value string
+		dst   **time.Time
+	}{{*after, &filter.After}, {*before, &filter.Before}} {
+		if d.value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.DateOnly, d.value)
+		if err != nil {
+			return fmt.Errorf("parse date: %v", err)
+		}
+
+		*d.dst = &t
+	}
+
+	b := file.NewBackfill(filter, nil)
+	if err := s.fileStore.CreateBackfill(ctx, b); err != nil {
+		return fmt.Errorf("create backfill: %v", err)
+	}
+
+	if err := services.EnqueueBackfill(ctx, s.fileStore, s.pubsubService, b); err != nil {
+		return err
+	}
+
+	s.applog.Infof("backfill %s enqueued\n", b.ID)
+	if *noWait {
+		return nil
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		b, err := s.fileStore.GetBackfill(ctx, b.ID)
+		if err != nil {
+			return fmt.Errorf("get backfill: %v", err)
+		}
+
+		fmt.Printf("%d/%d processed: %d succeeded, %d failed, %d skipped\n",
+			b.Processed(), b.Total, b.Succeeded, b.Failed, b.Skipped)
+
+		if b.Done() {
+			return nil
+		}
+	}
+}
+
+// report counts a processed file in the backfill it was enqueued by, if any.
+func (s *service) report(ctx context.Context, f *File, result string) {
+	if f.Backfill == nil {
+		return
+	}
+
+	if err := s.fileStore.IncrementBackfill(ctx, *f.Backfill, result); err != nil {
+		s.applog.Errorf("cannot update backfill: %v\n", err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+// videoBitrates are the target video bitrates in kbit/s of the usual rendition
+// heights, other heights are approximated from the closest smaller one.
+var videoBitrates = map[int]int{
+	240:  400,
+	360:  800,
+	480:  1400,
+	720:  2800,
+	1080: 5000,
+	1440: 8000,
+	2160: 14000,
+}
+
+// audioBitrate is shared by every rendition, in kbit/s.
+const audioBitrate = 128
+
+// Rendition is one variant stream of the HLS master playlist.
+type Rendition struct {
+	Width   int
+	Height  int
+	Bitrate int // video bitrate in kbit/s
+}
+
+// Name is the directory of the rendition playlist and segments.
+func (r Rendition) Name() string {
+	return fmt.Sprintf("%dp", r.Height)
+}
+
+// Renditions returns the renditions worth producing for a video. Heights above
+// the source are dropped since upscaling only wastes space, but the smallest
+// one is always kept. media may be nil when the metadata is unknown.
+func Renditions(heights []int, media *file.Media) []Rendition {
+	heights = append([]int(nil), heights...)
+	sort.Ints(heights)
+
+	renditions := make([]Rendition, 0, len(heights))
+	for i, h := range heights {
+		if i > 0 && media != nil && media.Height > 0 && h > media.Height {
+			break
+		}
+
+		r := Rendition{Height: h, Bitrate: bitrate(h)}
+		if media != nil && media.Width > 0 && media.Height > 0 {
+			// ffmpeg scale=-2:h keeps the aspect ratio with an even width
+			r.Width = (media.Width*h/media.Height + 1) / 2 * 2
+		}
+
+		renditions = append(renditions, r)
+	}
+
+	return renditions
+}
+
+func bitrate(height int) int {
+	best := 0
+	for h := range videoBitrates {
+		if h <= height && h > best {
+			best = h
+		}
+	}
+
+	if best == 0 {
+		return videoBitrates[240] * height / 240
+	}
+
+	return videoBitrates[best] * height / best
+}
+
+// MasterPlaylist lists the renditions for the players, which pick one
+// depending on the available bandwidth.
+func MasterPlaylist(renditions []Rendition) string {
+	var b strings.Builder
+
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, r := range renditions {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d", (r.Bitrate+audioBitrate)*1000)
+		if r.Width > 0 {
+			fmt.Fprintf(&b, ",RESOLUTION=%dx%d", r.Width, r.Height)
+		}
+
+		fmt.Fprintf(&b, "\n%s/index.m3u8\n", r.Name())
+	}
+
+	return b.String()
+}
+
+// HLSTranscoder produces an HLS stream with one ffmpeg run per rendition.
+type HLSTranscoder struct{}
+
+func (t *HLSTranscoder) Transcode(ctx context.Context, input string, dir string, renditions []Rendition) error {
+	for _, r := range renditions {
+		if err := os.MkdirAll(filepath.Join(dir, r.Name()), 0o755); err != nil {
+			return fmt.Errorf("create directory: %v", err)
+		}
+
+		cmd := exec.CommandContext(ctx, "ffmpeg", "-i", input,
+			"-map", "0:v:0", "-map", "0:a:0?",
+			"-vf", fmt.Sprintf("scale=-2:%d", r.Height),
+			"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "main",
+			"-b:v", fmt.Sprintf("%dk", r.Bitrate),
+			"-maxrate", fmt.Sprintf("%dk", r.Bitrate*107/100),
+			"-bufsize", fmt.Sprintf("%dk", r.Bitrate*3/2),
+			"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", audioBitrate), "-ac", "2",
+			"-f", "hls", "-hls_time", "6", "-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(dir, r.Name(), "segment_%03d.ts"),
+			filepath.Join(dir, r.Name(), "index.m3u8"))
+		var out bytes.Buffer
+		var stderr bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			log.Println(cmd.String(), out.String(), stderr.String())
+
+			return fmt.Errorf("transcode %s: %v", r.Name(), err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "master.m3u8"), []byte(MasterPlaylist(renditions)), 0o644); err != nil {
+		return fmt.Errorf("write master playlist: %v", err)
+	}
+
+	return nil
+}
+
+// transcode creates the HLS stream of a video and stores it in
+// /assets/streams/<id>, it is served by GET /api/files/:id/stream/*.
+func (s *service) transcode(ctx context.Context, f *File) error {
+	if !strings.HasPrefix(f.Mime, "video") {
+		return fmt.Errorf("%w: %s", errUnsupported, f.Mime)
+	}
+
+	// the metadata is extracted by the thumbnail job, the renditions fall
+	// back to every configured height without it
+	media, err := s.fileStore.GetMedia(ctx, f.ID)
+	if err != nil && !errors.Is(err, file.ErrNotFound) {
+		return fmt.Errorf("get media: %v", err)
+	}
+
+	work, err := os.MkdirTemp("", "transcode")
+	if err != nil {
+		return fmt.Errorf("create directory: %v", err)
+	}
+	defer os.RemoveAll(work)
+
+	input, err := s.download(ctx, work, f)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(work, "hls")
+
+	if err := (&HLSTranscoder{}).Transcode(ctx, input, dir, Renditions(s.heights, media)); err != nil {
+		return err
+	}
+
+	mime.AddExtensionType(".m3u8", "application/vnd.apple.mpegurl")
+	mime.AddExtensionType(".ts", "video/mp2t")
+
+	if err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		rc, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open file: %v", err)
+		}
+		defer rc.Close()
+
+		fullPath := filepath.Join("/assets", "streams", f.ID.String(), rel)
+		if _, err := s.fileService.CreateFile(ctx, rc, fullPath, mime.TypeByExtension(filepath.Ext(path))); err != nil {
+			return fmt.Errorf("create file: %v", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("upload stream: %v", err)
+	}
+
+	if err := s.fileStore.UpdateStream(ctx, f.ID, fmt.Sprintf("/api/files/%s/stream/master.m3u8", f.ID)); err != nil {
+		return fmt.Errorf("update stream: %v", err)
+	}
+
+	return nil
+}
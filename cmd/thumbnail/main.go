@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"mime"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/SeaCloudHub/backend/adapters/postgrestore"
 	"github.com/SeaCloudHub/backend/adapters/redisstore"
@@ -27,7 +32,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// errUnsupported is returned for files that have neither a thumbnail nor
+// media metadata, they are acknowledged without being retried.
+var errUnsupported = errors.New("converter not found for mime type")
+
+// defaultThumbnailSize is the size stored in files.thumbnail for the grid views.
+const defaultThumbnailSize = 200
+
 type service struct {
+	sizes         []int
+	heights       []int
+	maxAttempts   int
 	applog        *zap.SugaredLogger
 	userStore     identity.Store
 	fileStore     file.Store
@@ -36,8 +51,9 @@ type service struct {
 }
 
 type File struct {
-	ID   uuid.UUID `json:"id"`
-	Mime string    `json:"mime"`
+	ID       uuid.UUID  `json:"id"`
+	Mime     string     `json:"mime"`
+	Backfill *uuid.UUID `json:"backfill,omitempty"`
 }
 
 func main() {
@@ -73,6 +89,9 @@ func main() {
 	}
 
 	s := &service{
+		sizes:         cfg.Thumbnail.Sizes,
+		heights:       cfg.Transcode.Heights,
+		maxAttempts:   cfg.Thumbnail.MaxAttempts,
 		applog:        applog,
 		userStore:     postgrestore.NewUserStore(db),
 		fileStore:     postgrestore.NewFileStore(db),
@@ -80,104 +99,373 @@ func main() {
 		pubsubService: redisstore.NewRedisClient(redis),
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// thumbnail backfill [flags] enqueues existing files instead of processing jobs
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := s.backfill(ctx, os.Args[2:]); err != nil {
+			applog.Fatalf("cannot backfill: %v\n", err)
+		}
+
+		return
+	}
+
+	hostname, _ := os.Hostname()
+
+	queue, err := s.pubsubService.Consume(ctx, "thumbnails", pubsub.QueueOptions{
+		Group:             "thumbnail",
+		Consumer:          hostname,
+		MaxAttempts:       cfg.Thumbnail.MaxAttempts,
+		Backoff:           cfg.Thumbnail.RetryBackoff,
+		VisibilityTimeout: cfg.Thumbnail.VisibilityTimeout,
+	})
+	if err != nil {
+		applog.Fatalf("cannot consume queue: %v\n", err)
+	}
+
+	// transcoding is much slower than thumbnails, it has its own queue so
+	// videos do not hold back the thumbnails of other files
+	transcodes, err := s.pubsubService.Consume(ctx, "transcodes", pubsub.QueueOptions{
+		Group:             "transcode",
+		Consumer:          hostname,
+		MaxAttempts:       cfg.Thumbnail.MaxAttempts,
+		Backoff:           cfg.Thumbnail.RetryBackoff,
+		VisibilityTimeout: cfg.Transcode.VisibilityTimeout,
+	})
+	if err != nil {
+		applog.Fatalf("cannot consume queue: %v\n", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(cfg.Thumbnail.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, queue, jobTimeout(cfg.Thumbnail.VisibilityTimeout), s.process)
+		}()
+	}
+
+	for i := 0; i < max(cfg.Transcode.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, transcodes, jobTimeout(cfg.Transcode.VisibilityTimeout), s.transcode)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// jobTimeout leaves a tenth of the visibility timeout to retry a job that ran
+// out of time before another consumer claims it.
+func jobTimeout(visibilityTimeout time.Duration) time.Duration {
+	return visibilityTimeout - visibilityTimeout/10
+}
+
+// jobContext cancels a job after timeout, a job without timeout runs until
+// ctx is done.
+func jobContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
 
-	// pubsub
-	pubsub := s.pubsubService.Subscribe(ctx, "thumbnails")
-	defer pubsub.Close()
+	return context.WithTimeout(ctx, timeout)
+}
 
-	// listen for messages
+// work processes jobs until the context is cancelled, a job is cancelled
+// after timeout. Files that failed are retried on their own, the rest of the
+// batch is not processed again.
+func (s *service) work(ctx context.Context, queue pubsub.Queue, timeout time.Duration, process func(ctx context.Context, f *File) error) {
 	for {
-		msg, err := pubsub.ReceiveMessage(ctx)
+		job, err := queue.Receive(ctx)
 		if err != nil {
-			log.Fatalf("cannot receive message: %v\n", err)
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.applog.Errorf("cannot receive job: %v\n", err)
+			time.Sleep(time.Second)
+
+			continue
 		}
 
-		// parse message
+		// parse message, a malformed payload will never succeed
 		var files []File
-		if err := json.Unmarshal([]byte(msg.Payload), &files); err != nil {
-			log.Fatalf("cannot unmarshal payload: %v\n", err)
+		if err := json.Unmarshal([]byte(job.Payload), &files); err != nil {
+			if err := queue.DeadLetter(ctx, job, err); err != nil {
+				s.applog.Errorf("cannot dead-letter job: %v\n", err)
+			}
+
+			continue
 		}
 
-		// iterate over the payload
+		var (
+			failed []File
+			cause  error
+		)
+
+		jctx, cancel := jobContext(ctx, timeout)
+
 		for _, f := range files {
-			log.Printf("file: %v\n", f)
-			if err := s.process(ctx, &f); err != nil {
-				applog.Infof("cannot process file: %v\n", err)
+			err := process(jctx, &f)
+			switch {
+			case err == nil:
+				s.report(ctx, &f, file.BackfillSucceeded)
+			case errors.Is(err, errUnsupported):
+				s.report(ctx, &f, file.BackfillSkipped)
+			default:
+				s.applog.Infof("cannot process file %s (attempt %d): %v\n", f.ID, job.Attempts+1, err)
+				failed, cause = append(failed, f), err
+
+				// the queue dead-letters the job after its last attempt
+				if job.Attempts+1 >= s.maxAttempts {
+					s.report(ctx, &f, file.BackfillFailed)
+				}
+			}
+		}
+
+		cancel()
+
+		if len(failed) == 0 {
+			if err := queue.Ack(ctx, job); err != nil {
+				s.applog.Errorf("cannot ack job: %v\n", err)
 			}
+
+			continue
 		}
 
-		log.Printf("message received: %s\n", msg.Payload)
+		payload, _ := json.Marshal(failed)
+		job.Payload = string(payload)
+
+		if err := queue.Retry(ctx, job, cause); err != nil {
+			s.applog.Errorf("cannot retry job: %v\n", err)
+		}
 	}
 }
 
 func (s *service) process(ctx context.Context, f *File) error {
-	// get converter
+	// get converter and metadata extractor
 	c := getConverter(f.Mime)
+	e := getExtractor(f.Mime)
+	p := getPreviewer(f.Mime)
+	if c == nil && e == nil && p == nil {
+		return fmt.Errorf("%w: %s", errUnsupported, f.Mime)
+	}
+
+	// every job works in its own directory, removed whatever the outcome
+	dir, err := os.MkdirTemp("", "thumbnail")
+	if err != nil {
+		return fmt.Errorf("create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input, err := s.download(ctx, dir, f)
+	if err != nil {
+		return err
+	}
+
+	// extract media metadata, a failure here must not prevent the thumbnail
+	if e != nil {
+		if err := s.extract(ctx, e, f.ID, input); err != nil {
+			s.applog.Infof("cannot extract media: %v\n", err)
+		}
+	}
+
+	// previews are best effort as well
+	if p != nil {
+		if err := s.preview(ctx, p, f.ID, f.Mime, input); err != nil {
+			s.applog.Infof("cannot create preview: %v\n", err)
+		}
+	}
+
 	if c == nil {
-		return fmt.Errorf("converter not found for mime type: %s", f.Mime)
+		return nil
+	}
+
+	// office documents are rendered to PDF once, the rendition is kept for the
+	// web preview and the thumbnails are taken from it like any other PDF
+	if o, ok := c.(*OfficeConverter); ok {
+		pdf, err := s.render(ctx, o, f.ID, input)
+		if err != nil {
+			return fmt.Errorf("render: %v", err)
+		}
+
+		c, input = &PDFConverter{}, pdf
+	}
+
+	// create a thumbnail for every configured size
+	thumbnails := make([]file.Thumbnail, 0, len(s.sizes))
+	for _, size := range s.sizes {
+		t, err := s.thumbnail(ctx, c, dir, f.ID, input, size)
+		if err != nil {
+			return fmt.Errorf("thumbnail %d: %v", size, err)
+		}
+
+		thumbnails = append(thumbnails, *t)
+	}
+
+	// update the file record in the database
+	if err := s.fileStore.UpdateThumbnails(ctx, f.ID, thumbnails); err != nil {
+		return fmt.Errorf("update thumbnails: %v", err)
+	}
+
+	// keep the single thumbnail used by the grid views up to date
+	if t := file.PickThumbnail(thumbnails, defaultThumbnailSize); t != nil {
+		if err := s.fileStore.UpdateThumbnail(ctx, f.ID, t.PNG); err != nil {
+			return fmt.Errorf("update thumbnail: %v", err)
+		}
+	}
+
+	// the owner is told the thumbnails are ready, a failure is not retried
+	if e, err := s.fileStore.GetByID(ctx, f.ID.String()); err != nil {
+		s.applog.Errorf("cannot get file %s: %v\n", f.ID, err)
+	} else if err := services.PublishEvents(ctx, s.pubsubService, file.NewEvent(e, file.EventThumbnail).WithUserID(e.OwnerID)); err != nil {
+		s.applog.Errorf("cannot publish event of %s: %v\n", f.ID, err)
+	}
+
+	if strings.HasPrefix(f.Mime, "video") {
+		message, err := json.Marshal([]File{{ID: f.ID, Mime: f.Mime}})
+		if err != nil {
+			return fmt.Errorf("marshal payload: %v", err)
+		}
+
+		if err := s.pubsubService.Enqueue(ctx, "transcodes", string(message)); err != nil {
+			return fmt.Errorf("enqueue transcode: %v", err)
+		}
 	}
 
-	// download and save the file to disk
+	return nil
+}
+
+// download saves the original file in dir and returns its path, the
+// extension is kept because the converters rely on it.
+func (s *service) download(ctx context.Context, dir string, f *File) (string, error) {
 	rc, _, err := s.fileService.DownloadFile(ctx, f.ID.String())
 	if err != nil {
-		return fmt.Errorf("download file: %v", err)
+		return "", fmt.Errorf("download file: %v", err)
 	}
 	defer rc.Close()
 
 	mime.AddExtensionType(".mov", "video/quicktime")
-	exts, _ := mime.ExtensionsByType(f.Mime)
-	if len(exts) == 0 {
-		return fmt.Errorf("extensions not found for mime type: %s", f.Mime)
+	for ext, typ := range officeTypes {
+		mime.AddExtensionType(ext, typ)
+	}
+
+	// many text types have no registered extension, the previews do not
+	// need one and the converters sniff the content anyway
+	var ext string
+	if exts, _ := mime.ExtensionsByType(f.Mime); len(exts) > 0 {
+		ext = exts[0]
 	}
 
-	df, err := os.Create(f.ID.String() + exts[0])
+	input := filepath.Join(dir, f.ID.String()+ext)
+
+	df, err := os.Create(input)
 	if err != nil {
-		return fmt.Errorf("create file: %v", err)
+		return "", fmt.Errorf("create file: %v", err)
 	}
 	defer df.Close()
 
 	if _, err := io.Copy(df, rc); err != nil {
-		return fmt.Errorf("copy file: %v", err)
+		return "", fmt.Errorf("copy file: %v", err)
 	}
 
 	// close the file
 	if err := df.Close(); err != nil {
-		return fmt.Errorf("close file: %v", err)
+		return "", fmt.Errorf("close file: %v", err)
 	}
 
-	input := f.ID.String() + exts[0]
-	fileName := "thumb_" + f.ID.String() + ".png"
+	return input, nil
+}
 
-	// create a thumbnail
-	if err := c.Convert(ctx, input, fileName); err != nil {
-		return fmt.Errorf("convert: %v", err)
+// thumbnail creates in dir and uploads the PNG and WebP thumbnails of one
+// size. WebP is best effort, clients fall back to the PNG when it is missing.
+func (s *service) thumbnail(ctx context.Context, c Converter, dir string, id uuid.UUID, input string, size int) (*file.Thumbnail, error) {
+	var (
+		name = filepath.Join(dir, fmt.Sprintf("thumb_%s_%d", id, size))
+		png  = name + ".png"
+		webp = name + ".webp"
+		t    = &file.Thumbnail{Size: size}
+		err  error
+	)
+
+	if err := c.Convert(ctx, input, png, size); err != nil {
+		return nil, fmt.Errorf("convert: %v", err)
+	}
+
+	t.PNG, err = s.upload(ctx, png, "image/png")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (&WebPConverter{}).Convert(ctx, png, webp, size); err != nil {
+		s.applog.Infof("cannot create webp thumbnail: %v\n", err)
+
+		return t, nil
 	}
 
-	// upload the thumbnail to assets/thumbnails
-	rc, err = os.Open(fileName)
+	t.WebP, err = s.upload(ctx, webp, "image/webp")
 	if err != nil {
-		return fmt.Errorf("open file: %v", err)
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// upload stores a generated image in /assets/images under its base name and
+// returns its public URL.
+func (s *service) upload(ctx context.Context, name string, contentType string) (string, error) {
+	rc, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("open file: %v", err)
 	}
 	defer rc.Close()
 
-	fullPath := filepath.Join("/assets", "images", fileName)
-	if _, err := s.fileService.CreateFile(ctx, rc, fullPath, "image/png"); err != nil {
-		return fmt.Errorf("create file: %v", err)
+	fullPath := filepath.Join("/assets", "images", filepath.Base(name))
+	if _, err := s.fileService.CreateFile(ctx, rc, fullPath, contentType); err != nil {
+		return "", fmt.Errorf("create file: %v", err)
 	}
 
-	// delete the temporary files
-	if err := os.Remove(input); err != nil {
-		return fmt.Errorf("remove file: %v", err)
+	return filepath.Join("/api/assets/images", filepath.Base(name)), nil
+}
+
+// render converts an office document to PDF and stores the full rendition in
+// /assets/renditions. The returned PDF is left next to the input for the
+// thumbnails.
+func (s *service) render(ctx context.Context, o *OfficeConverter, id uuid.UUID, input string) (string, error) {
+	pdf, err := o.ToPDF(ctx, input)
+	if err != nil {
+		return "", err
 	}
 
-	if err := os.Remove(fileName); err != nil {
-		return fmt.Errorf("remove file: %v", err)
+	rc, err := os.Open(pdf)
+	if err != nil {
+		return "", fmt.Errorf("open file: %v", err)
 	}
+	defer rc.Close()
 
-	// update the file record in the database
-	if err := s.fileStore.UpdateThumbnail(ctx, f.ID, filepath.Join("/api/assets/images", fileName)); err != nil {
-		return fmt.Errorf("update thumbnail: %v", err)
+	fullPath := filepath.Join("/assets", "renditions", id.String()+".pdf")
+	if _, err := s.fileService.CreateFile(ctx, rc, fullPath, "application/pdf"); err != nil {
+		return "", fmt.Errorf("create file: %v", err)
+	}
+
+	if err := s.fileStore.UpdateRendition(ctx, id, fmt.Sprintf("/api/files/%s/rendition", id)); err != nil {
+		return "", fmt.Errorf("update rendition: %v", err)
+	}
+
+	return pdf, nil
+}
+
+func (s *service) extract(ctx context.Context, e Extractor, id uuid.UUID, input string) error {
+	media, err := e.Extract(ctx, input)
+	if err != nil {
+		return fmt.Errorf("extract: %v", err)
+	}
+
+	media.FileID = id
+	if err := s.fileStore.UpsertMedia(ctx, media); err != nil {
+		return fmt.Errorf("upsert media: %v", err)
 	}
 
 	return nil
@@ -191,19 +479,22 @@ func getConverter(mime string) Converter {
 		return &VideoConverter{}
 	case strings.HasPrefix(mime, "application/pdf"):
 		return &PDFConverter{}
+	case isOfficeDocument(mime):
+		return &OfficeConverter{}
 	}
 
 	return nil
 }
 
 type Converter interface {
-	Convert(ctx context.Context, input string, output string) error
+	Convert(ctx context.Context, input string, output string, size int) error
 }
 
 type ImageConverter struct{}
 
-func (c *ImageConverter) Convert(ctx context.Context, input string, output string) error {
-	cmd := exec.Command("convert", input, "-resize", "200x200", "-gravity", "center", "-extent", "200x200", output)
+func (c *ImageConverter) Convert(ctx context.Context, input string, output string, size int) error {
+	box := fmt.Sprintf("%dx%d", size, size)
+	cmd := exec.CommandContext(ctx, "convert", input, "-resize", box, "-gravity", "center", "-extent", box, output)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -219,8 +510,9 @@ func (c *ImageConverter) Convert(ctx context.Context, input string, output strin
 
 type VideoConverter struct{}
 
-func (c *VideoConverter) Convert(ctx context.Context, input string, output string) error {
-	cmd := exec.Command("ffmpeg", "-i", input, "-vf", "scale=200:200:force_original_aspect_ratio=decrease,pad=200:200:(ow-iw)/2:(oh-ih)/2", "-vframes", "1", "-update", "true", output)
+func (c *VideoConverter) Convert(ctx context.Context, input string, output string, size int) error {
+	filter := fmt.Sprintf("scale=%[1]d:%[1]d:force_original_aspect_ratio=decrease,pad=%[1]d:%[1]d:(ow-iw)/2:(oh-ih)/2", size)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", input, "-vf", filter, "-vframes", "1", "-update", "true", output)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -236,8 +528,27 @@ func (c *VideoConverter) Convert(ctx context.Context, input string, output strin
 
 type PDFConverter struct{}
 
-func (c *PDFConverter) Convert(ctx context.Context, input string, output string) error {
-	cmd := exec.Command("convert", fmt.Sprintf(`%s[0]`, input), "-resize", "200x200", "-gravity", "center", "-extent", "200x200", output)
+func (c *PDFConverter) Convert(ctx context.Context, input string, output string, size int) error {
+	box := fmt.Sprintf("%dx%d", size, size)
+	cmd := exec.CommandContext(ctx, "convert", fmt.Sprintf(`%s[0]`, input), "-resize", box, "-gravity", "center", "-extent", box, output)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Println(cmd.String(), out.String(), stderr.String())
+
+		return fmt.Errorf("convert: %v", err)
+	}
+
+	return nil
+}
+
+// WebPConverter re-encodes an already generated PNG thumbnail as WebP.
+type WebPConverter struct{}
+
+func (c *WebPConverter) Convert(ctx context.Context, input string, output string, size int) error {
+	cmd := exec.CommandContext(ctx, "convert", input, "-quality", "80", "-define", "webp:method=6", output)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+)
+
+var iso6709Regex = regexp.MustCompile(`^([+-]\d+(?:\.\d+)?)([+-]\d+(?:\.\d+)?)`)
+
+func getExtractor(mime string) Extractor {
+	switch {
+	case strings.HasPrefix(mime, "image"):
+		return &ImageExtractor{}
+	case strings.HasPrefix(mime, "video"), strings.HasPrefix(mime, "audio"):
+		return &ProbeExtractor{}
+	}
+
+	return nil
+}
+
+// Extractor reads the technical metadata of a downloaded file.
+type Extractor interface {
+	Extract(ctx context.Context, input string) (*file.Media, error)
+}
+
+type ImageExtractor struct{}
+
+func (e *ImageExtractor) Extract(ctx context.Context, input string) (*file.Media, error) {
+	format := strings.Join([]string{
+		"%w", "%h",
+		"%[EXIF:DateTimeOriginal]", "%[EXIF:Make]", "%[EXIF:Model]",
+		"%[EXIF:GPSLatitude]", "%[EXIF:GPSLatitudeRef]", "%[EXIF:GPSLongitude]", "%[EXIF:GPSLongitudeRef]",
+	}, "\n")
+
+	cmd := exec.CommandContext(ctx, "identify", "-format", format, fmt.Sprintf(`%s[0]`, input))
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Println(cmd.String(), out.String(), stderr.String())
+
+		return nil, fmt.Errorf("identify: %v", err)
+	}
+
+	fields := strings.Split(out.String(), "\n")
+	for len(fields) < 9 {
+		fields = append(fields, "")
+	}
+
+	media := &file.Media{
+		CameraMake:  strings.TrimSpace(fields[3]),
+		CameraModel: strings.TrimSpace(fields[4]),
+	}
+
+	media.Width, _ = strconv.Atoi(fields[0])
+	media.Height, _ = strconv.Atoi(fields[1])
+
+	if t, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(fields[2])); err == nil {
+		media.CapturedAt = &t
+	}
+
+	media.Latitude = parseGPSCoordinate(fields[5], fields[6])
+	media.Longitude = parseGPSCoordinate(fields[7], fields[8])
+
+	return media, nil
+}
+
+type ProbeExtractor struct{}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType string            `json:"codec_type"`
+		CodecName string            `json:"codec_name"`
+		Width     int               `json:"width"`
+		Height    int               `json:"height"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"streams"`
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+func (e *ProbeExtractor) Extract(ctx context.Context, input string) (*file.Media, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-print_format", "json", "-show_format", "-show_streams", input)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Println(cmd.String(), out.String(), stderr.String())
+
+		return nil, fmt.Errorf("ffprobe: %v", err)
+	}
+
+	var probe probeOutput
+	if err := json.Unmarshal(out.Bytes(), &probe); err != nil {
+		return nil, fmt.Errorf("unmarshal ffprobe output: %v", err)
+	}
+
+	media := &file.Media{}
+	media.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			// cover art in audio files is reported as a video stream as well
+			if media.VideoCodec == "" && stream.Tags["comment"] != "Cover (front)" {
+				media.VideoCodec = stream.CodecName
+				media.Width = stream.Width
+				media.Height = stream.Height
+			}
+		case "audio":
+			if media.AudioCodec == "" {
+				media.AudioCodec = stream.CodecName
+			}
+		}
+	}
+
+	tags := probe.Format.Tags
+	if t, err := time.Parse(time.RFC3339Nano, tags["creation_time"]); err == nil {
+		media.CapturedAt = &t
+	}
+
+	media.CameraMake = tags["com.apple.quicktime.make"]
+	media.CameraModel = tags["com.apple.quicktime.model"]
+
+	location := tags["location"]
+	if location == "" {
+		location = tags["com.apple.quicktime.location.ISO6709"]
+	}
+
+	if m := iso6709Regex.FindStringSubmatch(location); m != nil {
+		lat, _ := strconv.ParseFloat(m[1], 64)
+		lng, _ := strconv.ParseFloat(m[2], 64)
+		media.Latitude, media.Longitude = &lat, &lng
+	}
+
+	return media, nil
+}
+
+// parseGPSCoordinate converts an EXIF "deg/1, min/1, sec/100" coordinate and
+// its N/S/E/W reference to decimal degrees.
+func parseGPSCoordinate(value, ref string) *float64 {
+	parts := strings.Split(strings.TrimSpace(value), ",")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	var dms [3]float64
+	for i, part := range parts {
+		num, den, ok := strings.Cut(strings.TrimSpace(part), "/")
+		if !ok {
+			den = "1"
+		}
+
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return nil
+		}
+
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil || d == 0 {
+			return nil
+		}
+
+		dms[i] = n / d
+	}
+
+	coordinate := dms[0] + dms[1]/60 + dms[2]/3600
+
+	switch strings.ToUpper(strings.TrimSpace(ref)) {
+	case "S", "W":
+		coordinate = -coordinate
+	}
+
+	return &coordinate
+}
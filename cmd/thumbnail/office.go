@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// officeTimeout bounds a conversion, LibreOffice can hang on a broken
+// document and would hold the job until its deadline otherwise.
+const officeTimeout = 5 * time.Minute
+
+// officeTypes maps the document extensions to their mime types, most of them
+// are missing from the minimal mime tables of the alpine image.
+var officeTypes = map[string]string{
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+}
+
+// isOfficeDocument mirrors the 'document' branch of classify_mime_type.
+func isOfficeDocument(mime string) bool {
+	switch {
+	case strings.HasPrefix(mime, "application/vnd.openxmlformats-officedocument"):
+		return true
+	case strings.HasPrefix(mime, "application/vnd.oasis.opendocument"):
+		return true
+	case mime == "application/msword", mime == "application/vnd.ms-excel", mime == "application/vnd.ms-powerpoint":
+		return true
+	}
+
+	return false
+}
+
+// OfficeConverter renders office documents with headless LibreOffice.
+type OfficeConverter struct{}
+
+// ToPDF converts the whole document to PDF next to the input and returns the
+// name of the PDF file.
+func (c *OfficeConverter) ToPDF(ctx context.Context, input string) (string, error) {
+	// LibreOffice refuses to run twice with the same profile, every
+	// conversion gets its own so the processors can run concurrently
+	profile, err := os.MkdirTemp("", "soffice")
+	if err != nil {
+		return "", fmt.Errorf("create profile: %v", err)
+	}
+	defer os.RemoveAll(profile)
+
+	ctx, cancel := context.WithTimeout(ctx, officeTimeout)
+	defer cancel()
+
+	dir := filepath.Dir(input)
+	cmd := exec.CommandContext(ctx, "soffice", "-env:UserInstallation=file://"+profile, "--headless", "--norestore", "--convert-to", "pdf", "--outdir", dir, input)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	// the processes started by soffice may keep the output open once it is
+	// killed
+	cmd.WaitDelay = 10 * time.Second
+	if err := cmd.Run(); err != nil {
+		log.Println(cmd.String(), out.String(), stderr.String())
+
+		return "", fmt.Errorf("convert: %v", err)
+	}
+
+	output := filepath.Join(dir, strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))+".pdf")
+	if _, err := os.Stat(output); err != nil {
+		log.Println(cmd.String(), out.String(), stderr.String())
+
+		return "", fmt.Errorf("convert: %v", err)
+	}
+
+	return output, nil
+}
+
+// Convert renders the first page of the document through the PDF path.
+func (c *OfficeConverter) Convert(ctx context.Context, input string, output string, size int) error {
+	pdf, err := c.ToPDF(ctx, input)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(pdf)
+
+	return (&PDFConverter{}).Convert(ctx, pdf, output, size)
+}
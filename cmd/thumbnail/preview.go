@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
+)
+
+const (
+	// previewBytes is the size of the text snippet stored for text files.
+	previewBytes = 8 << 10
+	// waveformRate is the sample rate the audio is decoded at, enough for a
+	// waveform and much cheaper than the original rate.
+	waveformRate = 8000
+	// waveformPeaks is the number of peaks stored for audio files.
+	waveformPeaks = 1000
+)
+
+type Previewer interface {
+	Preview(ctx context.Context, input string, name string, mime string) (*file.Preview, error)
+}
+
+func getPreviewer(mime string) Previewer {
+	switch {
+	case strings.HasPrefix(mime, "text"), mime == "application/json":
+		return &TextPreviewer{}
+	case strings.HasPrefix(mime, "audio"):
+		return &WaveformPreviewer{}
+	}
+
+	return nil
+}
+
+// TextPreviewer keeps the beginning of text files along with their line count.
+type TextPreviewer struct{}
+
+func (p *TextPreviewer) Preview(ctx context.Context, input string, name string, mime string) (*file.Preview, error) {
+	f, err := os.Open(input)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		r     = bufio.NewReader(f)
+		head  bytes.Buffer
+		buf   = make([]byte, 32<<10)
+		lines int
+		last  byte
+	)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if head.Len() <= previewBytes {
+				head.Write(buf[:min(n, previewBytes+1-head.Len())])
+			}
+
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read file: %v", err)
+		}
+	}
+
+	// the last line usually has no trailing newline
+	if last != 0 && last != '\n' {
+		lines++
+	}
+
+	content, truncated := file.TruncateUTF8(head.Bytes(), previewBytes)
+
+	// postgres rejects invalid UTF-8 and NUL bytes in text columns
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(content), "\uFFFD"), "\x00", "")
+
+	return &file.Preview{
+		Kind:      file.PreviewKindText,
+		Content:   text,
+		Language:  file.DetectLanguage(name, mime),
+		Lines:     lines,
+		Truncated: truncated,
+	}, nil
+}
+
+// WaveformPreviewer computes the peaks drawn by the audio player.
+type WaveformPreviewer struct{}
+
+func (p *WaveformPreviewer) Preview(ctx context.Context, input string, name string, mime string) (*file.Preview, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-loglevel", "error", "-i", input, "-ac", "1", "-ar", fmt.Sprint(waveformRate), "-f", "s16le", "pipe:1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start: %v", err)
+	}
+
+	// one peak per 100ms, reduced to waveformPeaks once the length is known
+	var (
+		r      = bufio.NewReader(stdout)
+		peaks  []float64
+		peak   float64
+		count  int
+		sample int16
+	)
+
+	for {
+		if err := binary.Read(r, binary.LittleEndian, &sample); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+
+			cmd.Process.Kill()
+			cmd.Wait()
+
+			return nil, fmt.Errorf("read samples: %v", err)
+		}
+
+		peak = max(peak, math.Abs(float64(sample))/math.MaxInt16)
+		if count++; count == waveformRate/10 {
+			peaks, peak, count = append(peaks, peak), 0, 0
+		}
+	}
+
+	if count > 0 {
+		peaks = append(peaks, peak)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		log.Println(cmd.String(), stderr.String())
+
+		return nil, fmt.Errorf("convert: %v", err)
+	}
+
+	peaks = file.DownsamplePeaks(peaks, waveformPeaks)
+	for i := range peaks {
+		peaks[i] = math.Round(min(peaks[i], 1)*1000) / 1000
+	}
+
+	return &file.Preview{
+		Kind:  file.PreviewKindWaveform,
+		Peaks: peaks,
+	}, nil
+}
+
+func (s *service) preview(ctx context.Context, p Previewer, id uuid.UUID, mime string, input string) error {
+	f, err := s.fileStore.GetByID(ctx, id.String())
+	if err != nil {
+		return fmt.Errorf("get file: %v", err)
+	}
+
+	preview, err := p.Preview(ctx, input, f.Name, mime)
+	if err != nil {
+		return fmt.Errorf("preview: %v", err)
+	}
+
+	preview.FileID = id
+	if err := s.fileStore.UpsertPreview(ctx, preview); err != nil {
+		return fmt.Errorf("upsert preview: %v", err)
+	}
+
+	return nil
+}
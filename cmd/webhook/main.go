@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SeaCloudHub/backend/adapters/postgrestore"
+	"github.com/SeaCloudHub/backend/adapters/redisstore"
+	"github.com/SeaCloudHub/backend/adapters/services"
+	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/SeaCloudHub/backend/domain/permission"
+	"github.com/SeaCloudHub/backend/domain/pubsub"
+	"github.com/SeaCloudHub/backend/pkg/config"
+	"github.com/SeaCloudHub/backend/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type service struct {
+	maxAttempts       int
+	timeout           time.Duration
+	allowHTTP         bool
+	applog            *zap.SugaredLogger
+	userStore         identity.Store
+	fileStore         file.Store
+	permissionService permission.Service
+	pubsubService     pubsub.Service
+	client            *http.Client
+}
+
+// main sends the events of the files to the webhooks. The changes queued by
+// the API are matched against the webhooks of the directories holding the
+// files, each match is recorded as a delivery, which is retried with backoff
+// until the endpoint accepts it.
+func main() {
+	applog, err := logger.NewAppLogger()
+	if err != nil {
+		log.Fatalf("cannot load config: %v\n", err)
+	}
+	defer logger.Sync(applog)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		applog.Fatal(err)
+	}
+
+	db, err := postgrestore.NewConnection(postgrestore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to db: %v\n", err)
+	}
+
+	redis, err := redisstore.NewConnection(redisstore.ParseFromConfig(cfg))
+	if err != nil {
+		applog.Fatalf("cannot connect to redis: %v\n", err)
+	}
+
+	s := &service{
+		maxAttempts:       cfg.Webhook.MaxAttempts,
+		timeout:           cfg.Webhook.Timeout,
+		allowHTTP:         cfg.Webhook.AllowHTTP,
+		applog:            applog,
+		userStore:         postgrestore.NewUserStore(db),
+		fileStore:         postgrestore.NewFileStore(db),
+		permissionService: services.NewPermissionService(cfg),
+		pubsubService:     redisstore.NewRedisClient(redis),
+		client:            services.NewWebhookClient(cfg.Webhook.Timeout),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	hostname, _ := os.Hostname()
+
+	opts := pubsub.QueueOptions{
+		Group:             "webhook",
+		Consumer:          hostname,
+		MaxAttempts:       cfg.Webhook.MaxAttempts,
+		Backoff:           cfg.Webhook.RetryBackoff,
+		VisibilityTimeout: cfg.Webhook.VisibilityTimeout,
+	}
+
+	events, err := s.pubsubService.Consume(ctx, file.WebhookEventsQueue, opts)
+	if err != nil {
+		applog.Fatalf("cannot consume queue: %v\n", err)
+	}
+
+	deliveries, err := s.pubsubService.Consume(ctx, file.WebhookDeliveriesQueue, opts)
+	if err != nil {
+		applog.Fatalf("cannot consume queue: %v\n", err)
+	}
+
+	applog.Info("webhook worker started!")
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.work(ctx, events, s.match)
+	}()
+
+	for i := 0; i < max(cfg.Webhook.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, deliveries, s.deliver)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// errMalformed is returned for the jobs that will never succeed, they are
+// dead-lettered right away.
+var errMalformed = errors.New("malformed job")
+
+// work processes the jobs of queue until the context is cancelled.
+func (s *service) work(ctx context.Context, queue pubsub.Queue, process func(ctx context.Context, job pubsub.Job) error) {
+	for {
+		job, err := queue.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.applog.Errorf("cannot receive job: %v\n", err)
+			time.Sleep(time.Second)
+
+			continue
+		}
+
+		err = process(ctx, job)
+		switch {
+		case err == nil:
+			err = queue.Ack(ctx, job)
+		case errors.Is(err, errMalformed):
+			err = queue.DeadLetter(ctx, job, err)
+		default:
+			s.applog.Infof("cannot process job %s of %s (attempt %d): %v\n", job.ID, job.Queue, job.Attempts+1, err)
+			err = queue.Retry(ctx, job, err)
+		}
+
+		if err != nil {
+			s.applog.Errorf("cannot settle job %s of %s: %v\n", job.ID, job.Queue, err)
+		}
+	}
+}
+
+// match records a delivery for every webhook the changes of the job are sent
+// to and queues them.
+func (s *service) match(ctx context.Context, job pubsub.Job) error {
+	var changes []file.Change
+	if err := json.Unmarshal([]byte(job.Payload), &changes); err != nil {
+		return fmt.Errorf("%w: %v", errMalformed, err)
+	}
+
+	var deliveries []file.WebhookDelivery
+
+	for i := range changes {
+		c := &changes[i]
+
+		webhooks, err := s.fileStore.ListWebhooksByDirectories(ctx, c.Dirs())
+		if err != nil {
+			return fmt.Errorf("list webhooks: %w", err)
+		}
+
+		eventType := file.WebhookEventType(c.Type)
+
+		for _, w := range webhooks {
+			if !w.Subscribes(eventType) {
+				continue
+			}
+
+			canView, err := s.canView(ctx, &w)
+			if err != nil {
+				return err
+			}
+
+			if !canView {
+				continue
+			}
+
+			payload, err := json.Marshal(file.NewWebhookEvent(&w, c))
+			if err != nil {
+				return fmt.Errorf("marshal event: %w", err)
+			}
+
+			deliveries = append(deliveries, *file.NewWebhookDelivery(w.ID, eventType, string(payload)))
+		}
+	}
+
+	if err := s.fileStore.CreateWebhookDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("create deliveries: %w", err)
+	}
+
+	// the job is not retried once the deliveries exist, a delivery that could
+	// not be queued stays pending until it is redelivered
+	for _, d := range deliveries {
+		if err := s.pubsubService.Enqueue(ctx, file.WebhookDeliveriesQueue, d.ID.String()); err != nil {
+			s.applog.Errorf("cannot enqueue delivery %s: %v\n", d.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// canView reports whether the user of the webhook can still view its
+// directory, the webhooks of the admins see every directory.
+func (s *service) canView(ctx context.Context, w *file.Webhook) (bool, error) {
+	user, err := s.userStore.GetByID(ctx, w.UserID.String())
+	if err != nil {
+		if errors.Is(err, identity.ErrIdentityNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get user: %w", err)
+	}
+
+	if user.IsAdmin {
+		return true, nil
+	}
+
+	canView, err := s.permissionService.CanViewDirectory(ctx, user.ID.String(), w.DirectoryID.String())
+	if err != nil {
+		return false, fmt.Errorf("check permission: %w", err)
+	}
+
+	return canView, nil
+}
+
+// deliver sends the delivery of the job and records the outcome, the job is
+// retried until the last attempt.
+func (s *service) deliver(ctx context.Context, job pubsub.Job) error {
+	id, err := uuid.Parse(job.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMalformed, err)
+	}
+
+	d, err := s.fileStore.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		// deleted with its webhook
+		if errors.Is(err, file.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("get delivery: %w", err)
+	}
+
+	// settled already, e.g. by a consumer that crashed before acknowledging it
+	if d.Status != file.DeliveryPending {
+		return nil
+	}
+
+	w, err := s.fileStore.GetWebhook(ctx, d.WebhookID)
+	if err != nil {
+		if errors.Is(err, file.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("get webhook: %w", err)
+	}
+
+	// the host may resolve to other addresses since the webhook was created,
+	// such a delivery fails right away
+	if err := services.CheckWebhookURL(ctx, w.URL, s.allowHTTP); err != nil {
+		d.Attempt(0, err, true)
+		if err := s.fileStore.UpdateWebhookDelivery(ctx, d); err != nil {
+			s.applog.Errorf("cannot update delivery %s: %v\n", d.ID, err)
+		}
+
+		return nil
+	}
+
+	statusCode, cause := s.post(ctx, w, d)
+
+	// the address the client refused is not disclosed in the delivery log
+	if errors.Is(cause, file.ErrWebhookURLNotAllowed) {
+		cause = file.ErrWebhookURLNotAllowed
+	}
+
+	d.Attempt(statusCode, cause, job.Attempts+1 >= s.maxAttempts)
+	if err := s.fileStore.UpdateWebhookDelivery(ctx, d); err != nil {
+		s.applog.Errorf("cannot update delivery %s: %v\n", d.ID, err)
+	}
+
+	return cause
+}
+
+// post sends the payload of d to the URL of w, any status other than 2xx is
+// a failure. The client only connects to public addresses and does not follow
+// redirects.
+func (s *service) post(ctx context.Context, w *file.Webhook, d *file.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, strings.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SeaCloud-Webhook")
+	req.Header.Set("X-SeaCloud-Event", d.Event)
+	req.Header.Set("X-SeaCloud-Delivery", d.ID.String())
+	req.Header.Set("X-SeaCloud-Signature", w.Sign([]byte(d.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drained so that the connection is reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
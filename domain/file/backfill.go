@@ -0,0 +1,54 @@
+package file
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BackfillSucceeded = "succeeded"
+	BackfillFailed    = "failed"
+	BackfillSkipped   = "skipped"
+)
+
+// BackfillFilter selects the files that are sent to the thumbnail worker again.
+type BackfillFilter struct {
+	Type    string     `json:"type,omitempty"`
+	After   *time.Time `json:"after,omitempty"`
+	Before  *time.Time `json:"before,omitempty"`
+	Missing bool       `json:"missing"`
+}
+
+// Backfill tracks one run of thumbnail regeneration. Total is only final once
+// every matching file has been enqueued, the counters are updated by the worker.
+type Backfill struct {
+	ID         uuid.UUID      `json:"id"`
+	Filter     BackfillFilter `json:"filter"`
+	Total      int            `json:"total"`
+	Succeeded  int            `json:"succeeded"`
+	Failed     int            `json:"failed"`
+	Skipped    int            `json:"skipped"`
+	CreatedBy  *uuid.UUID     `json:"created_by"`
+	EnqueuedAt *time.Time     `json:"enqueued_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+} // @name file.Backfill
+
+func NewBackfill(filter BackfillFilter, createdBy *uuid.UUID) *Backfill {
+	return &Backfill{
+		ID:        uuid.New(),
+		Filter:    filter,
+		CreatedBy: createdBy,
+	}
+}
+
+// Processed returns the number of files the worker is done with.
+func (b *Backfill) Processed() int {
+	return b.Succeeded + b.Failed + b.Skipped
+}
+
+// Done reports whether every enqueued file has been processed.
+func (b *Backfill) Done() bool {
+	return b.EnqueuedAt != nil && b.Processed() >= b.Total
+}
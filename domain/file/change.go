@@ -0,0 +1,108 @@
+package file
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/google/uuid"
+)
+
+const (
+	ChangeCreate     = "create"
+	ChangeUpdate     = "update"
+	ChangeMove       = "move"
+	ChangeRename     = "rename"
+	ChangeTrash      = "trash"
+	ChangeRestore    = "restore"
+	ChangeDelete     = "delete"
+	ChangePermission = "permission"
+)
+
+// Change is an entry of the change journal of a user. The IDs of the entries
+// of a user only grow, a sync client asks for the changes after the last one
+// it has seen.
+type Change struct {
+	ID        int64     `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	FileID    uuid.UUID `json:"file_id"`
+	Type      string    `json:"type"`
+	Path      string    `json:"path"`                // at the time of the change
+	FromPath  string    `json:"from_path,omitempty"` // before a move or a rename
+	IsDir     bool      `json:"is_dir"`
+	CreatedAt time.Time `json:"created_at"`
+
+	File *File `json:"file,omitempty"` // nil once deleted
+} // @name file.Change
+
+// NewChange records a change of f in the journal of the user whose tree
+// holds it.
+func NewChange(f *File, _type string) Change {
+	return Change{
+		UserID: f.TreeOwnerID(),
+		FileID: f.ID,
+		Type:   _type,
+		Path:   f.FullPath(),
+		IsDir:  f.IsDir,
+	}
+}
+
+// NewMoveChanges records the move of f, which is at its new path already,
+// out of the directory src. A file moved to the tree of another user leaves
+// the tree of src.
+func NewMoveChanges(src *File, f *File, _type string) []Change {
+	change := NewChange(f, _type).WithFromPath(path.Join(src.FullPath(), f.Name))
+	if srcOwnerID := src.TreeOwnerID(); srcOwnerID != change.UserID {
+		return []Change{change, change.WithUserID(srcOwnerID)}
+	}
+
+	return []Change{change}
+}
+
+// WithUserID records the change in the journal of another user, such as the
+// users a file is shared with.
+func (c Change) WithUserID(userID uuid.UUID) Change {
+	c.UserID = userID
+
+	return c
+}
+
+// WithFromPath records where the file was before it was moved or renamed.
+func (c Change) WithFromPath(fromPath string) Change {
+	c.FromPath = fromPath
+
+	return c
+}
+
+// IsCopy reports whether the change is a copy in the journal of a user other
+// than the one whose tree holds the file.
+func (c *Change) IsCopy() bool {
+	return c.UserID != treeOwnerID(c.Path)
+}
+
+func (c *Change) Response() *Change {
+	c.Path = app.RemoveRootPath(c.Path)
+	if c.FromPath != "" {
+		c.FromPath = app.RemoveRootPath(c.FromPath)
+	}
+	if c.File != nil {
+		c.File.Response()
+	}
+
+	return c
+}
+
+// TreeOwnerID returns the ID of the user whose root holds f, the roots are
+// named after their users.
+func (f *File) TreeOwnerID() uuid.UUID {
+	return treeOwnerID(f.FullPath())
+}
+
+func treeOwnerID(fullPath string) uuid.UUID {
+	name, _, _ := strings.Cut(strings.TrimPrefix(fullPath, "/"), "/")
+
+	id, _ := uuid.Parse(name)
+
+	return id
+}
@@ -0,0 +1,83 @@
+package file
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/google/uuid"
+)
+
+var mentionRegex = regexp.MustCompile(`(?:^|\s)@([a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,})`)
+
+// Comment is a message on a file. Comments without a parent start a thread,
+// replies point to the thread they belong to.
+type Comment struct {
+	ID         uuid.UUID  `json:"id"`
+	FileID     uuid.UUID  `json:"file_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	ParentID   *uuid.UUID `json:"parent_id"`
+	Content    string     `json:"content"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	User    *identity.User `json:"user,omitempty"`
+	Replies []Comment      `json:"replies,omitempty"`
+} // @name file.Comment
+
+func NewComment(fileID, userID uuid.UUID, content string) *Comment {
+	return &Comment{
+		ID:      uuid.New(),
+		FileID:  fileID,
+		UserID:  userID,
+		Content: content,
+	}
+}
+
+func (c *Comment) WithParentID(parentID *uuid.UUID) *Comment {
+	c.ParentID = parentID
+
+	return c
+}
+
+func (c *Comment) IsThread() bool {
+	return c.ParentID == nil
+}
+
+func (c *Comment) Resolve(userID uuid.UUID) *Comment {
+	now := time.Now()
+	c.ResolvedAt = &now
+	c.ResolvedBy = &userID
+
+	return c
+}
+
+func (c *Comment) Reopen() *Comment {
+	c.ResolvedAt = nil
+	c.ResolvedBy = nil
+
+	return c
+}
+
+// Mentions returns the distinct emails mentioned as @email in the content.
+func (c *Comment) Mentions() []string {
+	var (
+		emails []string
+		seen   = make(map[string]struct{})
+	)
+
+	for _, match := range mentionRegex.FindAllStringSubmatch(c.Content, -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if _, ok := seen[email]; ok {
+			continue
+		}
+
+		seen[email] = struct{}{}
+		emails = append(emails, email)
+	}
+
+	return emails
+}
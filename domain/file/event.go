@@ -0,0 +1,95 @@
+package file
+
+import (
+	"path"
+	"time"
+
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/google/uuid"
+)
+
+// EventsChannel carries the events of the files to the connected clients, the
+// last ones are kept in a stream of the same name for the clients that
+// reconnect.
+const EventsChannel = "events"
+
+// EventThumbnail tells that the thumbnails of a file are ready, the other
+// events are named after the changes.
+const EventThumbnail = "thumbnail"
+
+// Event tells the clients about a file. The viewers of the directory of the
+// file, before or after a move, receive it, or only UserID when it is set.
+type Event struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	FileID    uuid.UUID  `json:"file_id"`
+	Path      string     `json:"path"`
+	FromPath  string     `json:"from_path,omitempty"` // before a move or a rename
+	IsDir     bool       `json:"is_dir"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+} // @name file.Event
+
+func NewEvent(f *File, _type string) Event {
+	return Event{
+		Type:   _type,
+		FileID: f.ID,
+		Path:   f.FullPath(),
+		IsDir:  f.IsDir,
+	}
+}
+
+// NewChangeEvent announces a change to the viewers of the directory of the
+// file, or only to the user of the journal when it is not the one of the
+// tree holding the file, such as the users the file is shared with.
+func NewChangeEvent(c Change) Event {
+	e := Event{
+		Type:     c.Type,
+		FileID:   c.FileID,
+		Path:     c.Path,
+		FromPath: c.FromPath,
+		IsDir:    c.IsDir,
+	}
+
+	if c.IsCopy() {
+		e = e.WithUserID(c.UserID)
+	}
+
+	return e
+}
+
+// WithUserID sends the event to a single user.
+func (e Event) WithUserID(userID uuid.UUID) Event {
+	e.UserID = &userID
+
+	return e
+}
+
+// Dir returns the full path of the directory of the file.
+func (e *Event) Dir() string {
+	return path.Dir(e.Path)
+}
+
+// FromDir returns the full path of the directory of the file before a move or
+// a rename, empty for the other events.
+func (e *Event) FromDir() string {
+	if e.FromPath == "" {
+		return ""
+	}
+
+	return path.Dir(e.FromPath)
+}
+
+// TreeOwnerID returns the ID of the user whose tree holds the file.
+func (e *Event) TreeOwnerID() uuid.UUID {
+	return treeOwnerID(e.Path)
+}
+
+func (e *Event) Response() *Event {
+	e.Path = app.RemoveRootPath(e.Path)
+	if e.FromPath != "" {
+		e.FromPath = app.RemoveRootPath(e.FromPath)
+	}
+
+	return e
+}
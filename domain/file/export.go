@@ -0,0 +1,147 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SeaCloudHub/backend/domain/identity"
+	"github.com/google/uuid"
+)
+
+const (
+	ExportPending   = "pending"
+	ExportSucceeded = "succeeded"
+	ExportFailed    = "failed"
+)
+
+// ExportsDir holds the archives until they expire.
+const ExportsDir = "/exports"
+
+// ExportStaleAfter is how long an export can stay pending. An older pending
+// export was interrupted, e.g. by a restart, and is considered failed.
+const ExportStaleAfter = 6 * time.Hour
+
+// Export is an archive of the tree of a user with a manifest of its metadata.
+// Token authorizes the download link, which works until ExpiresAt.
+type Export struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	Files       int        `json:"files"`
+	Size        uint64     `json:"size"`
+	Error       string     `json:"error"`
+	Token       string     `json:"-"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedBy   *uuid.UUID `json:"created_by"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+} // @name file.Export
+
+func NewExport(userID uuid.UUID, token string, createdBy *uuid.UUID) *Export {
+	return &Export{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    ExportPending,
+		Token:     token,
+		CreatedBy: createdBy,
+	}
+}
+
+// StoragePath returns where the archive is stored.
+func (e *Export) StoragePath() string {
+	return fmt.Sprintf("%s/%s.zip", ExportsDir, e.ID)
+}
+
+// Available reports whether the archive can be downloaded at now.
+func (e *Export) Available(now time.Time) bool {
+	return e.Status == ExportSucceeded && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+// Pending reports whether the archive is still being written at now.
+func (e *Export) Pending(now time.Time) bool {
+	return e.Status == ExportPending && now.Sub(e.CreatedAt) < ExportStaleAfter
+}
+
+// Response sets the download link of an available archive.
+func (e *Export) Response() *Export {
+	if e.Available(time.Now()) {
+		e.DownloadURL = fmt.Sprintf("/api/exports/%s/download?token=%s", e.ID, e.Token)
+	}
+
+	return e
+}
+
+// Finish records the outcome of the archive, it expires ttl after now.
+func (e *Export) Finish(err error, ttl time.Duration) {
+	now := time.Now()
+
+	e.Status = ExportSucceeded
+	if err != nil {
+		e.Status = ExportFailed
+		e.Error = err.Error()
+	} else {
+		expiresAt := now.Add(ttl)
+		e.ExpiresAt = &expiresAt
+	}
+
+	e.FinishedAt = &now
+}
+
+// Manifest describes the content of an export archive, it is stored as
+// manifest.json next to the files directory.
+type Manifest struct {
+	Version   int            `json:"version"`
+	User      ManifestUser   `json:"user"`
+	Files     []ManifestFile `json:"files"`
+	Stars     []ManifestStar `json:"stars"`
+	Logs      []ManifestLog  `json:"logs"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type ManifestUser struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+}
+
+func NewManifestUser(user *identity.User) ManifestUser {
+	return ManifestUser{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+}
+
+// ManifestFile is a file of the archive, Path is relative to the files
+// directory. Missing is set when the content could not be exported.
+type ManifestFile struct {
+	ID            uuid.UUID              `json:"id"`
+	Path          string                 `json:"path"`
+	IsDir         bool                   `json:"is_dir"`
+	Size          uint64                 `json:"size"`
+	MimeType      string                 `json:"mime_type"`
+	MD5           string                 `json:"md5"`
+	GeneralAccess string                 `json:"general_access"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	Shares        []Share                `json:"shares"`
+	Missing       bool                   `json:"missing,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// ManifestStar is a starred file, which may belong to another user and not
+// be part of the archive.
+type ManifestStar struct {
+	FileID uuid.UUID `json:"file_id"`
+	Name   string    `json:"name"`
+}
+
+type ManifestLog struct {
+	FileID    uuid.UUID `json:"file_id"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+}
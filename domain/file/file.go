@@ -2,8 +2,10 @@ package file
 
 import (
 	"context"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/SeaCloudHub/backend/domain/identity"
@@ -35,6 +37,43 @@ type Store interface {
 	UpdatePath(ctx context.Context, fileID uuid.UUID, path string) error
 	UpdateName(ctx context.Context, fileID uuid.UUID, name string) error
 	UpdateThumbnail(ctx context.Context, fileID uuid.UUID, thumbnail string) error
+	UpdateThumbnails(ctx context.Context, fileID uuid.UUID, thumbnails []Thumbnail) error
+	UpdateRendition(ctx context.Context, fileID uuid.UUID, rendition string) error
+	UpdateStream(ctx context.Context, fileID uuid.UUID, stream string) error
+	ListThumbnailsByURLs(ctx context.Context, urls []string) ([]string, error)
+	ListBackfillFiles(ctx context.Context, filter BackfillFilter, cursor *pagination.Cursor) ([]File, error)
+	CreateBackfill(ctx context.Context, backfill *Backfill) error
+	GetBackfill(ctx context.Context, id uuid.UUID) (*Backfill, error)
+	ListBackfills(ctx context.Context, cursor *pagination.Cursor) ([]Backfill, error)
+	FinishBackfillEnqueue(ctx context.Context, id uuid.UUID, total int) error
+	IncrementBackfill(ctx context.Context, id uuid.UUID, result string) error
+	ListScrubFiles(ctx context.Context, cursor *pagination.Cursor) ([]File, error)
+	ListExistingIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+	RepairFile(ctx context.Context, fileID uuid.UUID, size uint64, md5 []byte) error
+	QuarantineFile(ctx context.Context, fileID uuid.UUID) error
+	CreateScrub(ctx context.Context, scrub *Scrub) error
+	GetScrub(ctx context.Context, id uuid.UUID) (*Scrub, error)
+	ListScrubs(ctx context.Context, cursor *pagination.Cursor) ([]Scrub, error)
+	UpdateScrub(ctx context.Context, scrub *Scrub) error
+	CreateScrubIssues(ctx context.Context, issues []ScrubIssue) error
+	ListScrubIssues(ctx context.Context, scrubID uuid.UUID, kind string, cursor *pagination.Cursor) ([]ScrubIssue, error)
+	CreateExport(ctx context.Context, export *Export) error
+	GetExport(ctx context.Context, id uuid.UUID) (*Export, error)
+	GetPendingExport(ctx context.Context, userID uuid.UUID) (*Export, error)
+	ListExports(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]Export, error)
+	ListExportsByIDs(ctx context.Context, ids []uuid.UUID) ([]Export, error)
+	UpdateExport(ctx context.Context, export *Export) error
+	ListSharesByFileIDs(ctx context.Context, fileIDs []uuid.UUID) ([]Share, error)
+	CreateMultipartUpload(ctx context.Context, upload *MultipartUpload) error
+	GetMultipartUpload(ctx context.Context, id uuid.UUID) (*MultipartUpload, error)
+	ListMultipartUploadsByIDs(ctx context.Context, ids []uuid.UUID) ([]MultipartUpload, error)
+	ListStaleMultipartUploads(ctx context.Context, before time.Time, limit int) ([]MultipartUpload, error)
+	DeleteMultipartUpload(ctx context.Context, id uuid.UUID) error
+	ReserveMultipartStorage(ctx context.Context, id uuid.UUID, size uint64) error
+	ReleaseMultipartStorage(ctx context.Context, id uuid.UUID, size uint64) error
+	UpsertUploadPart(ctx context.Context, part *UploadPart) error
+	ListUploadParts(ctx context.Context, uploadID uuid.UUID) ([]UploadPart, error)
+	UpdateMetadata(ctx context.Context, fileID uuid.UUID, metadata map[string]interface{}) error
 	UpdateChunk(ctx context.Context, fileID uuid.UUID, size uint64, last bool) (*File, error)
 	MoveToTrash(ctx context.Context, fileID uuid.UUID, path string) error
 	RestoreFromTrash(ctx context.Context, fileID uuid.UUID, path string) error
@@ -57,33 +96,81 @@ type Store interface {
 	DeleteStarByUserID(ctx context.Context, userID uuid.UUID) error
 	WriteLogs(ctx context.Context, logs []Log) error
 	ReadLogs(ctx context.Context, userID string, cursor *pagination.Cursor) ([]Log, error)
+	WriteChanges(ctx context.Context, changes []Change) error
+	ListChanges(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]Change, bool, error)
+	GetChangesStartToken(ctx context.Context, userID uuid.UUID) (string, error)
+	CreateWebhook(ctx context.Context, webhook *Webhook) error
+	GetWebhook(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	ListWebhooks(ctx context.Context, userID uuid.UUID) ([]Webhook, error)
+	ListWebhooksByDirectories(ctx context.Context, fullPaths []string) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	CreateWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (*WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, cursor *pagination.Cursor) ([]WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
 	ListSuggested(ctx context.Context, userID uuid.UUID, limit int, isDir bool) ([]File, error)
 	ListActivities(ctx context.Context, fileID uuid.UUID, cursor *pagination.Cursor) ([]Log, error)
+	CreateSmartFolder(ctx context.Context, folder *SmartFolder) error
+	GetSmartFolder(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*SmartFolder, error)
+	ListSmartFolders(ctx context.Context, userID uuid.UUID, cursor *pagination.Cursor) ([]SmartFolder, error)
+	ListSmartFoldersByParent(ctx context.Context, userID uuid.UUID, parentID uuid.UUID) ([]SmartFolder, error)
+	UpdateSmartFolder(ctx context.Context, folder *SmartFolder) error
+	DeleteSmartFolder(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	DeleteSmartFoldersByUserID(ctx context.Context, userID uuid.UUID) error
+	CreateTag(ctx context.Context, tag *Tag) error
+	GetTag(ctx context.Context, id uuid.UUID) (*Tag, error)
+	ListTags(ctx context.Context, userID uuid.UUID) ([]Tag, error)
+	UpdateTag(ctx context.Context, tag *Tag) error
+	DeleteTag(ctx context.Context, id uuid.UUID) error
+	TagFiles(ctx context.Context, tagID uuid.UUID, fileIDs []uuid.UUID) error
+	UntagFiles(ctx context.Context, tagID uuid.UUID, fileIDs []uuid.UUID) error
+	ListFileTags(ctx context.Context, fileID uuid.UUID, userID uuid.UUID) ([]Tag, error)
+	CountTaggedFilesNotOwnedBy(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) (int64, error)
+	DeleteFileTagsByFileID(ctx context.Context, fileID uuid.UUID) error
+	DeleteTagsByUserID(ctx context.Context, userID uuid.UUID) error
+	CreateComment(ctx context.Context, comment *Comment) error
+	GetComment(ctx context.Context, id uuid.UUID) (*Comment, error)
+	ListComments(ctx context.Context, fileID uuid.UUID, cursor *pagination.Cursor) ([]Comment, error)
+	UpdateComment(ctx context.Context, comment *Comment) error
+	DeleteComment(ctx context.Context, id uuid.UUID) error
+	DeleteCommentsByFileID(ctx context.Context, fileID uuid.UUID) error
+	UpsertMedia(ctx context.Context, media *Media) error
+	GetMedia(ctx context.Context, fileID uuid.UUID) (*Media, error)
+	DeleteMediaByFileID(ctx context.Context, fileID uuid.UUID) error
+	UpsertPreview(ctx context.Context, preview *Preview) error
+	GetPreview(ctx context.Context, fileID uuid.UUID) (*Preview, error)
+	DeletePreviewByFileID(ctx context.Context, fileID uuid.UUID) error
 }
 
 type File struct {
-	ID            uuid.UUID   `json:"id"`
-	Name          string      `json:"name"`
-	Path          string      `json:"path"`
-	ShownPath     string      `json:"shown_path"`
-	PreviousPath  *string     `json:"-"`
-	Size          uint64      `json:"size"`
-	Mode          os.FileMode `json:"mode"`
-	MimeType      string      `json:"mime_type"`
-	Type          string      `json:"type"`
-	Thumbnail     *string     `json:"thumbnail"`
-	MD5           []byte      `json:"md5"`
-	IsDir         bool        `json:"is_dir"`
-	GeneralAccess string      `json:"general_access"`
-	OwnerID       uuid.UUID   `json:"owner_id"`
-	CreatedAt     time.Time   `json:"created_at"`
-	UpdatedAt     time.Time   `json:"updated_at"`
+	ID            uuid.UUID              `json:"id"`
+	Name          string                 `json:"name"`
+	Path          string                 `json:"path"`
+	ShownPath     string                 `json:"shown_path"`
+	PreviousPath  *string                `json:"-"`
+	Size          uint64                 `json:"size"`
+	Mode          os.FileMode            `json:"mode"`
+	MimeType      string                 `json:"mime_type"`
+	Type          string                 `json:"type"`
+	Thumbnail     *string                `json:"thumbnail"`
+	Thumbnails    []Thumbnail            `json:"thumbnails"`
+	Rendition     *string                `json:"rendition"`
+	Stream        *string                `json:"stream"`
+	MD5           []byte                 `json:"md5"`
+	IsDir         bool                   `json:"is_dir"`
+	GeneralAccess string                 `json:"general_access"`
+	OwnerID       uuid.UUID              `json:"owner_id"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	QuarantinedAt *time.Time             `json:"quarantined_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
 
 	Owner     *identity.User `json:"owner,omitempty"`
 	Parent    *SimpleFile    `json:"parent,omitempty"`
 	Log       *Log           `json:"log,omitempty"`
 	UserRoles []string       `json:"userRoles"`
 	IsStarred bool           `json:"is_starred"`
+	Tags      []Tag          `json:"tags"`
 
 	more bool
 } // @name file.File
@@ -99,6 +186,13 @@ func NewDirectory(name string) *File {
 	}
 }
 
+// NewFile returns a file whose content is not stored yet.
+func NewFile(name string) *File {
+	return &File{
+		Name: name,
+	}
+}
+
 func (f *File) WithID(id uuid.UUID) *File {
 	f.ID = id
 
@@ -129,12 +223,25 @@ func (f *File) WithIsStarred(isStarred bool) *File {
 	return f
 }
 
+func (f *File) WithTags(tags []Tag) *File {
+	f.Tags = tags
+
+	return f
+}
+
 func (f *File) WithOwnerID(ownerID uuid.UUID) *File {
 	f.OwnerID = ownerID
 
 	return f
 }
 
+// Quarantined reports whether the content failed an integrity check. The
+// content of a quarantined file cannot be trusted, so it is neither served nor
+// copied until it is repaired.
+func (f *File) Quarantined() bool {
+	return f.QuarantinedAt != nil
+}
+
 func (f *File) FullPath() string {
 	return filepath.Join(f.Path, f.Name)
 }
@@ -180,6 +287,27 @@ func (f *File) WithThumbnail(thumbnail *string) *File {
 	return f
 }
 
+// MaxMetadataKeys is the number of keys the metadata of a file can hold.
+const MaxMetadataKeys = 100
+
+// PatchMetadata merges patch into the file metadata, a null value removes the key.
+func (f *File) PatchMetadata(patch map[string]interface{}) *File {
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]interface{}, len(patch))
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(f.Metadata, key)
+			continue
+		}
+
+		f.Metadata[key] = value
+	}
+
+	return f
+}
+
 func (f *File) IsRoot() bool {
 	return f.Path == "" || f.Path == "/"
 }
@@ -208,9 +336,12 @@ type Stars struct {
 } // @name file.Stars
 
 type Filter struct {
-	Type  string
-	After *time.Time
-	Path  string
+	Type     string
+	After    *time.Time
+	Path     string
+	Tag      *uuid.UUID
+	Metadata map[string]string
+	Sort     string
 }
 
 func NewFilter(_type string, after *time.Time) Filter {
@@ -226,6 +357,38 @@ func (f Filter) WithPath(path string) Filter {
 	return f
 }
 
+func (f Filter) WithTag(tag *uuid.UUID) Filter {
+	f.Tag = tag
+
+	return f
+}
+
+func (f Filter) WithSort(sort string) Filter {
+	f.Sort = sort
+
+	return f
+}
+
+// WithMetadata picks the meta.key=value pairs out of the query parameters. A
+// value also matches the number or boolean it spells, meta.count=3 matches
+// both "3" and 3.
+func (f Filter) WithMetadata(params url.Values) Filter {
+	for param, values := range params {
+		key, ok := strings.CutPrefix(param, "meta.")
+		if !ok || key == "" || len(values) == 0 {
+			continue
+		}
+
+		if f.Metadata == nil {
+			f.Metadata = make(map[string]string)
+		}
+
+		f.Metadata[key] = values[0]
+	}
+
+	return f
+}
+
 type Log struct {
 	FileID    uuid.UUID `json:"file_id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -252,6 +415,7 @@ var (
 	LogActionMove    = "move"
 	LogActionShare   = "share"
 	LogActionStar    = "star"
+	LogActionComment = "comment"
 	SuggestedActions = []string{LogActionOpen, LogActionCreate, LogActionUpdate, LogActionDelete}
 )
 
@@ -1,9 +1,13 @@
 package file_test
 
 import (
+	"net/url"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/SeaCloudHub/backend/domain/file"
+	"github.com/google/uuid"
 )
 
 func TestParents(t *testing.T) {
@@ -34,3 +38,300 @@ func TestParents(t *testing.T) {
 		}
 	}
 }
+
+func TestPatchMetadata(t *testing.T) {
+	f := file.File{Metadata: map[string]interface{}{"client": "acme", "status": "draft"}}
+	f.PatchMetadata(map[string]interface{}{"status": "approved", "client": nil, "code": "P-42"})
+
+	want := map[string]interface{}{"status": "approved", "code": "P-42"}
+	if len(f.Metadata) != len(want) {
+		t.Fatalf("PatchMetadata() = %v; want %v", f.Metadata, want)
+	}
+
+	for k, v := range want {
+		if f.Metadata[k] != v {
+			t.Errorf("PatchMetadata()[%q] = %v; want %v", k, f.Metadata[k], v)
+		}
+	}
+}
+
+func TestFilterWithMetadata(t *testing.T) {
+	params := url.Values{
+		"meta.client": {"acme"},
+		"meta.":       {"ignored"},
+		"query":       {"report"},
+	}
+
+	got := file.Filter{}.WithMetadata(params).Metadata
+	if len(got) != 1 || got["client"] != "acme" {
+		t.Errorf("WithMetadata(%v) = %v; want map[client:acme]", params, got)
+	}
+
+	if got := (file.Filter{}).WithMetadata(url.Values{"type": {"pdf"}}).Metadata; got != nil {
+		t.Errorf("WithMetadata() = %v; want nil", got)
+	}
+}
+
+func TestCommentMentions(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"@alice@example.com please review", []string{"alice@example.com"}},
+		{"cc @Bob@Example.com and @alice@example.com, @bob@example.com.", []string{"bob@example.com", "alice@example.com"}},
+		{"mail me at carol@example.com", nil},
+		{"no mentions here", nil},
+	}
+
+	for _, tt := range tests {
+		got := file.NewComment(uuid.New(), uuid.New(), tt.content).Mentions()
+		if len(got) != len(tt.want) {
+			t.Errorf("Mentions(%q) = %v; want %v", tt.content, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Mentions(%q) = %v; want %v", tt.content, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPickThumbnail(t *testing.T) {
+	thumbnails := []file.Thumbnail{{Size: 200}, {Size: 64}, {Size: 800}}
+
+	tests := []struct {
+		size int
+		want int
+	}{
+		{32, 64},
+		{64, 64},
+		{100, 200},
+		{200, 200},
+		{500, 800},
+		{1600, 800},
+	}
+
+	for _, tt := range tests {
+		got := file.PickThumbnail(thumbnails, tt.size)
+		if got == nil || got.Size != tt.want {
+			t.Errorf("PickThumbnail(%d) = %v; want size %d", tt.size, got, tt.want)
+		}
+	}
+
+	if got := file.PickThumbnail(nil, 200); got != nil {
+		t.Errorf("PickThumbnail(nil) = %v; want nil", got)
+	}
+}
+
+func TestBackfillDone(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		backfill file.Backfill
+		want     bool
+	}{
+		{file.Backfill{Total: 0}, false},
+		{file.Backfill{Total: 0, EnqueuedAt: &now}, true},
+		{file.Backfill{Total: 3, Succeeded: 3}, false},
+		{file.Backfill{Total: 3, Succeeded: 1, Failed: 1, EnqueuedAt: &now}, false},
+		{file.Backfill{Total: 3, Succeeded: 1, Failed: 1, Skipped: 1, EnqueuedAt: &now}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.backfill.Done(); got != tt.want {
+			t.Errorf("Done() of %+v = %v; want %v", tt.backfill, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		in        string
+		n         int
+		want      string
+		truncated bool
+	}{
+		{"hello", 10, "hello", false},
+		{"hello", 5, "hello", false},
+		{"hello", 3, "hel", true},
+		{"héllo", 2, "h", true},
+		{"héllo", 3, "hé", true},
+		{"日本語", 4, "日", true},
+		{"日本語", 5, "日", true},
+		{"日本語", 6, "日本", true},
+	}
+
+	for _, tt := range tests {
+		got, truncated := file.TruncateUTF8([]byte(tt.in), tt.n)
+		if string(got) != tt.want || truncated != tt.truncated {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, %v; want %q, %v", tt.in, tt.n, got, truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		mime string
+		want string
+	}{
+		{"main.go", "text/plain", "go"},
+		{"README.MD", "text/plain", "markdown"},
+		{"data", "application/json", "json"},
+		{"feed", "application/rss+xml", "xml"},
+		{"notes.txt", "text/plain", ""},
+	}
+
+	for _, tt := range tests {
+		if got := file.DetectLanguage(tt.name, tt.mime); got != tt.want {
+			t.Errorf("DetectLanguage(%q, %q) = %q; want %q", tt.name, tt.mime, got, tt.want)
+		}
+	}
+}
+
+func TestDownsamplePeaks(t *testing.T) {
+	peaks := []float64{0.1, 0.5, 0.2, 0.9, 0.3, 0.4}
+
+	got := file.DownsamplePeaks(peaks, 3)
+	want := []float64{0.5, 0.9, 0.4}
+	if len(got) != len(want) {
+		t.Fatalf("DownsamplePeaks() = %v; want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DownsamplePeaks() = %v; want %v", got, want)
+		}
+	}
+
+	if got := file.DownsamplePeaks(peaks, 10); len(got) != len(peaks) {
+		t.Errorf("DownsamplePeaks(10) = %v; want %v", got, peaks)
+	}
+}
+
+func TestAssetFileID(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"thumb_" + id.String() + ".png", true},
+		{"thumb_" + id.String() + "_200.webp", true},
+		{id.String() + ".pdf", true},
+		{id.String(), true},
+		{"V1StGXR8_Z5.png", false},
+		{"thumb_.png", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := file.AssetFileID(tt.name)
+		if ok != tt.ok || (ok && got != id) {
+			t.Errorf("AssetFileID(%q) = %v, %v; want %v, %v", tt.name, got, ok, id, tt.ok)
+		}
+	}
+}
+
+func TestScrubCount(t *testing.T) {
+	var sc file.Scrub
+
+	for _, issue := range []file.ScrubIssue{
+		{Kind: file.IssueMissing, Action: file.ActionQuarantined},
+		{Kind: file.IssueSizeMismatch, Action: file.ActionRepaired},
+		{Kind: file.IssueMD5Mismatch},
+		{Kind: file.IssueOrphaned},
+		{Kind: file.IssueUnchecked},
+	} {
+		sc.Count(&issue)
+	}
+
+	want := file.Scrub{Missing: 1, Mismatched: 2, Orphaned: 1, Unchecked: 1, Repaired: 1, Quarantined: 1}
+	if sc != want {
+		t.Errorf("Count() = %+v; want %+v", sc, want)
+	}
+}
+
+func TestExportAvailable(t *testing.T) {
+	var (
+		now    = time.Now()
+		past   = now.Add(-time.Minute)
+		future = now.Add(time.Minute)
+	)
+
+	tests := []struct {
+		export file.Export
+		want   bool
+	}{
+		{file.Export{Status: file.ExportPending}, false},
+		{file.Export{Status: file.ExportFailed, ExpiresAt: &future}, false},
+		{file.Export{Status: file.ExportSucceeded, ExpiresAt: &past}, false},
+		{file.Export{Status: file.ExportSucceeded, ExpiresAt: &future}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.export.Available(now); got != tt.want {
+			t.Errorf("Available() of %+v = %v; want %v", tt.export, got, tt.want)
+		}
+	}
+}
+
+func TestExportPending(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		export file.Export
+		want   bool
+	}{
+		{file.Export{Status: file.ExportPending, CreatedAt: now.Add(-time.Minute)}, true},
+		{file.Export{Status: file.ExportPending, CreatedAt: now.Add(-file.ExportStaleAfter)}, false},
+		{file.Export{Status: file.ExportSucceeded, CreatedAt: now}, false},
+		{file.Export{Status: file.ExportFailed, CreatedAt: now}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.export.Pending(now); got != tt.want {
+			t.Errorf("Pending() of %+v = %v; want %v", tt.export, got, tt.want)
+		}
+	}
+}
+
+func TestTreeOwnerID(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		f    file.File
+		want uuid.UUID
+	}{
+		{file.File{Path: "/", Name: id.String()}, id},
+		{file.File{Path: "/" + id.String(), Name: "a"}, id},
+		{file.File{Path: "/" + id.String() + "/.trash/b", Name: "c"}, id},
+		{file.File{Path: "/", Name: "not-a-user"}, uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.f.TreeOwnerID(); got != tt.want {
+			t.Errorf("TreeOwnerID() of %q = %v; want %v", tt.f.FullPath(), got, tt.want)
+		}
+	}
+}
+
+func TestChangeDirs(t *testing.T) {
+	tests := []struct {
+		change file.Change
+		want   []string
+	}{
+		{file.Change{Path: "/u/a/b/c"}, []string{"/u/a/b", "/u/a", "/u"}},
+		{file.Change{Path: "/u"}, nil},
+		{file.Change{Path: "/u/x/c", FromPath: "/u/a/c"}, []string{"/u/x", "/u", "/u/a"}},
+		{file.Change{Path: "/u/.trash/c", FromPath: "/u/a/c"}, []string{"/u/a", "/u"}},
+		{file.Change{Path: "/v/a/c", FromPath: "/u/.trash/c"}, []string{"/v/a", "/v"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.change.Dirs(); !slices.Equal(got, tt.want) {
+			t.Errorf("Dirs() of %q from %q = %v; want %v", tt.change.Path, tt.change.FromPath, got, tt.want)
+		}
+	}
+}
@@ -10,18 +10,32 @@ import (
 )
 
 var (
-	ErrNotFound         = errors.New("no such file or directory")
-	ErrInvalidCursor    = errors.New("invalid cursor")
-	ErrNotAnImage       = errors.New("only image file is allowed")
-	ErrDirAlreadyExists = errors.New("directory already exists")
+	ErrNotFound          = errors.New("no such file or directory")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrNotAnImage        = errors.New("only image file is allowed")
+	ErrDirAlreadyExists  = errors.New("directory already exists")
+	ErrTagAlreadyExists  = errors.New("tag already exists")
+	ErrQuarantined       = errors.New("file is quarantined")
+	ErrTooManyMetadata   = errors.New("too many metadata keys")
+	ErrExportInProgress  = errors.New("export in progress")
+	ErrExportExpired     = errors.New("export expired")
+	ErrExportInterrupted = errors.New("export interrupted")
+
+	ErrWebhookURLNotAllowed = errors.New("webhook url is not allowed")
+
+	ErrStorageCapacityExceeded = errors.New("storage capacity exceeded")
 )
 
 type Service interface {
 	GetMetadata(ctx context.Context, id string) (*Entry, error)
 	DownloadFile(ctx context.Context, id string) (io.ReadCloser, string, error)
+	// DownloadRange reads length bytes from offset, or up to the end when
+	// length is negative.
+	DownloadRange(ctx context.Context, id string, offset int64, length int64) (io.ReadCloser, string, error)
 	CreateFile(ctx context.Context, content io.Reader, id string, contentType string) (int64, error)
 	AppendFile(ctx context.Context, content io.Reader, id string) (int64, error)
 	Delete(ctx context.Context, id string) error
+	ListEntries(ctx context.Context, dirpath string, lastFileName string, limit int) ([]Entry, error)
 	DirStatus(ctx context.Context) (map[string]interface{}, error)
 	VolStatus(ctx context.Context) (map[string]interface{}, error)
 }
@@ -0,0 +1,27 @@
+package file
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const SortByCapturedAt = "captured_at"
+
+// Media holds the technical metadata extracted from images, videos and audio
+// files by the thumbnail worker. Fields that could not be extracted are left empty.
+type Media struct {
+	FileID      uuid.UUID  `json:"file_id"`
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	Duration    float64    `json:"duration"` // in seconds
+	VideoCodec  string     `json:"video_codec"`
+	AudioCodec  string     `json:"audio_codec"`
+	CapturedAt  *time.Time `json:"captured_at"`
+	CameraMake  string     `json:"camera_make"`
+	CameraModel string     `json:"camera_model"`
+	Latitude    *float64   `json:"latitude"`
+	Longitude   *float64   `json:"longitude"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+} // @name file.Media
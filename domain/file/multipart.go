@@ -0,0 +1,48 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MultipartDir holds the parts of the multipart uploads of the S3 gateway
+// until the uploads are completed or aborted.
+const MultipartDir = "/multipart"
+
+// MultipartUpload is an object of the S3 gateway being uploaded in parts, Key
+// is relative to the directory of the bucket. The size of the parts is
+// reserved in the storage of the user until the upload is completed or
+// aborted.
+type MultipartUpload struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	BucketID  uuid.UUID `json:"bucket_id"`
+	Key       string    `json:"key"`
+	Reserved  uint64    `json:"reserved"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewMultipartUpload(userID uuid.UUID, bucketID uuid.UUID, key string) *MultipartUpload {
+	return &MultipartUpload{
+		ID:       uuid.New(),
+		UserID:   userID,
+		BucketID: bucketID,
+		Key:      key,
+	}
+}
+
+// StoragePath returns where the part number of the upload is stored.
+func (u *MultipartUpload) StoragePath(number int) string {
+	return fmt.Sprintf("%s/%s.%d", MultipartDir, u.ID, number)
+}
+
+type UploadPart struct {
+	UploadID  uuid.UUID `json:"upload_id"`
+	Number    int       `json:"number"`
+	Size      uint64    `json:"size"`
+	MD5       []byte    `json:"md5"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
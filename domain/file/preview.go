@@ -0,0 +1,127 @@
+package file
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PreviewKindText     = "text"
+	PreviewKindWaveform = "waveform"
+)
+
+// Preview is the derived content the web app shows instead of downloading the
+// whole file: the beginning of text files or the waveform of audio files.
+type Preview struct {
+	FileID    uuid.UUID `json:"file_id"`
+	Kind      string    `json:"kind"`
+	Content   string    `json:"content,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Lines     int       `json:"lines,omitempty"`
+	Truncated bool      `json:"truncated"`
+	Peaks     []float64 `json:"peaks,omitempty"` // between 0 and 1
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+} // @name file.Preview
+
+var languages = map[string]string{
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".csv":   "csv",
+	".dart":  "dart",
+	".go":    "go",
+	".html":  "html",
+	".htm":   "html",
+	".java":  "java",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".jsx":   "javascript",
+	".json":  "json",
+	".kt":    "kotlin",
+	".lua":   "lua",
+	".md":    "markdown",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".scss":  "scss",
+	".sh":    "shell",
+	".sql":   "sql",
+	".swift": "swift",
+	".toml":  "toml",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".xml":   "xml",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+}
+
+// DetectLanguage guesses the syntax highlighting language from the file name,
+// falling back to the mime type. Plain text has no language.
+func DetectLanguage(name string, mimeType string) string {
+	if lang, ok := languages[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+
+	switch {
+	case mimeType == "application/json":
+		return "json"
+	case mimeType == "text/html":
+		return "html"
+	case mimeType == "text/css":
+		return "css"
+	case mimeType == "text/markdown":
+		return "markdown"
+	case strings.HasSuffix(mimeType, "xml"):
+		return "xml"
+	}
+
+	return ""
+}
+
+// TruncateUTF8 returns at most n bytes of b without cutting a multi-byte
+// character in half, and whether anything was cut.
+func TruncateUTF8(b []byte, n int) ([]byte, bool) {
+	if len(b) <= n {
+		return b, false
+	}
+
+	b = b[:n]
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		r, size := utf8.DecodeLastRune(b)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+
+		b = b[:len(b)-1]
+	}
+
+	return b, true
+}
+
+// DownsamplePeaks reduces peaks to at most n values, keeping the loudest
+// value of every group so short spikes stay visible.
+func DownsamplePeaks(peaks []float64, n int) []float64 {
+	if n <= 0 || len(peaks) <= n {
+		return peaks
+	}
+
+	out := make([]float64, n)
+	for i := range out {
+		from, to := i*len(peaks)/n, (i+1)*len(peaks)/n
+		for _, p := range peaks[from:to] {
+			out[i] = max(out[i], p)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScrubRunning   = "running"
+	ScrubSucceeded = "succeeded"
+	ScrubFailed    = "failed"
+)
+
+const (
+	// IssueMissing is a row whose content is not in the storage.
+	IssueMissing = "missing"
+	// IssueSizeMismatch is a row whose size differs from the stored content.
+	IssueSizeMismatch = "size_mismatch"
+	// IssueMD5Mismatch is a row whose MD5 differs from the stored content.
+	IssueMD5Mismatch = "md5_mismatch"
+	// IssueOrphaned is a stored content without any row.
+	IssueOrphaned = "orphaned"
+	// IssueUnchecked is a row whose content could not be read from the
+	// storage, the error is kept as the actual value.
+	IssueUnchecked = "unchecked"
+)
+
+const (
+	ActionRepaired    = "repaired"
+	ActionQuarantined = "quarantined"
+)
+
+// ScrubOptions tells what a scrub does with the rows that disagree with the
+// storage. Without any option the scrub only reports them.
+type ScrubOptions struct {
+	// Repair copies the size and MD5 of the storage to the mismatched rows.
+	Repair bool `json:"repair"`
+	// Quarantine blocks the download of the missing rows, and of the
+	// mismatched ones when they are not repaired.
+	Quarantine bool `json:"quarantine"`
+}
+
+// Scrub tracks one run of the integrity check between the files table and
+// the storage, the counters are updated as the files are checked.
+type Scrub struct {
+	ID          uuid.UUID    `json:"id"`
+	Options     ScrubOptions `json:"options"`
+	Status      string       `json:"status"`
+	Checked     int          `json:"checked"`
+	Missing     int          `json:"missing"`
+	Mismatched  int          `json:"mismatched"`
+	Orphaned    int          `json:"orphaned"`
+	Unchecked   int          `json:"unchecked"`
+	Repaired    int          `json:"repaired"`
+	Quarantined int          `json:"quarantined"`
+	Error       string       `json:"error"`
+	CreatedBy   *uuid.UUID   `json:"created_by"`
+	FinishedAt  *time.Time   `json:"finished_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+} // @name file.Scrub
+
+func NewScrub(options ScrubOptions, createdBy *uuid.UUID) *Scrub {
+	return &Scrub{
+		ID:        uuid.New(),
+		Options:   options,
+		Status:    ScrubRunning,
+		CreatedBy: createdBy,
+	}
+}
+
+// Count adds an issue to the counters of the scrub.
+func (s *Scrub) Count(issue *ScrubIssue) {
+	switch issue.Kind {
+	case IssueMissing:
+		s.Missing++
+	case IssueSizeMismatch, IssueMD5Mismatch:
+		s.Mismatched++
+	case IssueOrphaned:
+		s.Orphaned++
+	case IssueUnchecked:
+		s.Unchecked++
+	}
+
+	switch issue.Action {
+	case ActionRepaired:
+		s.Repaired++
+	case ActionQuarantined:
+		s.Quarantined++
+	}
+}
+
+// Finish marks the scrub as done, err is the reason it stopped early.
+func (s *Scrub) Finish(err error) {
+	now := time.Now()
+
+	s.Status = ScrubSucceeded
+	if err != nil {
+		s.Status = ScrubFailed
+		s.Error = err.Error()
+	}
+
+	s.FinishedAt = &now
+}
+
+// ScrubIssue is one disagreement found by a scrub. FileID is nil for the
+// orphaned contents, Expected and Actual hold the values of the row and of
+// the storage.
+type ScrubIssue struct {
+	ID        int64      `json:"id"`
+	ScrubID   uuid.UUID  `json:"scrub_id"`
+	FileID    *uuid.UUID `json:"file_id"`
+	Path      string     `json:"path"`
+	Kind      string     `json:"kind"`
+	Expected  string     `json:"expected"`
+	Actual    string     `json:"actual"`
+	Action    string     `json:"action"`
+	CreatedAt time.Time  `json:"created_at"`
+} // @name file.ScrubIssue
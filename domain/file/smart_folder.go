@@ -0,0 +1,42 @@
+package file
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmartFolder is a saved search. It is shown inside its parent directory and
+// its entries are re-evaluated through Store.Search every time it is opened.
+type SmartFolder struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ParentID  uuid.UUID  `json:"parent_id"`
+	Name      string     `json:"name"`
+	Query     string     `json:"query"`
+	Type      string     `json:"type"`
+	After     *time.Time `json:"after"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+} // @name file.SmartFolder
+
+func NewSmartFolder(userID, parentID uuid.UUID, name string) *SmartFolder {
+	return &SmartFolder{
+		ID:       uuid.New(),
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     name,
+	}
+}
+
+func (f *SmartFolder) WithSearch(query string, filter Filter) *SmartFolder {
+	f.Query = query
+	f.Type = filter.Type
+	f.After = filter.After
+
+	return f
+}
+
+func (f *SmartFolder) Filter() Filter {
+	return NewFilter(f.Type, f.After)
+}
@@ -0,0 +1,33 @@
+package file
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a user defined label. Private tags are only visible to their owner,
+// shared tags are also visible to collaborators of the files the owner tagged.
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+} // @name file.Tag
+
+func NewTag(userID uuid.UUID, name string, color string, shared bool) *Tag {
+	return &Tag{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   name,
+		Color:  color,
+		Shared: shared,
+	}
+}
+
+func (t *Tag) VisibleTo(userID uuid.UUID) bool {
+	return t.UserID == userID || t.Shared
+}
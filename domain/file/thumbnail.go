@@ -0,0 +1,58 @@
+package file
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Thumbnail is one size of the thumbnail set generated by the thumbnail worker.
+// WebP is empty when the conversion failed, PNG is always present.
+type Thumbnail struct {
+	Size int    `json:"size"`
+	WebP string `json:"webp"`
+	PNG  string `json:"png"`
+} // @name file.Thumbnail
+
+// PickThumbnail returns the smallest thumbnail that is at least size pixels
+// wide, or the largest one when none is big enough.
+func PickThumbnail(thumbnails []Thumbnail, size int) *Thumbnail {
+	var best *Thumbnail
+
+	for i := range thumbnails {
+		t := &thumbnails[i]
+
+		switch {
+		case best == nil:
+			best = t
+		case best.Size < size:
+			if t.Size > best.Size {
+				best = t
+			}
+		case t.Size >= size && t.Size < best.Size:
+			best = t
+		}
+	}
+
+	return best
+}
+
+// AssetFileID returns the file an asset generated by the thumbnail worker
+// belongs to: thumb_<id>.png, thumb_<id>_<size>.webp, <id>.pdf or the <id>
+// stream directory. Uploaded images have random names and are not matched.
+func AssetFileID(name string) (uuid.UUID, bool) {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimPrefix(name, "thumb_")
+
+	if i := strings.LastIndexByte(name, '_'); i >= 0 {
+		name = name[:i]
+	}
+
+	id, err := uuid.Parse(name)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,216 @@
+package file
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SeaCloudHub/backend/pkg/app"
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+const (
+	WebhookCreate = "create"
+	WebhookUpdate = "update"
+	WebhookDelete = "delete"
+	WebhookShare  = "share"
+	WebhookMove   = "move"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	// WebhookEventsQueue carries the changes to match against the webhooks.
+	WebhookEventsQueue = "webhook-events"
+	// WebhookDeliveriesQueue carries the IDs of the deliveries to send.
+	WebhookDeliveriesQueue = "webhook-deliveries"
+)
+
+// WebhookEventType returns the type of the webhook events of a change, they
+// are coarser than the changes. A file moved to the trash is deleted as far as
+// the webhooks are concerned, the deletions from the trash are not sent.
+func WebhookEventType(changeType string) string {
+	switch changeType {
+	case ChangeCreate, ChangeRestore:
+		return WebhookCreate
+	case ChangeUpdate:
+		return WebhookUpdate
+	case ChangeMove, ChangeRename:
+		return WebhookMove
+	case ChangeTrash:
+		return WebhookDelete
+	case ChangePermission:
+		return WebhookShare
+	default:
+		return ""
+	}
+}
+
+// Webhook sends the events of the files of the subtree of a directory to URL.
+// The secret signs the deliveries so it is kept as is, it is only returned
+// when the webhook is created.
+type Webhook struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	DirectoryID uuid.UUID `json:"directory_id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+} // @name file.Webhook
+
+func NewWebhook(userID uuid.UUID, directoryID uuid.UUID, url string, events []string) *Webhook {
+	return &Webhook{
+		ID:          uuid.New(),
+		UserID:      userID,
+		DirectoryID: directoryID,
+		URL:         url,
+		Events:      events,
+		Secret:      gonanoid.Must(40),
+	}
+}
+
+// Subscribes reports whether the webhook is sent the events of the type.
+func (w *Webhook) Subscribes(eventType string) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// Sign returns the HMAC-SHA256 of payload with the secret of the webhook, in
+// hex with a sha256= prefix.
+func (w *Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Response hides the secret.
+func (w *Webhook) Response() *Webhook {
+	w.Secret = ""
+
+	return w
+}
+
+// WebhookEvent is the payload of a delivery.
+type WebhookEvent struct {
+	Event     string    `json:"event"`
+	Change    string    `json:"change"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+	FileID    uuid.UUID `json:"file_id"`
+	Path      string    `json:"path"`
+	FromPath  string    `json:"from_path,omitempty"`
+	IsDir     bool      `json:"is_dir"`
+	CreatedAt time.Time `json:"created_at"`
+} // @name file.WebhookEvent
+
+func NewWebhookEvent(w *Webhook, c *Change) WebhookEvent {
+	e := WebhookEvent{
+		Event:     WebhookEventType(c.Type),
+		Change:    c.Type,
+		WebhookID: w.ID,
+		FileID:    c.FileID,
+		Path:      app.RemoveRootPath(c.Path),
+		IsDir:     c.IsDir,
+		CreatedAt: c.CreatedAt,
+	}
+
+	if c.FromPath != "" {
+		e.FromPath = app.RemoveRootPath(c.FromPath)
+	}
+
+	return e
+}
+
+// WebhookDelivery is an entry of the delivery log of a webhook. A delivery is
+// retried until it succeeds or runs out of attempts, a redelivery sends the
+// same payload again as a new delivery.
+type WebhookDelivery struct {
+	ID          uuid.UUID  `json:"id"`
+	WebhookID   uuid.UUID  `json:"webhook_id"`
+	Event       string     `json:"event"`
+	Payload     string     `json:"payload"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	StatusCode  int        `json:"status_code"` // of the last attempt, 0 when there was no response
+	Error       string     `json:"error"`
+	Redelivery  bool       `json:"redelivery"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+} // @name file.WebhookDelivery
+
+func NewWebhookDelivery(webhookID uuid.UUID, event string, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhookID,
+		Event:     event,
+		Payload:   payload,
+		Status:    DeliveryPending,
+	}
+}
+
+// Redeliver returns a new delivery of the same payload.
+func (d *WebhookDelivery) Redeliver() *WebhookDelivery {
+	redelivery := NewWebhookDelivery(d.WebhookID, d.Event, d.Payload)
+	redelivery.Redelivery = true
+
+	return redelivery
+}
+
+// Attempt records the outcome of an attempt, the delivery fails once the last
+// attempt fails.
+func (d *WebhookDelivery) Attempt(statusCode int, err error, last bool) {
+	d.Attempts++
+	d.StatusCode = statusCode
+	d.Error = ""
+
+	switch {
+	case err == nil:
+		now := time.Now()
+
+		d.Status = DeliverySucceeded
+		d.DeliveredAt = &now
+	case last:
+		d.Status = DeliveryFailed
+		d.Error = err.Error()
+	default:
+		d.Error = err.Error()
+	}
+}
+
+// Dirs returns the full paths of the directories holding the file before and
+// after the change, the webhooks of these directories receive its events. The
+// trash is left out, it is not part of the subtree of any directory.
+func (c *Change) Dirs() []string {
+	var dirs []string
+
+	for _, p := range []string{c.Path, c.FromPath} {
+		if p == "" || inTrash(p) {
+			continue
+		}
+
+		for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	return dirs
+}
+
+// inTrash reports whether fullPath is in the trash of a user, /<user ID>/.trash.
+func inTrash(fullPath string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(fullPath, "/"), "/", 3)
+
+	return len(parts) > 1 && parts[1] == ".trash"
+}
@@ -0,0 +1,44 @@
+package identity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+const (
+	accessKeyIDAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	secretKeyAlphabet   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+// AccessKey authenticates the requests of a user to the S3 gateway. The
+// secret signs the requests so it can't be hashed, it is encrypted with the
+// master key when the encryption is enabled and only returned when the key is
+// created.
+type AccessKey struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	AccessKeyID string     `json:"access_key_id"`
+	SecretKey   string     `json:"secret_key,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+} // @name identity.AccessKey
+
+func NewAccessKey(userID uuid.UUID, name string) *AccessKey {
+	return &AccessKey{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        name,
+		AccessKeyID: "SC" + gonanoid.MustGenerate(accessKeyIDAlphabet, 18),
+		SecretKey:   gonanoid.MustGenerate(secretKeyAlphabet, 40),
+	}
+}
+
+// Response hides the secret.
+func (k *AccessKey) Response() *AccessKey {
+	k.SecretKey = ""
+
+	return k
+}
@@ -18,6 +18,10 @@ var (
 	ErrIdentityNotFound      = errors.New("identity not found")
 	ErrIdentityWasDisabled   = errors.New("identity was disabled")
 	ErrIdentityAlreadyExists = errors.New("identity already exists")
+	ErrAccessKeyNotFound     = errors.New("access key not found")
+	ErrSSHKeyNotFound        = errors.New("ssh key not found")
+	ErrSSHKeyAlreadyExists   = errors.New("ssh key already exists")
+	ErrInvalidSSHKey         = errors.New("invalid ssh key")
 )
 
 type Service interface {
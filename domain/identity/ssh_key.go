@@ -0,0 +1,43 @@
+package identity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKey authenticates a user to the SFTP server. PublicKey is in the
+// authorized_keys format, the keys are looked up by their fingerprint.
+type SSHKey struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	PublicKey   string     `json:"public_key"`
+	Fingerprint string     `json:"fingerprint"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+} // @name identity.SSHKey
+
+// NewSSHKey parses publicKey, the comment of the key names it when name is
+// empty.
+func NewSSHKey(userID uuid.UUID, name string, publicKey string) (*SSHKey, error) {
+	key, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSSHKey, err)
+	}
+
+	if name == "" {
+		name = comment
+	}
+
+	return &SSHKey{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        name,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		Fingerprint: ssh.FingerprintSHA256(key),
+	}, nil
+}
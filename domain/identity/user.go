@@ -22,11 +22,22 @@ type Store interface {
 	List(ctx context.Context, pagination *pagination.Pager, filter Filter) ([]User, error)
 	ListByEmails(ctx context.Context, emails []string) ([]User, error)
 	ListByIDs(ctx context.Context, userIDs []string) ([]User, error)
+	ListAvatarsByNames(ctx context.Context, names []string) ([]string, error)
 	FuzzySearch(ctx context.Context, keyword string) ([]User, error)
 	UpdateStorageCapacity(ctx context.Context, userID uuid.UUID, storageCapacity uint64) error
 	ToggleActive(ctx context.Context, userID uuid.UUID) error
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, userID uuid.UUID) error
+	CreateAccessKey(ctx context.Context, key *AccessKey) error
+	GetAccessKey(ctx context.Context, accessKeyID string) (*AccessKey, error)
+	ListAccessKeys(ctx context.Context, userID uuid.UUID) ([]AccessKey, error)
+	DeleteAccessKey(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	UpdateAccessKeyLastUsedAt(ctx context.Context, id uuid.UUID) error
+	CreateSSHKey(ctx context.Context, key *SSHKey) error
+	GetSSHKeyByFingerprint(ctx context.Context, fingerprint string) (*SSHKey, error)
+	ListSSHKeys(ctx context.Context, userID uuid.UUID) ([]SSHKey, error)
+	DeleteSSHKey(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	UpdateSSHKeyLastUsedAt(ctx context.Context, id uuid.UUID) error
 }
 
 type User struct {
@@ -3,6 +3,7 @@ package pubsub
 import "context"
 
 type Message struct {
+	ID      string // set for the messages of a stream
 	Channel string
 	Payload string
 }
@@ -15,4 +16,12 @@ type PubSub interface {
 type Service interface {
 	Publish(ctx context.Context, channel string, message interface{}) error
 	Subscribe(ctx context.Context, channel string) PubSub
+	Enqueue(ctx context.Context, queue string, payload string) error
+	Consume(ctx context.Context, queue string, opts QueueOptions) (Queue, error)
+	// Append adds payload to a stream capped to about maxLen messages and
+	// returns its ID, the IDs of a stream only grow.
+	Append(ctx context.Context, stream string, payload string, maxLen int64) (string, error)
+	// Range lists at most count messages of a stream after the ID, oldest
+	// first.
+	Range(ctx context.Context, stream string, afterID string, count int64) ([]Message, error)
 }
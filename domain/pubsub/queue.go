@@ -0,0 +1,50 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a message read from a durable queue. It stays pending until it is
+// acknowledged, retried or moved to the dead-letter queue.
+type Job struct {
+	ID       string
+	Queue    string
+	Payload  string
+	Attempts int
+}
+
+// QueueOptions configures a consumer of a durable queue.
+type QueueOptions struct {
+	// Group shares the jobs between every consumer with the same group name.
+	Group    string
+	Consumer string
+	// MaxAttempts is the number of failed attempts after which a job is
+	// moved to the dead-letter queue.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on every attempt.
+	Backoff time.Duration
+	// VisibilityTimeout is how long a job can stay unacknowledged before it
+	// is handed to another consumer, e.g. because its consumer crashed.
+	VisibilityTimeout time.Duration
+}
+
+// RetryAfter returns the delay before the given attempt is retried.
+func (o QueueOptions) RetryAfter(attempts int) time.Duration {
+	d := o.Backoff
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+
+	return d
+}
+
+type Queue interface {
+	// Receive blocks until a job is available or the context is done.
+	Receive(ctx context.Context) (Job, error)
+	Ack(ctx context.Context, job Job) error
+	// Retry schedules the job again after a backoff, or dead-letters it once
+	// it has failed MaxAttempts times.
+	Retry(ctx context.Context, job Job, cause error) error
+	DeadLetter(ctx context.Context, job Job, cause error) error
+}
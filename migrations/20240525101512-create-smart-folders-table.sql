@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "smart_folders"
+(
+    "id"            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "user_id"       UUID NOT NULL,
+    "parent_id"     UUID NOT NULL, -- directory the folder is shown in, also the search scope
+    "name"          VARCHAR(255) NOT NULL,
+    "query"         TEXT NOT NULL DEFAULT '',
+    "type"          VARCHAR(255) NOT NULL DEFAULT '',
+    "after"         TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX smart_folders_user_id_parent_id_idx ON smart_folders (user_id, parent_id);
+
+-- +migrate Down
+DROP TABLE "smart_folders";
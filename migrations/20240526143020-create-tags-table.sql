@@ -0,0 +1,29 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "tags"
+(
+    "id"            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "user_id"       UUID NOT NULL,
+    "name"          VARCHAR(255) NOT NULL,
+    "color"         VARCHAR(255) NOT NULL DEFAULT '',
+    "shared"        BOOLEAN NOT NULL DEFAULT FALSE, -- shared tags are visible to collaborators
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE ("user_id", "name")
+);
+
+CREATE TABLE IF NOT EXISTS "file_tags"
+(
+    "file_id"       UUID NOT NULL,
+    "tag_id"        UUID NOT NULL REFERENCES "tags" ("id") ON DELETE CASCADE,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY ("file_id", "tag_id")
+);
+
+CREATE INDEX file_tags_tag_id_idx ON file_tags (tag_id);
+
+-- +migrate Down
+DROP TABLE "file_tags";
+DROP TABLE "tags";
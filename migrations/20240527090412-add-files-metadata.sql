@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE files ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX files_metadata_idx ON files USING GIN (metadata jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX files_metadata_idx;
+ALTER TABLE files DROP COLUMN metadata;
@@ -0,0 +1,20 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "comments"
+(
+    "id"            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "file_id"       UUID NOT NULL,
+    "user_id"       UUID NOT NULL,
+    "parent_id"     UUID NULL REFERENCES "comments" ("id") ON DELETE CASCADE, -- NULL for the first comment of a thread
+    "content"       TEXT NOT NULL,
+    "resolved_at"   TIMESTAMPTZ NULL,
+    "resolved_by"   UUID NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX comments_file_id_created_at_idx ON comments (file_id, created_at);
+CREATE INDEX comments_parent_id_idx ON comments (parent_id);
+
+-- +migrate Down
+DROP TABLE "comments";
@@ -0,0 +1,23 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "file_media"
+(
+    "file_id"       UUID PRIMARY KEY,
+    "width"         INTEGER NOT NULL DEFAULT 0,
+    "height"        INTEGER NOT NULL DEFAULT 0,
+    "duration"      DOUBLE PRECISION NOT NULL DEFAULT 0, -- seconds
+    "video_codec"   VARCHAR(255) NOT NULL DEFAULT '',
+    "audio_codec"   VARCHAR(255) NOT NULL DEFAULT '',
+    "captured_at"   TIMESTAMPTZ NULL,
+    "camera_make"   VARCHAR(255) NOT NULL DEFAULT '',
+    "camera_model"  VARCHAR(255) NOT NULL DEFAULT '',
+    "latitude"      DOUBLE PRECISION NULL,
+    "longitude"     DOUBLE PRECISION NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX file_media_captured_at_idx ON file_media (captured_at);
+
+-- +migrate Down
+DROP TABLE "file_media";
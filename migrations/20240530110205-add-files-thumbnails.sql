@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE files ADD COLUMN thumbnails JSONB NOT NULL DEFAULT '[]';
+
+-- +migrate Down
+ALTER TABLE files DROP COLUMN thumbnails;
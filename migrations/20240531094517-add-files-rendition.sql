@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE files ADD COLUMN rendition VARCHAR;
+
+-- +migrate Down
+ALTER TABLE files DROP COLUMN rendition;
@@ -0,0 +1,21 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "thumbnail_backfills"
+(
+    "id"            UUID PRIMARY KEY,
+    "filter"        JSONB NOT NULL DEFAULT '{}',
+    "total"         INTEGER NOT NULL DEFAULT 0,
+    "succeeded"     INTEGER NOT NULL DEFAULT 0,
+    "failed"        INTEGER NOT NULL DEFAULT 0,
+    "skipped"       INTEGER NOT NULL DEFAULT 0,
+    "created_by"    UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    "enqueued_at"   TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX files_missing_thumbnail_idx ON files (created_at) WHERE thumbnail IS NULL AND is_dir = FALSE;
+
+-- +migrate Down
+DROP INDEX files_missing_thumbnail_idx;
+DROP TABLE "thumbnail_backfills";
@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "file_previews"
+(
+    "file_id"       UUID PRIMARY KEY,
+    "kind"          VARCHAR(20) NOT NULL,
+    "content"       TEXT NOT NULL DEFAULT '',
+    "language"      VARCHAR(50) NOT NULL DEFAULT '',
+    "lines"         INTEGER NOT NULL DEFAULT 0,
+    "truncated"     BOOLEAN NOT NULL DEFAULT FALSE,
+    "peaks"         JSONB NOT NULL DEFAULT '[]',
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE "file_previews";
@@ -0,0 +1,6 @@
+
+-- +migrate Up
+CREATE INDEX files_thumbnail_idx ON files (thumbnail) WHERE thumbnail IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX files_thumbnail_idx;
@@ -0,0 +1,41 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "scrubs"
+(
+    "id"            UUID PRIMARY KEY,
+    "options"       JSONB NOT NULL DEFAULT '{}',
+    "status"        VARCHAR(32) NOT NULL, -- running, succeeded, failed
+    "checked"       INTEGER NOT NULL DEFAULT 0,
+    "missing"       INTEGER NOT NULL DEFAULT 0,
+    "mismatched"    INTEGER NOT NULL DEFAULT 0,
+    "orphaned"      INTEGER NOT NULL DEFAULT 0,
+    "repaired"      INTEGER NOT NULL DEFAULT 0,
+    "quarantined"   INTEGER NOT NULL DEFAULT 0,
+    "error"         TEXT NOT NULL DEFAULT '',
+    "created_by"    UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    "finished_at"   TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS "scrub_issues"
+(
+    "id"            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "scrub_id"      UUID NOT NULL REFERENCES scrubs (id) ON DELETE CASCADE,
+    "file_id"       UUID NULL, -- NULL for orphaned contents
+    "path"          TEXT NOT NULL,
+    "kind"          VARCHAR(32) NOT NULL, -- missing, size_mismatch, md5_mismatch, orphaned
+    "expected"      TEXT NOT NULL DEFAULT '',
+    "actual"        TEXT NOT NULL DEFAULT '',
+    "action"        VARCHAR(32) NOT NULL DEFAULT '', -- repaired, quarantined
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX scrub_issues_scrub_id_idx ON scrub_issues (scrub_id, id);
+
+ALTER TABLE "files" ADD COLUMN "quarantined_at" TIMESTAMPTZ NULL;
+
+-- +migrate Down
+ALTER TABLE "files" DROP COLUMN "quarantined_at";
+DROP TABLE "scrub_issues";
+DROP TABLE "scrubs";
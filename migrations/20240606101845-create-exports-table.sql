@@ -0,0 +1,22 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "exports"
+(
+    "id"            UUID PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "status"        VARCHAR(32) NOT NULL, -- pending, succeeded, failed
+    "files"         INTEGER NOT NULL DEFAULT 0,
+    "size"          BIGINT NOT NULL DEFAULT 0,
+    "error"         TEXT NOT NULL DEFAULT '',
+    "token"         VARCHAR(64) NOT NULL,
+    "created_by"    UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    "expires_at"    TIMESTAMPTZ NULL,
+    "finished_at"   TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX exports_user_id_idx ON exports (user_id, created_at);
+
+-- +migrate Down
+DROP TABLE "exports";
@@ -0,0 +1,39 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "access_keys"
+(
+    "id"            UUID PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "name"          VARCHAR(255) NOT NULL DEFAULT '',
+    "access_key_id" VARCHAR(32) NOT NULL UNIQUE,
+    "secret_key"    VARCHAR(64) NOT NULL,
+    "last_used_at"  TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX access_keys_user_id_idx ON access_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS "multipart_uploads"
+(
+    "id"            UUID PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "bucket_id"     UUID NOT NULL,
+    "key"           TEXT NOT NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS "upload_parts"
+(
+    "upload_id"     UUID NOT NULL REFERENCES multipart_uploads (id) ON DELETE CASCADE,
+    "number"        INTEGER NOT NULL,
+    "size"          BIGINT NOT NULL,
+    "md5"           VARCHAR(32) NOT NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("upload_id", "number")
+);
+
+-- +migrate Down
+DROP TABLE "upload_parts";
+DROP TABLE "multipart_uploads";
+DROP TABLE "access_keys";
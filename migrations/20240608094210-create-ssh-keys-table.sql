@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "ssh_keys"
+(
+    "id"            UUID PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "name"          VARCHAR(255) NOT NULL DEFAULT '',
+    "public_key"    TEXT NOT NULL,
+    "fingerprint"   VARCHAR(64) NOT NULL UNIQUE,
+    "last_used_at"  TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX ssh_keys_user_id_idx ON ssh_keys (user_id);
+
+-- +migrate Down
+DROP TABLE "ssh_keys";
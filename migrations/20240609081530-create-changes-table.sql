@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "changes"
+(
+    "id"            BIGSERIAL PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "file_id"       UUID NOT NULL, -- kept once the file is deleted
+    "type"          VARCHAR(16) NOT NULL, -- create, update, move, rename, trash, restore, delete, permission
+    "path"          TEXT NOT NULL,
+    "is_dir"        BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX changes_user_id_idx ON changes (user_id, id);
+
+-- +migrate Down
+DROP TABLE "changes";
@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE "changes" ADD COLUMN IF NOT EXISTS "from_path" TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "changes" DROP COLUMN IF EXISTS "from_path";
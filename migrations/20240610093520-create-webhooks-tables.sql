@@ -0,0 +1,37 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "webhooks"
+(
+    "id"            UUID PRIMARY KEY,
+    "user_id"       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "directory_id"  UUID NOT NULL, -- root of the subtree the events are sent for
+    "url"           TEXT NOT NULL,
+    "events"        JSONB NOT NULL DEFAULT '[]', -- create, update, delete, share, move
+    "secret"        VARCHAR(64) NOT NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id, created_at);
+CREATE INDEX webhooks_directory_id_idx ON webhooks (directory_id);
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries"
+(
+    "id"            UUID PRIMARY KEY,
+    "webhook_id"    UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    "event"         VARCHAR(16) NOT NULL,
+    "payload"       TEXT NOT NULL,
+    "status"        VARCHAR(32) NOT NULL, -- pending, succeeded, failed
+    "attempts"      INTEGER NOT NULL DEFAULT 0,
+    "status_code"   INTEGER NOT NULL DEFAULT 0,
+    "error"         TEXT NOT NULL DEFAULT '',
+    "redelivery"    BOOLEAN NOT NULL DEFAULT FALSE,
+    "delivered_at"  TIMESTAMPTZ NULL,
+    "created_at"    TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+
+-- +migrate Down
+DROP TABLE "webhook_deliveries";
+DROP TABLE "webhooks";
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE "multipart_uploads" ADD COLUMN IF NOT EXISTS "reserved" BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX multipart_uploads_created_at_idx ON multipart_uploads (created_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS multipart_uploads_created_at_idx;
+ALTER TABLE "multipart_uploads" DROP COLUMN IF EXISTS "reserved";
@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE "scrubs" ADD COLUMN IF NOT EXISTS "unchecked" INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "scrubs" DROP COLUMN IF EXISTS "unchecked";
@@ -0,0 +1,12 @@
+
+-- +migrate Up
+-- keep the latest pending export of every user, the others were interrupted
+UPDATE "exports" SET "status" = 'failed', "error" = 'export interrupted', "finished_at" = NOW(), "updated_at" = NOW()
+WHERE "status" = 'pending' AND "id" NOT IN (
+    SELECT DISTINCT ON ("user_id") "id" FROM "exports" WHERE "status" = 'pending' ORDER BY "user_id", "created_at" DESC
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exports_user_id_pending_idx ON exports (user_id) WHERE status = 'pending';
+
+-- +migrate Down
+DROP INDEX IF EXISTS exports_user_id_pending_idx;
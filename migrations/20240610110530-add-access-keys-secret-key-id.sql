@@ -0,0 +1,7 @@
+
+-- +migrate Up
+ALTER TABLE "access_keys" ALTER COLUMN "secret_key" TYPE TEXT;
+ALTER TABLE "access_keys" ADD COLUMN IF NOT EXISTS "secret_key_id" VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "access_keys" DROP COLUMN IF EXISTS "secret_key_id";
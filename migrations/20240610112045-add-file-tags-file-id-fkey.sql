@@ -0,0 +1,8 @@
+
+-- +migrate Up
+DELETE FROM "file_tags" WHERE NOT EXISTS (SELECT 1 FROM "files" WHERE "files"."id" = "file_tags"."file_id");
+ALTER TABLE "file_tags" DROP CONSTRAINT IF EXISTS "file_tags_file_id_fkey";
+ALTER TABLE "file_tags" ADD CONSTRAINT "file_tags_file_id_fkey" FOREIGN KEY ("file_id") REFERENCES "files" ("id") ON DELETE CASCADE;
+
+-- +migrate Down
+ALTER TABLE "file_tags" DROP CONSTRAINT IF EXISTS "file_tags_file_id_fkey";
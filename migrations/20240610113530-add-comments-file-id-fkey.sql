@@ -0,0 +1,8 @@
+
+-- +migrate Up
+DELETE FROM "comments" WHERE NOT EXISTS (SELECT 1 FROM "files" WHERE "files"."id" = "comments"."file_id");
+ALTER TABLE "comments" DROP CONSTRAINT IF EXISTS "comments_file_id_fkey";
+ALTER TABLE "comments" ADD CONSTRAINT "comments_file_id_fkey" FOREIGN KEY ("file_id") REFERENCES "files" ("id") ON DELETE CASCADE;
+
+-- +migrate Down
+ALTER TABLE "comments" DROP CONSTRAINT IF EXISTS "comments_file_id_fkey";
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+DELETE FROM "file_media" WHERE NOT EXISTS (SELECT 1 FROM "files" WHERE "files"."id" = "file_media"."file_id");
+ALTER TABLE "file_media" DROP CONSTRAINT IF EXISTS "file_media_file_id_fkey";
+ALTER TABLE "file_media" ADD CONSTRAINT "file_media_file_id_fkey" FOREIGN KEY ("file_id") REFERENCES "files" ("id") ON DELETE CASCADE;
+
+-- +migrate Down
+ALTER TABLE "file_media" DROP CONSTRAINT IF EXISTS "file_media_file_id_fkey";
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+DELETE FROM "smart_folders" WHERE NOT EXISTS (SELECT 1 FROM "files" WHERE "files"."id" = "smart_folders"."parent_id");
+ALTER TABLE "smart_folders" DROP CONSTRAINT IF EXISTS "smart_folders_parent_id_fkey";
+ALTER TABLE "smart_folders" ADD CONSTRAINT "smart_folders_parent_id_fkey" FOREIGN KEY ("parent_id") REFERENCES "files" ("id") ON DELETE CASCADE;
+
+-- +migrate Down
+ALTER TABLE "smart_folders" DROP CONSTRAINT IF EXISTS "smart_folders_parent_id_fkey";
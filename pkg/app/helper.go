@@ -3,9 +3,12 @@ package app
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/labstack/echo/v4"
@@ -61,3 +64,65 @@ func DetectContentType(input io.Reader) (string, io.Reader, error) {
 
 	return mtype.String(), recycled, err
 }
+
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+// ContentRange returns the Content-Range header of the partial response.
+func (r *ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Offset, r.Offset+r.Length-1, size)
+}
+
+// ParseRange parses the Range header against the size of the content. Only a
+// single byte range is supported, nil means the whole content should be sent.
+func ParseRange(header string, size int64) (*ByteRange, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	// suffix range, the last bytes of the content
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return nil, nil
+		}
+
+		if n == 0 || size == 0 {
+			return nil, ErrRangeNotSatisfiable
+		}
+
+		n = min(n, size)
+
+		return &ByteRange{Offset: size - n, Length: n}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return nil, nil
+		}
+
+		end = min(end, size-1)
+	}
+
+	if start >= size {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	return &ByteRange{Offset: start, Length: end - start + 1}, nil
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		size   int64
+		want   *ByteRange
+		err    error
+	}{
+		{"", 100, nil, nil},
+		{"bytes=0-9", 100, &ByteRange{Offset: 0, Length: 10}, nil},
+		{"bytes=90-", 100, &ByteRange{Offset: 90, Length: 10}, nil},
+		{"bytes=90-200", 100, &ByteRange{Offset: 90, Length: 10}, nil},
+		{"bytes=-10", 100, &ByteRange{Offset: 90, Length: 10}, nil},
+		{"bytes=-200", 100, &ByteRange{Offset: 0, Length: 100}, nil},
+		{"bytes=100-", 100, nil, ErrRangeNotSatisfiable},
+		{"bytes=-0", 100, nil, ErrRangeNotSatisfiable},
+		{"bytes=0-9,20-29", 100, nil, nil},
+		{"bytes=9-0", 100, nil, nil},
+		{"items=0-9", 100, nil, nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseRange(tt.header, tt.size)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseRange(%q, %d) error = %v; want %v", tt.header, tt.size, err, tt.err)
+			continue
+		}
+
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseRange(%q, %d) = %+v; want %+v", tt.header, tt.size, got, tt.want)
+		}
+	}
+}
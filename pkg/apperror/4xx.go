@@ -16,6 +16,7 @@ const (
 	FileOnlyOperationCode       = "400013"
 	DirectoryOnlyOperationCode  = "400014"
 	NoFilesSelectedCode         = "400015"
+	TagAlreadyExistsCode        = "400016"
 	UnauthorizedCode            = "401004"
 	IdentityWasDisableCode      = "401009"
 	ForbiddenCode               = "403005"
@@ -23,6 +24,9 @@ const (
 	EntityNotFoundCode          = "404006"
 	IdentityNotFoundCode        = "404007"
 	IdentityAlreadyExistsCode   = "409001"
+	FileQuarantinedCode         = "409002"
+	ExportInProgressCode        = "409003"
+	SSHKeyAlreadyExistsCode     = "409004"
 )
 
 // 400 Bad Request
@@ -54,6 +58,10 @@ func ErrNoFilesSelected(err error) Error {
 	return NewError(err, http.StatusBadRequest, NoFilesSelectedCode, "No files selected")
 }
 
+func ErrTagAlreadyExists(err error) Error {
+	return NewError(err, http.StatusBadRequest, TagAlreadyExistsCode, "Tag already exists")
+}
+
 // 401 Unauthorized
 func ErrInvalidCredentials(err error) Error {
 	return NewError(err, http.StatusUnauthorized, InvalidCredentialsCode, "Invalid credentials")
@@ -101,3 +109,15 @@ func ErrIdentityNotFound(err error) Error {
 func ErrIdentityAlreadyExists(err error) Error {
 	return NewError(err, http.StatusConflict, IdentityAlreadyExistsCode, "Identity already exists")
 }
+
+func ErrFileQuarantined(err error) Error {
+	return NewError(err, http.StatusConflict, FileQuarantinedCode, "File is quarantined after an integrity check")
+}
+
+func ErrExportInProgress(err error) Error {
+	return NewError(err, http.StatusConflict, ExportInProgressCode, "An export is already in progress")
+}
+
+func ErrSSHKeyAlreadyExists(err error) Error {
+	return NewError(err, http.StatusConflict, SSHKeyAlreadyExistsCode, "SSH key already exists")
+}
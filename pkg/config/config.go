@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -9,6 +10,12 @@ import (
 
 var Empty = new(Config)
 
+const (
+	StorageBackendSeaweedFS = "seaweedfs"
+	StorageBackendLocal     = "local"
+	StorageBackendS3        = "s3"
+)
+
 type Config struct {
 	AppEnv       string `envconfig:"APP_ENV"`
 	Debug        bool   `envconfig:"DEBUG"`
@@ -26,8 +33,41 @@ type Config struct {
 	}
 
 	SeaweedFS struct {
-		MasterServer string `envconfig:"MASTER_SERVER"`
-		FilerServer  string `envconfig:"FILER_SERVER"`
+		MasterServer        string        `envconfig:"MASTER_SERVER"`
+		FilerServers        []string      `envconfig:"FILER_SERVER"` // comma separated
+		FilerSelection      string        `envconfig:"FILER_SELECTION" default:"round-robin"`
+		Timeout             time.Duration `envconfig:"FILER_TIMEOUT" default:"30s"`
+		MaxRetries          int           `envconfig:"FILER_MAX_RETRIES" default:"3"`
+		RetryBackoff        time.Duration `envconfig:"FILER_RETRY_BACKOFF" default:"200ms"`
+		HealthCheckInterval time.Duration `envconfig:"FILER_HEALTH_CHECK_INTERVAL" default:"10s"`
+	}
+
+	S3 struct {
+		Endpoint  string `envconfig:"S3_ENDPOINT"`
+		Region    string `envconfig:"S3_REGION"`
+		Bucket    string `envconfig:"S3_BUCKET"`
+		AccessKey string `envconfig:"S3_ACCESS_KEY"`
+		SecretKey string `envconfig:"S3_SECRET_KEY"`
+		UseSSL    bool   `envconfig:"S3_USE_SSL"`
+	}
+
+	S3Gateway struct {
+		Port int `envconfig:"S3_GATEWAY_PORT"` // disabled when zero
+	}
+
+	SFTP struct {
+		Port    int    `envconfig:"SFTP_PORT" default:"2022"`
+		HostKey string `envconfig:"SFTP_HOST_KEY" default:"./sftp_host_key"` // generated when missing
+	}
+
+	Storage struct {
+		Backend   string `envconfig:"STORAGE_BACKEND" default:"seaweedfs"`
+		LocalRoot string `envconfig:"STORAGE_LOCAL_ROOT" default:"./data"`
+	}
+
+	Encryption struct {
+		KeyID string            `envconfig:"ENCRYPTION_KEY_ID"`
+		Keys  map[string]string `envconfig:"ENCRYPTION_KEYS"`
 	}
 
 	Kratos struct {
@@ -49,6 +89,33 @@ type Config struct {
 	NotificationHub struct {
 		Endpoint string `envconfig:"NOTIFICATION_HUB_ENDPOINT"`
 	}
+
+	Thumbnail struct {
+		Sizes             []int         `envconfig:"THUMBNAIL_SIZES" default:"64,200,800"`
+		Concurrency       int           `envconfig:"THUMBNAIL_CONCURRENCY" default:"2"`
+		MaxAttempts       int           `envconfig:"THUMBNAIL_MAX_ATTEMPTS" default:"5"`
+		RetryBackoff      time.Duration `envconfig:"THUMBNAIL_RETRY_BACKOFF" default:"30s"`
+		VisibilityTimeout time.Duration `envconfig:"THUMBNAIL_VISIBILITY_TIMEOUT" default:"10m"`
+	}
+
+	Export struct {
+		TTL time.Duration `envconfig:"EXPORT_TTL" default:"168h"`
+	}
+
+	Transcode struct {
+		Heights           []int         `envconfig:"TRANSCODE_HEIGHTS" default:"360,720,1080"`
+		Concurrency       int           `envconfig:"TRANSCODE_CONCURRENCY" default:"1"`
+		VisibilityTimeout time.Duration `envconfig:"TRANSCODE_VISIBILITY_TIMEOUT" default:"2h"`
+	}
+
+	Webhook struct {
+		Concurrency       int           `envconfig:"WEBHOOK_CONCURRENCY" default:"4"`
+		MaxAttempts       int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+		RetryBackoff      time.Duration `envconfig:"WEBHOOK_RETRY_BACKOFF" default:"30s"`
+		VisibilityTimeout time.Duration `envconfig:"WEBHOOK_VISIBILITY_TIMEOUT" default:"2m"`
+		Timeout           time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`      // of a delivery
+		AllowHTTP         bool          `envconfig:"WEBHOOK_ALLOW_HTTP" default:"false"` // for development only
+	}
 }
 
 func LoadConfig() (*Config, error) {
@@ -1,22 +1,47 @@
 package seaweedfs
 
+import "time"
+
+type Selection string
+
+const (
+	// SelectionRoundRobin spreads the requests over the healthy filers.
+	SelectionRoundRobin Selection = "round-robin"
+	// SelectionFailover sends the requests to the first healthy filer, in the
+	// order they were added.
+	SelectionFailover Selection = "failover"
+)
+
 type Config struct {
 	MasterURL string
 	FilerURLs []string
-	debug     bool
+	Selection Selection
+	// Timeout bounds the metadata operations, and the wait for the response
+	// headers of the transfers whose body may take much longer.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of the idempotent operations.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled after each one.
+	RetryBackoff time.Duration
+	// HealthCheckInterval is the delay between two health checks of the
+	// filers, zero disables them.
+	HealthCheckInterval time.Duration
+	debug               bool
 }
 
 func NewConfig(masterURL string) *Config {
 	return &Config{
-		MasterURL: masterURL,
+		MasterURL:           masterURL,
+		Selection:           SelectionRoundRobin,
+		Timeout:             30 * time.Second,
+		MaxRetries:          3,
+		RetryBackoff:        200 * time.Millisecond,
+		HealthCheckInterval: 10 * time.Second,
 	}
 }
 
 func NewConfigWithFilerURL(masterURL string, filerURL string) *Config {
-	return &Config{
-		MasterURL: masterURL,
-		FilerURLs: []string{filerURL},
-	}
+	return NewConfig(masterURL).AddFilerURL(filerURL)
 }
 
 func (c *Config) Debug() *Config {
@@ -30,3 +55,28 @@ func (c *Config) AddFilerURL(filerURL string) *Config {
 
 	return c
 }
+
+func (c *Config) WithSelection(selection Selection) *Config {
+	c.Selection = selection
+
+	return c
+}
+
+func (c *Config) WithTimeout(timeout time.Duration) *Config {
+	c.Timeout = timeout
+
+	return c
+}
+
+func (c *Config) WithRetries(maxRetries int, backoff time.Duration) *Config {
+	c.MaxRetries = maxRetries
+	c.RetryBackoff = backoff
+
+	return c
+}
+
+func (c *Config) WithHealthCheckInterval(interval time.Duration) *Config {
+	c.HealthCheckInterval = interval
+
+	return c
+}
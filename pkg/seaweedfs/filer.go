@@ -6,13 +6,19 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
 type Filer struct {
-	host   *url.URL
-	client *resty.Client
+	host    *url.URL
+	client  *resty.Client
+	timeout time.Duration
+	// unhealthy is set by the failed requests and health checks, it is
+	// cleared by the next successful health check.
+	unhealthy atomic.Bool
 }
 
 func NewFiler(filerURL string) (*Filer, error) {
@@ -31,7 +37,60 @@ func (f *Filer) SetDebug(debug bool) {
 	f.client.SetDebug(debug)
 }
 
+// SetTimeout bounds the metadata operations. The transfers only wait that
+// long for the response headers, their body can take much longer.
+func (f *Filer) SetTimeout(timeout time.Duration) {
+	f.timeout = timeout
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	f.client.SetTransport(transport)
+}
+
+func (f *Filer) URL() string {
+	return f.host.String()
+}
+
+func (f *Filer) Healthy() bool {
+	return !f.unhealthy.Load()
+}
+
+func (f *Filer) SetHealthy(healthy bool) {
+	f.unhealthy.Store(!healthy)
+}
+
+func (f *Filer) CheckHealth(ctx context.Context) error {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := f.client.R().SetContext(ctx).Get("/healthz")
+	if err != nil {
+		return fmt.Errorf("check health: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return serverError(resp.StatusCode())
+	}
+
+	return nil
+}
+
+func (f *Filer) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, f.timeout)
+}
+
+func serverError(code int) error {
+	return fmt.Errorf("%w: status code %d", ErrServerError, code)
+}
+
 func (f *Filer) GetMetadata(ctx context.Context, in *GetMetadataRequest) (*Entry, error) {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
 	var result Entry
 
 	path, err := url.ParseRequestURI(in.FullPath)
@@ -49,10 +108,17 @@ func (f *Filer) GetMetadata(ctx context.Context, in *GetMetadataRequest) (*Entry
 		return nil, ErrNotFound
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return nil, serverError(resp.StatusCode())
+	}
+
 	return &result, nil
 }
 
 func (f *Filer) ListEntries(ctx context.Context, in *ListEntriesRequest) (*ListEntriesResponse, error) {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
 	var result ListEntriesResponse
 
 	path, err := url.ParseRequestURI(in.DirPath)
@@ -88,6 +154,10 @@ func (f *Filer) ListEntries(ctx context.Context, in *ListEntriesRequest) (*ListE
 		return nil, ErrNotFound
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return nil, serverError(resp.StatusCode())
+	}
+
 	return &result, nil
 }
 
@@ -97,16 +167,35 @@ func (f *Filer) DownloadFile(ctx context.Context, in *DownloadFileRequest) (io.R
 		return nil, fmt.Errorf("parse request uri: %w", err)
 	}
 
-	resp, err := f.client.R().SetContext(ctx).SetDoNotParseResponse(true).
-		Get(path.String())
+	req := f.client.R().SetContext(ctx).SetDoNotParseResponse(true)
+
+	if len(in.Range) > 0 {
+		req = req.SetHeader("Range", in.Range)
+	}
+
+	resp, err := req.Get(path.String())
 	if err != nil {
 		return nil, fmt.Errorf("download file: %w", err)
 	}
 
 	if resp.StatusCode() == http.StatusNotFound {
+		resp.RawBody().Close()
+
 		return nil, ErrNotFound
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		resp.RawBody().Close()
+
+		return nil, serverError(resp.StatusCode())
+	}
+
+	if resp.StatusCode() == http.StatusRequestedRangeNotSatisfiable {
+		resp.RawBody().Close()
+
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+
 	return resp.RawBody(), nil
 }
 
@@ -125,6 +214,10 @@ func (f *Filer) UploadFile(ctx context.Context, in *UploadFileRequest) (*UploadF
 		return nil, fmt.Errorf("upload file: %w", err)
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return nil, serverError(resp.StatusCode())
+	}
+
 	if resp.StatusCode() != http.StatusCreated {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
 	}
@@ -148,6 +241,10 @@ func (f *Filer) AppendFile(ctx context.Context, in *AppendFileRequest) (*AppendF
 		return nil, fmt.Errorf("append file: %w", err)
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return nil, serverError(resp.StatusCode())
+	}
+
 	if resp.StatusCode() != http.StatusCreated {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
 	}
@@ -156,6 +253,9 @@ func (f *Filer) AppendFile(ctx context.Context, in *AppendFileRequest) (*AppendF
 }
 
 func (f *Filer) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest) error {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
 	path, err := url.ParseRequestURI(in.DirPath)
 	if err != nil {
 		return fmt.Errorf("parse request uri: %w", err)
@@ -171,6 +271,10 @@ func (f *Filer) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest)
 		return ErrDirAlreadyExists
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return serverError(resp.StatusCode())
+	}
+
 	if resp.StatusCode() != http.StatusCreated {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode())
 	}
@@ -179,6 +283,9 @@ func (f *Filer) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest)
 }
 
 func (f *Filer) Delete(ctx context.Context, in *DeleteRequest) error {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
 	path, err := url.ParseRequestURI(in.FullPath)
 	if err != nil {
 		return fmt.Errorf("parse request uri: %w", err)
@@ -194,10 +301,17 @@ func (f *Filer) Delete(ctx context.Context, in *DeleteRequest) error {
 		return ErrNotFound
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return serverError(resp.StatusCode())
+	}
+
 	return nil
 }
 
 func (f *Filer) Move(ctx context.Context, in *MoveRequest) error {
+	ctx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
 	path, err := url.ParseRequestURI(in.DstFullPath)
 	if err != nil {
 		return fmt.Errorf("parse request uri: %w", err)
@@ -214,5 +328,9 @@ func (f *Filer) Move(ctx context.Context, in *MoveRequest) error {
 		return ErrNotFound
 	}
 
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return serverError(resp.StatusCode())
+	}
+
 	return nil
 }
@@ -16,6 +16,7 @@ type ListEntriesRequest struct {
 
 type DownloadFileRequest struct {
 	FullPath string
+	Range    string // optional, the value of the Range header
 }
 
 type UploadFileRequest struct {
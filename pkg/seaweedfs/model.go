@@ -11,6 +11,8 @@ import (
 var (
 	ErrNotFound         = errors.New("no such file or directory")
 	ErrDirAlreadyExists = errors.New("directory already exists")
+	ErrServerError      = errors.New("filer server error")
+	ErrNoFiler          = errors.New("no filer configured")
 )
 
 type FullPath string
@@ -0,0 +1,355 @@
+package seaweedfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxReplaySize is the largest content buffered so that an upload can be sent
+// again to another filer, the larger ones are sent once.
+const maxReplaySize = 32 << 20
+
+// retryPolicy tells which failed attempts of a request are sent again.
+type retryPolicy int
+
+const (
+	// retryNever sends the request once, its content cannot be replayed.
+	retryNever retryPolicy = iota
+	// retryUnsent retries the attempts that never reached a filer, the
+	// other failures may have been applied already.
+	retryUnsent
+	// retryAlways retries the idempotent requests.
+	retryAlways
+)
+
+// FilerPool sends the requests to one of several filers. The idempotent
+// operations, uploads included, are retried on another filer when a filer
+// cannot be reached or fails. The appends and moves are only retried when
+// they did not reach the filer, and the uploads too large to be buffered are
+// sent once.
+type FilerPool struct {
+	filers       []*Filer
+	selection    Selection
+	maxRetries   int
+	retryBackoff time.Duration
+	next         atomic.Uint64
+
+	// checking is set once the health checks run, they are the only way for
+	// a failed filer to be picked first again
+	checking bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewFilerPool(filers []*Filer, cfg *Config) *FilerPool {
+	return &FilerPool{
+		filers:       filers,
+		selection:    cfg.Selection,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		stop:         make(chan struct{}),
+	}
+}
+
+// StartHealthChecks checks every filer at each interval until Close.
+func (p *FilerPool) StartHealthChecks(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	p.checking = true
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.CheckHealth(context.Background())
+			}
+		}
+	}()
+}
+
+// CheckHealth updates the health of every filer.
+func (p *FilerPool) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, f := range p.filers {
+		wg.Add(1)
+		go func(f *Filer) {
+			defer wg.Done()
+
+			f.SetHealthy(f.CheckHealth(ctx) == nil)
+		}(f)
+	}
+
+	wg.Wait()
+}
+
+func (p *FilerPool) Close() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *FilerPool) Filers() []*Filer {
+	return p.filers
+}
+
+// pick returns the filer of an attempt. The healthy filers come first, the
+// unhealthy ones are only tried when every healthy one failed.
+func (p *FilerPool) pick(tried map[*Filer]bool) *Filer {
+	start := 0
+	if p.selection != SelectionFailover {
+		start = int(p.next.Add(1)-1) % len(p.filers)
+	}
+
+	var fallback *Filer
+	for i := range p.filers {
+		f := p.filers[(start+i)%len(p.filers)]
+		if tried[f] {
+			continue
+		}
+
+		if f.Healthy() {
+			return f
+		}
+
+		if fallback == nil {
+			fallback = f
+		}
+	}
+
+	if fallback != nil {
+		return fallback
+	}
+
+	// every filer failed already, start over
+	return p.filers[start]
+}
+
+// do runs op on the selected filer, the failed attempts allowed by policy are
+// retried with an exponential backoff.
+func (p *FilerPool) do(ctx context.Context, policy retryPolicy, op func(f *Filer) error) error {
+	if len(p.filers) == 0 {
+		return ErrNoFiler
+	}
+
+	var (
+		tried = make(map[*Filer]bool)
+		delay = p.retryBackoff
+		err   error
+	)
+
+	for attempt := 0; ; attempt++ {
+		f := p.pick(tried)
+
+		err = op(f)
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+
+		if p.checking {
+			f.SetHealthy(false)
+		}
+
+		tried[f] = true
+
+		if policy == retryNever || (policy == retryUnsent && !unsent(err)) || attempt >= p.maxRetries {
+			return err
+		}
+
+		// full jitter, the clients retrying together do not hit the next filer
+		// at the same time
+		wait := time.Duration(rand.Int63n(int64(delay) + 1))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w, last error: %w", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+	}
+}
+
+// retryable tells whether err comes from the filer or the network rather
+// than from the request itself.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDirAlreadyExists) {
+		return false
+	}
+
+	if errors.Is(err, ErrServerError) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// resty returns the transport errors as *url.Error, which covers the
+	// refused and reset connections as well as the timeouts
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// unsent tells whether err shows that the request never reached the filer,
+// like when the connection is refused while the filer restarts.
+func unsent(err error) bool {
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// buffer reads content so that it can be sent again, rest is the whole
+// content instead when it is larger than maxReplaySize.
+func buffer(content io.Reader) (data []byte, rest io.Reader, err error) {
+	data, err = io.ReadAll(io.LimitReader(content, maxReplaySize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(data) > maxReplaySize {
+		return nil, io.MultiReader(bytes.NewReader(data), content), nil
+	}
+
+	return data, nil, nil
+}
+
+func (p *FilerPool) GetMetadata(ctx context.Context, in *GetMetadataRequest) (*Entry, error) {
+	var result *Entry
+
+	err := p.do(ctx, retryAlways, func(f *Filer) (err error) {
+		result, err = f.GetMetadata(ctx, in)
+		return err
+	})
+
+	return result, err
+}
+
+func (p *FilerPool) ListEntries(ctx context.Context, in *ListEntriesRequest) (*ListEntriesResponse, error) {
+	var result *ListEntriesResponse
+
+	err := p.do(ctx, retryAlways, func(f *Filer) (err error) {
+		result, err = f.ListEntries(ctx, in)
+		return err
+	})
+
+	return result, err
+}
+
+func (p *FilerPool) DownloadFile(ctx context.Context, in *DownloadFileRequest) (io.ReadCloser, error) {
+	var result io.ReadCloser
+
+	err := p.do(ctx, retryAlways, func(f *Filer) (err error) {
+		result, err = f.DownloadFile(ctx, in)
+		return err
+	})
+
+	return result, err
+}
+
+// UploadFile overwrites the file, it is retried like the idempotent requests
+// when its content is buffered.
+func (p *FilerPool) UploadFile(ctx context.Context, in *UploadFileRequest) (*UploadFileResponse, error) {
+	data, rest, err := buffer(in.Content)
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+
+	policy := retryAlways
+	if rest != nil {
+		policy = retryNever
+	}
+
+	var result *UploadFileResponse
+
+	err = p.do(ctx, policy, func(f *Filer) (err error) {
+		req := *in
+		req.Content = rest
+		if rest == nil {
+			req.Content = bytes.NewReader(data)
+		}
+
+		result, err = f.UploadFile(ctx, &req)
+		return err
+	})
+
+	return result, err
+}
+
+// AppendFile is only retried when the append did not reach the filer, a
+// retry after an append that succeeded but whose response was lost would
+// append the content twice.
+func (p *FilerPool) AppendFile(ctx context.Context, in *AppendFileRequest) (*AppendFileResponse, error) {
+	data, rest, err := buffer(in.Content)
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+
+	policy := retryUnsent
+	if rest != nil {
+		policy = retryNever
+	}
+
+	var result *AppendFileResponse
+
+	err = p.do(ctx, policy, func(f *Filer) (err error) {
+		req := *in
+		req.Content = rest
+		if rest == nil {
+			req.Content = bytes.NewReader(data)
+		}
+
+		result, err = f.AppendFile(ctx, &req)
+		return err
+	})
+
+	return result, err
+}
+
+func (p *FilerPool) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest) error {
+	return p.do(ctx, retryAlways, func(f *Filer) error {
+		return f.CreateDirectory(ctx, in)
+	})
+}
+
+// Delete succeeds when a retry does not find the file, the failed attempt
+// before it may have deleted it already.
+func (p *FilerPool) Delete(ctx context.Context, in *DeleteRequest) error {
+	var attempts int
+
+	return p.do(ctx, retryAlways, func(f *Filer) error {
+		attempts++
+
+		err := f.Delete(ctx, in)
+		if errors.Is(err, ErrNotFound) && attempts > 1 {
+			return nil
+		}
+
+		return err
+	})
+}
+
+// Move is only retried when the move did not reach the filer, a retry after a
+// move that succeeded but whose response was lost would fail with
+// ErrNotFound.
+func (p *FilerPool) Move(ctx context.Context, in *MoveRequest) error {
+	return p.do(ctx, retryUnsent, func(f *Filer) error {
+		return f.Move(ctx, in)
+	})
+}
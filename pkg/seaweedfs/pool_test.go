@@ -0,0 +1,160 @@
+package seaweedfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testFiler answers every request with status, a zero status closes the
+// server so that the connections are refused. The content of the requests
+// that reached it is recorded.
+type testFiler struct {
+	status   int
+	requests atomic.Int32
+	content  string
+}
+
+func (tf *testFiler) start(t *testing.T) *Filer {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tf.requests.Add(1)
+
+		if f, _, err := r.FormFile("file"); err == nil {
+			b, _ := io.ReadAll(f)
+			tf.content = string(b)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(tf.status)
+		_, _ = w.Write([]byte(`{"name":"a.txt","size":7}`))
+	}))
+
+	if tf.status == 0 {
+		srv.Close()
+	} else {
+		t.Cleanup(srv.Close)
+	}
+
+	f, err := NewFiler(srv.URL)
+	if err != nil {
+		t.Fatalf("NewFiler() error = %v", err)
+	}
+
+	return f
+}
+
+func newTestPool(t *testing.T, filers ...*testFiler) *FilerPool {
+	t.Helper()
+
+	cfg := NewConfig("").WithSelection(SelectionFailover).WithRetries(3, time.Millisecond)
+
+	pool := make([]*Filer, len(filers))
+	for i, tf := range filers {
+		pool[i] = tf.start(t)
+	}
+
+	return NewFilerPool(pool, cfg)
+}
+
+func TestFilerPoolRetries(t *testing.T) {
+	const content = "content"
+
+	ops := map[string]func(ctx context.Context, p *FilerPool) error{
+		"upload": func(ctx context.Context, p *FilerPool) error {
+			_, err := p.UploadFile(ctx, &UploadFileRequest{Content: strings.NewReader(content), FullFileName: "/a.txt"})
+			return err
+		},
+		"append": func(ctx context.Context, p *FilerPool) error {
+			_, err := p.AppendFile(ctx, &AppendFileRequest{Content: strings.NewReader(content), FullFileName: "/a.txt"})
+			return err
+		},
+		"delete": func(ctx context.Context, p *FilerPool) error {
+			return p.Delete(ctx, &DeleteRequest{FullPath: "/a.txt"})
+		},
+		"move": func(ctx context.Context, p *FilerPool) error {
+			return p.Move(ctx, &MoveRequest{SrcFullPath: "/a.txt", DstFullPath: "/b.txt"})
+		},
+	}
+
+	tests := []struct {
+		name   string
+		op     string
+		first  int
+		second int
+		// retried tells whether the second filer is reached
+		retried bool
+		err     error
+	}{
+		{"upload after a server error", "upload", http.StatusInternalServerError, http.StatusCreated, true, nil},
+		{"upload after a refused connection", "upload", 0, http.StatusCreated, true, nil},
+		{"append after a refused connection", "append", 0, http.StatusCreated, true, nil},
+		{"append after a server error", "append", http.StatusInternalServerError, http.StatusCreated, false, ErrServerError},
+		{"delete after a server error", "delete", http.StatusInternalServerError, http.StatusNoContent, true, nil},
+		{"delete deleted by the failed attempt", "delete", http.StatusInternalServerError, http.StatusNotFound, true, nil},
+		{"delete not found", "delete", http.StatusNotFound, http.StatusNoContent, false, ErrNotFound},
+		{"move after a refused connection", "move", 0, http.StatusCreated, true, nil},
+		{"move after a server error", "move", http.StatusInternalServerError, http.StatusCreated, false, ErrServerError},
+	}
+
+	for _, tt := range tests {
+		first, second := &testFiler{status: tt.first}, &testFiler{status: tt.second}
+		p := newTestPool(t, first, second)
+
+		if err := ops[tt.op](context.Background(), p); !errors.Is(err, tt.err) {
+			t.Errorf("%s: error = %v; want %v", tt.name, err, tt.err)
+		}
+
+		if retried := second.requests.Load() > 0; retried != tt.retried {
+			t.Errorf("%s: second filer reached = %v; want %v", tt.name, retried, tt.retried)
+		}
+
+		if tt.retried && (tt.op == "upload" || tt.op == "append") && second.content != content {
+			t.Errorf("%s: second filer received %q; want %q", tt.name, second.content, content)
+		}
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		replays bool
+	}{
+		{"empty", 0, true},
+		{"small", 1 << 10, true},
+		{"limit", maxReplaySize, true},
+		{"too large", maxReplaySize + 1, false},
+	}
+
+	for _, tt := range tests {
+		content := strings.Repeat("a", tt.size)
+
+		data, rest, err := buffer(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("%s: buffer() error = %v", tt.name, err)
+		}
+
+		if replays := rest == nil; replays != tt.replays {
+			t.Errorf("%s: buffer() replays = %v; want %v", tt.name, replays, tt.replays)
+			continue
+		}
+
+		got := string(data)
+		if rest != nil {
+			b, _ := io.ReadAll(rest)
+			got = string(b)
+		}
+
+		if got != content {
+			t.Errorf("%s: buffer() returned %d bytes; want %d", tt.name, len(got), len(content))
+		}
+	}
+}
@@ -6,6 +6,7 @@ type Seaweed struct {
 	cfg    *Config
 	master *Master
 	filers []*Filer
+	pool   *FilerPool
 }
 
 func NewSeaweed(cfg *Config) (*Seaweed, error) {
@@ -29,13 +30,22 @@ func NewSeaweed(cfg *Config) (*Seaweed, error) {
 		}
 
 		filer.SetDebug(cfg.debug)
+		filer.SetTimeout(cfg.Timeout)
 
 		s.filers = append(s.filers, filer)
 	}
 
+	s.pool = NewFilerPool(s.filers, cfg)
+	s.pool.StartHealthChecks(cfg.HealthCheckInterval)
+
 	return s, nil
 }
 
+// Close stops the health checks of the filers.
+func (s *Seaweed) Close() {
+	s.pool.Close()
+}
+
 func (s *Seaweed) Master() *Master {
 	return s.master
 }
@@ -43,3 +53,9 @@ func (s *Seaweed) Master() *Master {
 func (s *Seaweed) Filers() []*Filer {
 	return s.filers
 }
+
+// Filer returns the pool of every filer, it fails over to another filer when
+// one is down.
+func (s *Seaweed) Filer() *FilerPool {
+	return s.pool
+}